@@ -0,0 +1,524 @@
+// Command dv is the wallet of voters and admins, it signs transactions locally and sends them to a node
+package main
+
+import (
+	"bufio"
+	"context"
+	"crypto/rand"
+	"errors"
+	"flag"
+	"fmt"
+	tx "github.com/Digital-Voting-Team/Digital-Voting/pkg/blockchain/transaction"
+	ts "github.com/Digital-Voting-Team/Digital-Voting/pkg/blockchain/transaction/transaction_specific"
+	"github.com/Digital-Voting-Team/Digital-Voting/pkg/client"
+	"github.com/Digital-Voting-Team/Digital-Voting/pkg/models/account"
+	"github.com/Digital-Voting-Team/Digital-Voting/pkg/signature/curve"
+	"github.com/Digital-Voting-Team/Digital-Voting/pkg/signature/hd_keys"
+	"github.com/Digital-Voting-Team/Digital-Voting/pkg/signature/keys"
+	"github.com/Digital-Voting-Team/Digital-Voting/pkg/signature/keystore"
+	"github.com/Digital-Voting-Team/Digital-Voting/pkg/validator/query"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+const usage = `usage: dv [flags] <command> [arguments]
+
+commands:
+  keygen                                 generate a key and save it to the keystore as -key
+  import                                 save an existing seed read from stdin to the keystore as -key
+  recover [-path <path>]                 restore a key from its mnemonic phrase, -path derives a child key
+  derive -path <path> [-count <n>]       print public keys of children of path derived from a mnemonic phrase
+  keys                                   list keys of the keystore
+  pubkey                                 print the public key of -key
+  register [-type <type>] <public key>   register an account, type is user, registration_admin or voting_admin
+  group -name <name> <public key>...     create a group
+  voting -spec <file.yaml>               create a voting described by a YAML spec
+  vote -voting <hash> -answer <n>        cast a vote, -anonymous signs it with a ring of -ring eligible voters
+  results <voting hash>                  print results of a voting
+  verify <transaction hash>              verify the inclusion proof of a transaction, -validators checks the witness
+
+The passphrase of keys is read from $` + passphraseEnv + ` or asked for on the terminal.
+
+flags:
+`
+
+// passphraseEnv lets scripts pass the passphrase of keys without a prompt
+const passphraseEnv = "DV_PASSPHRASE"
+
+var accountTypes = map[string]account.Type{
+	"user":               account.User,
+	"registration_admin": account.RegistrationAdmin,
+	"voting_admin":       account.VotingCreationAdmin,
+}
+
+// wallet holds the global flags shared by commands
+type wallet struct {
+	node     string
+	keystore string
+	name     string
+	wait     bool
+	input    *bufio.Reader
+}
+
+type command func(ctx context.Context, w *wallet, args []string) error
+
+var commands = map[string]command{
+	"keygen":   keygen,
+	"import":   importSeed,
+	"recover":  recoverKeys,
+	"derive":   derive,
+	"keys":     listKeys,
+	"pubkey":   pubkey,
+	"register": register,
+	"group":    group,
+	"voting":   createVoting,
+	"vote":     vote,
+	"results":  results,
+	"verify":   verify,
+}
+
+func main() {
+	log.SetFlags(0)
+	log.SetPrefix("dv: ")
+
+	home, err := os.UserHomeDir()
+	if err != nil {
+		home = "."
+	}
+	w := &wallet{input: bufio.NewReader(os.Stdin)}
+	flag.StringVar(&w.node, "node", "http://localhost:8082", "base URL of the rest api of a node")
+	flag.StringVar(&w.keystore, "keystore", filepath.Join(home, ".dv", "keystore"), "directory keys are kept in")
+	flag.StringVar(&w.name, "key", "default", "name of the key to sign with")
+	flag.BoolVar(&w.wait, "wait", true, "wait until submitted transactions are included in a block")
+	timeout := flag.Duration("timeout", time.Minute, "time a command has to finish")
+	flag.Usage = func() {
+		fmt.Fprint(flag.CommandLine.Output(), usage)
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+
+	run, exists := commands[flag.Arg(0)]
+	if !exists {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), *timeout)
+	defer cancel()
+	err = run(ctx, w, flag.Args()[1:])
+	if err != nil {
+		log.Fatalln(err)
+	}
+}
+
+// newFlagSet parses flags of a command, -h prints its usage
+func newFlagSet(name string) *flag.FlagSet {
+	return flag.NewFlagSet("dv "+name, flag.ExitOnError)
+}
+
+func (w *wallet) openKeystore() (*keystore.Keystore, error) {
+	return keystore.Open(w.keystore)
+}
+
+// passphrase takes the passphrase from the environment or reads a line of stdin
+func (w *wallet) passphrase() (string, error) {
+	if passphrase := os.Getenv(passphraseEnv); passphrase != "" {
+		return passphrase, nil
+	}
+	return w.readLine("passphrase of " + w.name)
+}
+
+// readLine prompts on stderr and reads a line of stdin, so secrets do not end up in the shell history
+func (w *wallet) readLine(prompt string) (string, error) {
+	fmt.Fprintf(os.Stderr, "%s: ", prompt)
+	line, err := w.input.ReadString('\n')
+	if err != nil && !(errors.Is(err, io.EOF) && line != "") {
+		return "", fmt.Errorf("reading %s: %w", prompt, err)
+	}
+	return strings.TrimRight(line, "\r\n"), nil
+}
+
+// readMnemonic reads a mnemonic phrase and decodes the seed it encodes
+func (w *wallet) readMnemonic() ([32]byte, error) {
+	mnemonic, err := w.readLine("mnemonic phrase")
+	if err != nil {
+		return [32]byte{}, err
+	}
+	return hd_keys.SeedFromMnemonic(mnemonic)
+}
+
+func (w *wallet) saveKeys(keyPair *keys.KeyPair) error {
+	ks, err := w.openKeystore()
+	if err != nil {
+		return err
+	}
+	if ks.Exists(w.name) {
+		return fmt.Errorf("%s: %w", w.name, keystore.ErrExists)
+	}
+	passphrase, err := w.passphrase()
+	if err != nil {
+		return err
+	}
+	err = ks.Save(w.name, keyPair, passphrase)
+	if err != nil {
+		return err
+	}
+
+	publicKey := keyPair.PublicToBytes()
+	fmt.Println(query.EncodeHex(publicKey[:]))
+	return nil
+}
+
+func (w *wallet) loadKeys() (*keys.KeyPair, error) {
+	ks, err := w.openKeystore()
+	if err != nil {
+		return nil, err
+	}
+	if !ks.Exists(w.name) {
+		return nil, fmt.Errorf("%s: %w, generate it with keygen", w.name, keystore.ErrNotFound)
+	}
+	passphrase, err := w.passphrase()
+	if err != nil {
+		return nil, err
+	}
+	return ks.Load(w.name, passphrase, curve.NewCurve25519())
+}
+
+func (w *wallet) client() (*client.Client, error) {
+	keyPair, err := w.loadKeys()
+	if err != nil {
+		return nil, err
+	}
+	return client.NewClient(w.node, keyPair), nil
+}
+
+// submit sends a signed transaction and waits for its inclusion if -wait is set
+func (w *wallet) submit(ctx context.Context, c *client.Client, transaction tx.ITransaction) error {
+	hash := transaction.GetHash()
+	err := c.Submit(ctx, transaction)
+	if err != nil {
+		return err
+	}
+	fmt.Println("submitted", query.EncodeHex(hash[:]))
+
+	if !w.wait {
+		return nil
+	}
+	included, err := c.AwaitInclusion(ctx, hash)
+	if err != nil {
+		return err
+	}
+	fmt.Println("included at height", included.BlockHeight)
+	return nil
+}
+
+// keygen generates a key and prints the mnemonic phrase of its seed as a backup
+func keygen(ctx context.Context, w *wallet, args []string) error {
+	rawSeed := [32]byte{}
+	_, err := rand.Read(rawSeed[:])
+	if err != nil {
+		return err
+	}
+	keyPair, err := keys.FromRawSeed(rawSeed, curve.NewCurve25519())
+	if err != nil {
+		return err
+	}
+	mnemonic, err := hd_keys.NewMnemonic(rawSeed)
+	if err != nil {
+		return err
+	}
+
+	err = w.saveKeys(keyPair)
+	if err != nil {
+		return err
+	}
+	fmt.Fprintln(os.Stderr, "write down the mnemonic phrase, dv recover restores the key from it:")
+	fmt.Fprintln(os.Stderr, mnemonic)
+	return nil
+}
+
+// recoverKeys restores a key from a mnemonic phrase, -path derives a child of the seed instead
+func recoverKeys(ctx context.Context, w *wallet, args []string) error {
+	flags := newFlagSet("recover")
+	path := flags.String("path", "", "derivation path of the key, e.g. m/1'/42'")
+	_ = flags.Parse(args)
+
+	rawSeed, err := w.readMnemonic()
+	if err != nil {
+		return err
+	}
+	if *path != "" {
+		child, err := hd_keys.NewMaster(rawSeed).Derive(*path)
+		if err != nil {
+			return err
+		}
+		rawSeed = child.RawSeed()
+	}
+	keyPair, err := keys.FromRawSeed(rawSeed, curve.NewCurve25519())
+	if err != nil {
+		return err
+	}
+	return w.saveKeys(keyPair)
+}
+
+// derive prints public keys of count children of path derived from a mnemonic phrase, a child is saved to
+// the keystore with dv recover -path. Seeds are printed only with -show-secret since anyone who sees them controls the keys
+func derive(ctx context.Context, w *wallet, args []string) error {
+	flags := newFlagSet("derive")
+	path := flags.String("path", "m", "derivation path of the parent of derived keys")
+	first := flags.Uint("first", 0, "index of the first child")
+	count := flags.Uint("count", 1, "number of children to derive")
+	showSecret := flags.Bool("show-secret", false, "print seeds of derived keys as well")
+	_ = flags.Parse(args)
+	if uint64(*first)+uint64(*count) > uint64(hd_keys.HardenedOffset) {
+		return errors.New("child indexes must be below 2^31")
+	}
+
+	rawSeed, err := w.readMnemonic()
+	if err != nil {
+		return err
+	}
+	parent, err := hd_keys.NewMaster(rawSeed).Derive(*path)
+	if err != nil {
+		return err
+	}
+	for index := uint32(*first); index < uint32(*first+*count); index++ {
+		child, err := parent.Child(index + hd_keys.HardenedOffset)
+		if err != nil {
+			return err
+		}
+		keyPair, err := child.KeyPair(curve.NewCurve25519())
+		if err != nil {
+			return err
+		}
+		publicKey := keyPair.PublicToBytes()
+		if *showSecret {
+			fmt.Printf("%s/%d' %s %s\n", strings.TrimSuffix(*path, "/"), index, query.EncodeHex(publicKey[:]), keyPair.Seed())
+		} else {
+			fmt.Printf("%s/%d' %s\n", strings.TrimSuffix(*path, "/"), index, query.EncodeHex(publicKey[:]))
+		}
+	}
+	return nil
+}
+
+// importSeed reads the seed from stdin rather than arguments, which other users see in the process list
+func importSeed(ctx context.Context, w *wallet, args []string) error {
+	if len(args) != 0 {
+		return errors.New("import reads the seed from stdin, it takes no arguments")
+	}
+	seed, err := w.readLine("seed")
+	if err != nil {
+		return err
+	}
+	keyPair, err := keys.FromSeed(strings.TrimSpace(seed), curve.NewCurve25519())
+	if err != nil {
+		return fmt.Errorf("invalid seed: %w", err)
+	}
+	return w.saveKeys(keyPair)
+}
+
+func listKeys(ctx context.Context, w *wallet, args []string) error {
+	ks, err := w.openKeystore()
+	if err != nil {
+		return err
+	}
+	entries, err := ks.List()
+	if err != nil {
+		return err
+	}
+	for _, entry := range entries {
+		fmt.Printf("%-20s %s\n", entry.Name, query.EncodeHex(entry.PublicKey[:]))
+	}
+	return nil
+}
+
+// pubkey prints the public key kept next to the encrypted seed, it needs no passphrase
+func pubkey(ctx context.Context, w *wallet, args []string) error {
+	ks, err := w.openKeystore()
+	if err != nil {
+		return err
+	}
+	entries, err := ks.List()
+	if err != nil {
+		return err
+	}
+	for _, entry := range entries {
+		if entry.Name == w.name {
+			fmt.Println(query.EncodeHex(entry.PublicKey[:]))
+			return nil
+		}
+	}
+	return fmt.Errorf("%s: %w", w.name, keystore.ErrNotFound)
+}
+
+func register(ctx context.Context, w *wallet, args []string) error {
+	flags := newFlagSet("register")
+	accountType := flags.String("type", "user", "type of the account: user, registration_admin or voting_admin")
+	_ = flags.Parse(args)
+	if flags.NArg() != 1 {
+		return errors.New("register takes the public key to register")
+	}
+	typeValue, exists := accountTypes[*accountType]
+	if !exists {
+		return fmt.Errorf("unknown account type %s", *accountType)
+	}
+	publicKey, err := query.DecodeKey(flags.Arg(0))
+	if err != nil {
+		return fmt.Errorf("public key: %w", err)
+	}
+
+	c, err := w.client()
+	if err != nil {
+		return err
+	}
+	transaction := client.NewAccountCreation(typeValue, publicKey)
+	c.Sign(transaction)
+	return w.submit(ctx, c, transaction)
+}
+
+func group(ctx context.Context, w *wallet, args []string) error {
+	flags := newFlagSet("group")
+	name := flags.String("name", "", "name of the group")
+	_ = flags.Parse(args)
+	if *name == "" || flags.NArg() == 0 {
+		return errors.New("group takes -name and public keys of members")
+	}
+	members := make([]keys.PublicKeyBytes, flags.NArg())
+	for i, encoded := range flags.Args() {
+		member, err := query.DecodeKey(encoded)
+		if err != nil {
+			return fmt.Errorf("member %s: %w", encoded, err)
+		}
+		members[i] = member
+	}
+
+	c, err := w.client()
+	if err != nil {
+		return err
+	}
+	transaction := client.NewGroupCreation(*name, members)
+	identifier := transaction.TxBody.(*ts.TxGroupCreation).GroupIdentifier
+	fmt.Println("group", query.EncodeHex(identifier[:]))
+	c.Sign(transaction)
+	return w.submit(ctx, c, transaction)
+}
+
+func createVoting(ctx context.Context, w *wallet, args []string) error {
+	flags := newFlagSet("voting")
+	specFile := flags.String("spec", "", "YAML file describing the voting")
+	_ = flags.Parse(args)
+	if *specFile == "" {
+		return errors.New("voting takes -spec")
+	}
+	data, err := os.ReadFile(*specFile)
+	if err != nil {
+		return err
+	}
+	spec, err := parseVotingSpec(data, time.Now())
+	if err != nil {
+		return fmt.Errorf("%s: %w", *specFile, err)
+	}
+
+	c, err := w.client()
+	if err != nil {
+		return err
+	}
+	transaction := client.NewVotingCreation(spec.expirationDate, spec.description, spec.answers, spec.whitelist)
+	c.Sign(transaction)
+	return w.submit(ctx, c, transaction)
+}
+
+func vote(ctx context.Context, w *wallet, args []string) error {
+	flags := newFlagSet("vote")
+	encodedVoting := flags.String("voting", "", "hash of the voting")
+	answer := flags.Uint("answer", 0, "index of the answer")
+	anonymous := flags.Bool("anonymous", false, "sign the vote with a ring signature")
+	ringSize := flags.Int("ring", 0, "number of eligible voters in the ring of an anonymous vote, zero takes all")
+	_ = flags.Parse(args)
+	votingLink, err := query.DecodeHash(*encodedVoting)
+	if err != nil {
+		return fmt.Errorf("voting: %w", err)
+	}
+	if *answer > 255 {
+		return errors.New("answer must be from 0 to 255")
+	}
+
+	c, err := w.client()
+	if err != nil {
+		return err
+	}
+	if !*anonymous {
+		transaction := client.NewVote(votingLink, uint8(*answer))
+		c.Sign(transaction)
+		return w.submit(ctx, c, transaction)
+	}
+
+	transaction, err := c.VoteAnonymously(ctx, votingLink, uint8(*answer), *ringSize)
+	if err != nil {
+		return err
+	}
+	return w.submit(ctx, c, transaction)
+}
+
+func results(ctx context.Context, w *wallet, args []string) error {
+	if len(args) != 1 {
+		return errors.New("results takes the hash of the voting")
+	}
+	votingLink, err := query.DecodeHash(args[0])
+	if err != nil {
+		return fmt.Errorf("voting: %w", err)
+	}
+
+	c := client.NewClient(w.node, nil)
+	voting, err := c.Voting(ctx, votingLink)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("%s (%s, expires %s)\n", voting.Description, voting.Status,
+		time.Unix(int64(voting.ExpirationDate), 0).Format(time.RFC3339))
+	for i, answer := range voting.Answers {
+		count := uint64(0)
+		if i < len(voting.Results) {
+			count = voting.Results[i]
+		}
+		fmt.Printf("%3d  %-40s %d\n", i, answer, count)
+	}
+	return nil
+}
+
+func verify(ctx context.Context, w *wallet, args []string) error {
+	flags := newFlagSet("verify")
+	validators := flags.String("validators", "", "comma separated public keys of the validators of the block, its witness is checked against them")
+	_ = flags.Parse(args)
+	if flags.NArg() != 1 {
+		return errors.New("verify takes the hash of the transaction")
+	}
+	hash, err := query.DecodeHash(flags.Arg(0))
+	if err != nil {
+		return fmt.Errorf("transaction: %w", err)
+	}
+
+	c := client.NewClient(w.node, nil)
+	if *validators != "" {
+		for _, encoded := range strings.Split(*validators, ",") {
+			publicKey, err := query.DecodeKey(strings.TrimSpace(encoded))
+			if err != nil {
+				return fmt.Errorf("validator: %w", err)
+			}
+			c.Validators = append(c.Validators, publicKey)
+		}
+	}
+	proof, err := c.Proof(ctx, hash)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("included in block %d %s, merkle root %s\n", proof.BlockHeight, proof.BlockHash, proof.MerkleRoot)
+	if len(c.Validators) == 0 {
+		fmt.Fprintln(os.Stderr, "witness of the block was not checked, pass -validators to check it")
+	}
+	return nil
+}
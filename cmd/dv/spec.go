@@ -0,0 +1,87 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"github.com/Digital-Voting-Team/Digital-Voting/pkg/validator/query"
+	"gopkg.in/yaml.v3"
+	"time"
+)
+
+// votingSpec is the YAML description of a voting, e.g.
+//
+//	description: Board election
+//	duration: 72h            # or expires: 2026-11-01T00:00:00Z
+//	answers: [Alice, Bob]
+//	whitelist:               # public keys of voters and identifiers of groups
+//	  - 02ab...
+type votingSpec struct {
+	Description string        `yaml:"description"`
+	Expires     time.Time     `yaml:"expires"`
+	Duration    time.Duration `yaml:"duration"`
+	Answers     []string      `yaml:"answers"`
+	Whitelist   []string      `yaml:"whitelist"`
+}
+
+// Limits of the fixed size fields of TxVotingCreation
+const (
+	maxDescriptionLength = 1024
+	maxAnswerLength      = 256
+	maxAnswers           = 256
+)
+
+// voting is a voting ready to be created
+type voting struct {
+	description    string
+	expirationDate time.Time
+	answers        []string
+	whitelist      [][33]byte
+}
+
+func parseVotingSpec(data []byte, now time.Time) (voting, error) {
+	spec := votingSpec{}
+	err := yaml.Unmarshal(data, &spec)
+	if err != nil {
+		return voting{}, err
+	}
+
+	if spec.Description == "" || len(spec.Description) > maxDescriptionLength {
+		return voting{}, fmt.Errorf("description must have from 1 to %d bytes", maxDescriptionLength)
+	}
+	if len(spec.Answers) == 0 || len(spec.Answers) > maxAnswers {
+		return voting{}, fmt.Errorf("voting must have from 1 to %d answers", maxAnswers)
+	}
+	for _, answer := range spec.Answers {
+		if answer == "" || len(answer) > maxAnswerLength {
+			return voting{}, fmt.Errorf("answer %q must have from 1 to %d bytes", answer, maxAnswerLength)
+		}
+	}
+
+	result := voting{description: spec.Description, answers: spec.Answers}
+	switch {
+	case !spec.Expires.IsZero() && spec.Duration != 0:
+		return voting{}, errors.New("only one of expires and duration may be set")
+	case !spec.Expires.IsZero():
+		result.expirationDate = spec.Expires
+	case spec.Duration > 0:
+		result.expirationDate = now.Add(spec.Duration)
+	default:
+		return voting{}, errors.New("expires or a positive duration must be set")
+	}
+	if !result.expirationDate.After(now) {
+		return voting{}, errors.New("voting must expire in the future")
+	}
+
+	if len(spec.Whitelist) == 0 {
+		return voting{}, errors.New("whitelist must not be empty")
+	}
+	for _, encoded := range spec.Whitelist {
+		identifier, err := query.DecodeKey(encoded)
+		if err != nil {
+			return voting{}, fmt.Errorf("whitelist entry %s: %w", encoded, err)
+		}
+		result.whitelist = append(result.whitelist, identifier)
+	}
+
+	return result, nil
+}
@@ -0,0 +1,72 @@
+package main
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseVotingSpec(t *testing.T) {
+	now := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	member := "02" + strings.Repeat("ab", 32)
+
+	tests := []struct {
+		name        string
+		spec        string
+		wantExpires time.Time
+		wantErr     bool
+	}{
+		{
+			name:        "Duration",
+			spec:        "description: Board\nduration: 72h\nanswers: [yes, no]\nwhitelist: [" + member + "]",
+			wantExpires: now.Add(72 * time.Hour),
+		},
+		{
+			name:        "Expiration date",
+			spec:        "description: Board\nexpires: 2026-02-01T00:00:00Z\nanswers: [yes]\nwhitelist: [" + member + "]",
+			wantExpires: time.Date(2026, 2, 1, 0, 0, 0, 0, time.UTC),
+		},
+		{
+			name:    "Both expiration date and duration",
+			spec:    "description: Board\nexpires: 2026-02-01T00:00:00Z\nduration: 1h\nanswers: [yes]\nwhitelist: [" + member + "]",
+			wantErr: true,
+		},
+		{
+			name:    "Expired",
+			spec:    "description: Board\nexpires: 2025-02-01T00:00:00Z\nanswers: [yes]\nwhitelist: [" + member + "]",
+			wantErr: true,
+		},
+		{
+			name:    "No answers",
+			spec:    "description: Board\nduration: 1h\nwhitelist: [" + member + "]",
+			wantErr: true,
+		},
+		{
+			name:    "Invalid whitelist entry",
+			spec:    "description: Board\nduration: 1h\nanswers: [yes]\nwhitelist: [02ab]",
+			wantErr: true,
+		},
+		{
+			name:    "No description",
+			spec:    "duration: 1h\nanswers: [yes]\nwhitelist: [" + member + "]",
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseVotingSpec([]byte(tt.spec), now)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parseVotingSpec() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			if !got.expirationDate.Equal(tt.wantExpires) {
+				t.Errorf("expirationDate = %v, want %v", got.expirationDate, tt.wantExpires)
+			}
+			if len(got.whitelist) != 1 || got.whitelist[0][0] != 0x02 {
+				t.Errorf("whitelist = %v, want the decoded member", got.whitelist)
+			}
+		})
+	}
+}
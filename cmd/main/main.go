@@ -1,50 +1,182 @@
 package main
 
 import (
+	"context"
+	"flag"
+	"fmt"
 	"github.com/Digital-Voting-Team/Digital-Voting/pkg/blockchain"
-	"github.com/Digital-Voting-Team/Digital-Voting/pkg/blockchain/block"
-	tx "github.com/Digital-Voting-Team/Digital-Voting/pkg/blockchain/transaction"
+	"github.com/Digital-Voting-Team/Digital-Voting/pkg/config"
+	"github.com/Digital-Voting-Team/Digital-Voting/pkg/consensus"
+	"github.com/Digital-Voting-Team/Digital-Voting/pkg/logging"
 	"github.com/Digital-Voting-Team/Digital-Voting/pkg/signature/curve"
 	"github.com/Digital-Voting-Team/Digital-Voting/pkg/signature/keys"
+	"github.com/Digital-Voting-Team/Digital-Voting/pkg/signature/keystore"
 	"github.com/Digital-Voting-Team/Digital-Voting/pkg/validator"
+	"github.com/Digital-Voting-Team/Digital-Voting/pkg/validator/connections/grpc_api"
+	"github.com/Digital-Voting-Team/Digital-Voting/pkg/validator/connections/rest_api"
 	"github.com/Digital-Voting-Team/Digital-Voting/pkg/validator/connections/web_socket/network_node"
-	"github.com/Digital-Voting-Team/Digital-Voting/pkg/validator/repository/account_manager"
-	"github.com/Digital-Voting-Team/Digital-Voting/pkg/validator/repository/indexed_votings"
+	"github.com/Digital-Voting-Team/Digital-Voting/pkg/validator/connections/web_socket/user_api"
+	"github.com/Digital-Voting-Team/Digital-Voting/pkg/validator/query"
+	"log"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"syscall"
+	"time"
 )
 
+// ValidatorKeyName is the name of the validator key in the keystore, its passphrase is taken from PassphraseEnv
+const (
+	ValidatorKeyName = "validator"
+	PassphraseEnv    = "DV_PASSPHRASE"
+)
+
+// ShutdownTimeout is the time requests in progress have to finish on shutdown
+const ShutdownTimeout = 10 * time.Second
+
 func main() {
-	channels := validator.Communication{
-		NetworkToValidator: make(chan *block.Block),
-		ValidatorToNetwork: make(chan *block.Block),
-		BlockResponse:      make(chan validator.ResponseMessage),
-		BlockApproval:      make(chan *block.Block),
-		ApprovalResponse:   make(chan bool),
-		BlockDenial:        make(chan *block.Block),
-		Transaction:        make(chan tx.ITransaction),
-		TxResponse:         make(chan bool),
-		ValidatorKeys:      make(chan []keys.PublicKeyBytes),
-		Votings:            make(chan []indexed_votings.VotingDTO),
-		PublicKey:          make(chan keys.PublicKeyBytes),
-	}
-
-	bc := &blockchain.Blockchain{}
-	_ = bc.AddBlock(&block.Block{})
-
-	v := validator.NewValidator(
-		bc,
-		channels,
-	)
-
-	nn := network_node.NewNetworkNode(
-		"localhost:8081",
-		v.KeyPair.PublicToBytes(),
-		channels,
-	)
-
-	keyPair := keys.FromPrivateKey(keys.PrivateKeyBytes{1}, curve.NewCurve25519())
-	v.IndexedData.AccountManager.AddPubKey(keyPair.PublicToBytes(), account_manager.RegistrationAdmin)
-	v.IndexedData.AccountManager.AddPubKey(keyPair.PublicToBytes(), account_manager.VotingCreationAdmin)
-	v.IndexedData.AccountManager.AddPubKey(keyPair.PublicToBytes(), account_manager.User)
-
-	_ = nn.Start(":8080")
+	cfg, err := config.Load(os.Args[1:], os.Getenv)
+	if err == flag.ErrHelp {
+		return
+	}
+	if err != nil {
+		log.Fatalln("Error loading config:", err)
+	}
+	config.SetupLogging(cfg.LogLevel, os.Stderr)
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	genesis := config.NewGenesis(cfg.Chain)
+	if cfg.GenesisFile != "" {
+		genesis, err = config.LoadGenesis(cfg.GenesisFile, cfg.Chain)
+		if err != nil {
+			log.Fatalln("Error loading genesis:", err)
+		}
+	}
+	genesisBlock, err := genesis.Block()
+	if err != nil {
+		log.Fatalln("Error loading genesis:", err)
+	}
+	if len(genesisBlock.Header.Accounts) == 0 {
+		log.Println("Genesis has no accounts, nobody will be able to register accounts or create votings")
+	}
+
+	err = os.MkdirAll(cfg.DataDir, 0o700)
+	if err != nil {
+		log.Fatalln(err)
+	}
+	bc, err := blockchain.LoadFromFile(filepath.Join(cfg.DataDir, validator.BlocksFile))
+	if err != nil {
+		if !os.IsNotExist(err) {
+			log.Fatalln(err)
+		}
+		bc = &blockchain.Blockchain{}
+		_ = bc.AddBlock(genesisBlock)
+	}
+	if first, err := bc.GetBlockByHeight(0); err != nil || first.GetHash() != genesisBlock.GetHash() {
+		log.Fatalln("Saved chain does not start with the genesis block of the genesis file")
+	}
+
+	validatorKeyPair, err := loadValidatorKeys(cfg.KeystoreDir, os.Getenv(PassphraseEnv))
+	if err != nil {
+		log.Fatalln("Error loading validator key:", err)
+	}
+	v := validator.NewValidator(bc, validatorKeyPair)
+	v.DataDir = cfg.DataDir
+
+	nn := network_node.NewNetworkNode(cfg.Listen.Node, v)
+	nn.Consensus = consensus.NewTendermint(v, nn, consensus.DefaultTimeouts())
+	nn.Admission = v.Admission
+	nn.Chain = bc
+	nn.BlockFeed = v.BlockFeed
+	nn.SeedPeers = cfg.Seeds
+	nn.PeersFile = filepath.Join(cfg.DataDir, network_node.PeersFile)
+	v.Network = nn
+
+	// stoppers stop enabled apis on shutdown
+	var stoppers []func(ctx context.Context) error
+	serve := func(name string, start func() error, stopper func(ctx context.Context) error) {
+		stoppers = append(stoppers, stopper)
+		go func() {
+			err := start()
+			if err != nil {
+				logging.Errorf("Error serving %s: %v", name, err)
+			}
+		}()
+	}
+	if cfg.Enabled(config.RestAPI) {
+		ra := rest_api.NewRestApi(cfg.Listen.Rest, bc, v.IndexedData, v.MemPool)
+		ra.Submitter = nn
+		ra.Admission = v.Admission
+		serve(config.RestAPI, ra.Start, ra.Stop)
+	}
+	if cfg.Enabled(config.GRPCAPI) {
+		gs := grpc_api.NewServer(cfg.Listen.GRPC, nn, query.NewService(bc, v.IndexedData, v.MemPool), v.BlockFeed)
+		gs.Admission = v.Admission
+		serve(config.GRPCAPI, gs.Start, func(ctx context.Context) error {
+			gs.Stop(ctx)
+			return nil
+		})
+	}
+	if cfg.Enabled(config.UserAPI) {
+		ua := user_api.NewUserApi(cfg.Listen.UserAPI, v)
+		ua.Admission = v.Admission
+		serve(config.UserAPI, ua.Start, ua.Stop)
+	}
+
+	v.Start(ctx)
+	go func() {
+		err := nn.Start(ctx, cfg.NodeConnector)
+		if err != nil {
+			logging.Errorln(err)
+		}
+		stop()
+	}()
+
+	<-ctx.Done()
+	log.Println("Shutting down")
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), ShutdownTimeout)
+	defer cancel()
+	for _, stopper := range stoppers {
+		err = stopper(shutdownCtx)
+		if err != nil {
+			logging.Errorln("Error stopping api:", err)
+		}
+	}
+	err = nn.Stop(shutdownCtx)
+	if err != nil {
+		logging.Errorln("Error stopping network node:", err)
+	}
+	err = v.Stop()
+	if err != nil {
+		logging.Errorln("Error saving validator data:", err)
+	}
+}
+
+// loadValidatorKeys decrypts the validator key, a new key is generated and saved on the first start
+func loadValidatorKeys(dir string, passphrase string) (*keys.KeyPair, error) {
+	if passphrase == "" {
+		return nil, fmt.Errorf("%s must be set", PassphraseEnv)
+	}
+	ks, err := keystore.Open(dir)
+	if err != nil {
+		return nil, err
+	}
+	if ks.Exists(ValidatorKeyName) {
+		return ks.Load(ValidatorKeyName, passphrase, curve.NewCurve25519())
+	}
+
+	keyPair, err := keys.Random(curve.NewCurve25519())
+	if err != nil {
+		return nil, err
+	}
+	err = ks.Save(ValidatorKeyName, keyPair, passphrase)
+	if err != nil {
+		return nil, err
+	}
+	publicKey := keyPair.PublicToBytes()
+	log.Printf("Created validator key %x", publicKey)
+	return keyPair, nil
 }
@@ -4,13 +4,13 @@ import (
 	"crypto/sha256"
 	"encoding/base64"
 	"encoding/json"
+	"fmt"
 	"github.com/Digital-Voting-Team/Digital-Voting/pkg/blockchain/block/merkle_tree"
 	tx "github.com/Digital-Voting-Team/Digital-Voting/pkg/blockchain/transaction"
 	"github.com/Digital-Voting-Team/Digital-Voting/pkg/blockchain/transaction/transaction_json"
+	"github.com/Digital-Voting-Team/Digital-Voting/pkg/rejection"
 	"github.com/Digital-Voting-Team/Digital-Voting/pkg/signature/keys"
-	signature "github.com/Digital-Voting-Team/Digital-Voting/pkg/signature/signatures/single_signature"
 	"github.com/Digital-Voting-Team/Digital-Voting/pkg/validator/repository"
-	"log"
 	"time"
 )
 
@@ -41,8 +41,9 @@ func NewBlock(txs []tx.ITransaction, previous [32]byte) *Block {
 	return block
 }
 
-func (b *Block) Sign(publicKey keys.PublicKeyBytes, signature signature.SingleSignatureBytes) {
-	b.Witness.addSignature(publicKey, signature)
+// SetWitness attaches the aggregate signature of the validators, it does not affect the block hash
+func (b *Block) SetWitness(witness Witness) {
+	b.Witness = witness
 }
 
 func (b *Block) AddMerkleRoot(merkleRoot [32]byte) {
@@ -71,25 +72,105 @@ func (b *Block) GetHashString() string {
 	return base64.URLEncoding.EncodeToString(hash[:])
 }
 
-func (b *Block) Verify(indexedData *repository.IndexedData) bool {
+// Verify checks the block as the next one of indexed data, its witness against the validators of its height
+func (b *Block) Verify(indexedData *repository.IndexedData) error {
+	err := b.Witness.Verify(indexedData.ValidatorHistory.At(indexedData.Height), b.GetHashString())
+	if err != nil {
+		return err
+	}
+
+	return b.VerifyProposal(indexedData)
+}
+
+// SignatureVerifier checks signatures of transactions, possibly in parallel and skipping ones verified before
+type SignatureVerifier interface {
+	VerifySignatures(transactions []tx.ITransaction) bool
+}
+
+type sequentialVerifier struct{}
+
+func (sequentialVerifier) VerifySignatures(transactions []tx.ITransaction) bool {
+	for _, transaction := range transactions {
+		if !transaction.VerifySignature() {
+			return false
+		}
+	}
+	return true
+}
+
+// VerifyProposal verifies the block without the witness, validators sign only blocks that pass it
+func (b *Block) VerifyProposal(indexedData *repository.IndexedData) error {
+	err := b.CheckHeader()
+	if err != nil {
+		return err
+	}
+	err = b.VerifySignatures(sequentialVerifier{})
+	if err != nil {
+		return err
+	}
+	return b.VerifyData(indexedData)
+}
+
+// CheckHeader rejects params and accounts in blocks after genesis, accounts of the header
+// get their roles without any transaction
+func (b *Block) CheckHeader() error {
+	if b.Header.Params != nil || len(b.Header.Accounts) != 0 {
+		return rejection.New(rejection.GenesisOnly, "params and accounts are set in genesis block only")
+	}
+	return nil
+}
+
+// VerifySignatures checks the merkle root and signatures of transactions, it does not need indexed data
+// so it can run without holding its lock
+func (b *Block) VerifySignatures(verifier SignatureVerifier) error {
 	if merkle_tree.GetMerkleRoot(b.Body.Transactions) != b.Header.MerkleRoot {
-		log.Println("Merkle root verification failed")
-		return false
+		return rejection.New(rejection.BadMerkleRoot, "merkle root does not match transactions")
 	}
 
-	if !b.Witness.Verify(indexedData.AccountManager, b.GetHashString()) {
-		log.Println("Witness verification failed")
-		return false
+	if !verifier.VerifySignatures(b.Body.Transactions) {
+		return rejection.New(rejection.BadSignature, "block contains transaction with invalid signature")
 	}
 
+	return nil
+}
+
+// VerifyData checks transactions against indexed data, signatures are checked by VerifySignatures.
+// Indexed data does not include votes and evidence of the block itself, so a voter voting twice
+// and a validator reported twice within it are checked here
+func (b *Block) VerifyData(indexedData *repository.IndexedData) error {
+	voted := map[[32]byte]map[[33]byte]bool{}
+	reported := map[keys.PublicKeyBytes]bool{}
 	for _, transaction := range b.Body.Transactions {
-		if !transaction.Verify(indexedData) {
-			log.Println("Transaction verification failed")
-			return false
+		err := transaction.VerifyData(indexedData)
+		if err != nil {
+			return fmt.Errorf("transaction %s: %w", transaction.GetHashString(), err)
 		}
+
+		if evidence, ok := transaction.GetTxBody().(tx.EvidenceBody); ok {
+			if reported[evidence.GetOffender()] {
+				return rejection.New(rejection.AlreadySlashed, "transaction %s reports validator %x again in the block", transaction.GetHashString(), evidence.GetOffender())
+			}
+			reported[evidence.GetOffender()] = true
+		}
+
+		caster, ok := transaction.(tx.BallotCaster)
+		if !ok {
+			continue
+		}
+		ballot, ok := caster.GetBallot()
+		if !ok {
+			continue
+		}
+		if voted[ballot.VotingLink] == nil {
+			voted[ballot.VotingLink] = map[[33]byte]bool{}
+		}
+		if voted[ballot.VotingLink][ballot.Voter] {
+			return rejection.New(rejection.DuplicateVote, "transaction %s votes again in the block", transaction.GetHashString())
+		}
+		voted[ballot.VotingLink][ballot.Voter] = true
 	}
 
-	return true
+	return nil
 }
 
 // UnmarshallBlock unmarshalls the JSON representation of the Block into the Block itself
@@ -106,8 +187,10 @@ func UnmarshallBlock(marshalledBlock []byte) (*Block, error) {
 	_ = json.Unmarshal(marshalledBlock, unmarshalledBlock)
 	unmarshalledBlock.Body.Transactions = nil
 
-	// Transactions are unmarshalled through iterative process
-	for _, transactions := range temp["body"].(map[string]any)["transactions"].([]any) {
+	// Transactions are unmarshalled through iterative process, genesis block has none
+	body, _ := temp["body"].(map[string]any)
+	transactionList, _ := body["transactions"].([]any)
+	for _, transactions := range transactionList {
 		marshall, err := json.Marshal(transactions)
 		if err != nil {
 			return nil, err
@@ -3,9 +3,15 @@ package block
 import (
 	tx "github.com/Digital-Voting-Team/Digital-Voting/pkg/blockchain/transaction"
 	ts "github.com/Digital-Voting-Team/Digital-Voting/pkg/blockchain/transaction/transaction_specific"
+	"github.com/Digital-Voting-Team/Digital-Voting/pkg/consensus/evidence"
 	"github.com/Digital-Voting-Team/Digital-Voting/pkg/models/account"
+	"github.com/Digital-Voting-Team/Digital-Voting/pkg/rejection"
 	"github.com/Digital-Voting-Team/Digital-Voting/pkg/signature/keys"
+	ms "github.com/Digital-Voting-Team/Digital-Voting/pkg/signature/signatures/multi_signature"
 	ss "github.com/Digital-Voting-Team/Digital-Voting/pkg/signature/signatures/single_signature"
+	"github.com/Digital-Voting-Team/Digital-Voting/pkg/validator/repository"
+	"github.com/Digital-Voting-Team/Digital-Voting/pkg/validator/repository/account_manager"
+	"github.com/Digital-Voting-Team/Digital-Voting/pkg/validator/repository/indexed_votings"
 	"reflect"
 	"testing"
 	"time"
@@ -20,8 +26,7 @@ func TestBlock(t *testing.T) {
 			MerkleRoot: [32]byte{1, 4, 5, 6, 7, 46},
 		},
 		Witness: Witness{
-			ValidatorsPublicKeys: nil,
-			ValidatorsSignatures: nil,
+			Signers: nil,
 		},
 		Body: Body{
 			Transactions: nil,
@@ -35,10 +40,9 @@ func TestBlock(t *testing.T) {
 		t.Errorf("Got %s, instead of %s", got, expect)
 	}
 
-	var key = keys.PublicKeyBytes{1, 2, 4, 41, 23}
-	var signature = ss.SingleSignatureBytes{6, 12, 9, 4, 3}
+	var signature = ms.AggregateSignatureBytes{6, 12, 9, 4, 3}
 
-	b.Sign(key, signature)
+	b.SetWitness(Witness{Signers: []byte{5}, Signature: signature})
 
 	got = b.GetHashString()
 	if got != expect {
@@ -60,21 +64,9 @@ func TestUnmarshallBlock(t *testing.T) {
 			]
 		  },
 		  "witness": {
-			"public_keys": [
-			  [
-				65,66,67,68,69,70,71,72,73,74,75,76,77,78,79,80,81,82,83,84,85,86,87,88,89,90,91,92,93,94,95,96,97
-			  ],
-			  [
-				98,99,100,101,102,103,104,105,106,107,108,109,110,111,112,113,114,115,116,117,118,119,120,121,122,123,124,125,126,127,128,129,130
-			  ]
-			],
-			"signatures": [
-			  [
-				131,132,133,134,135,136,137,138,139,140,141,142,143,144,145,146,147,148,149,150,151,152,153,154,155,156,157,158,159,160,161,162,163,164,165,166,167,168,169,170,171,172,173,174,175,176,177,178,179,180,181,182,183,184,185,186,187,188,189,190,191,192,193,194
-			  ],
-			  [
-				200,201,202,203,204,205,206,207,208,209,210,211,212,213,214,215,216,217,218,219,220,221,222,223,224,225,226,227,228,229,230,231,232,233,234,235,236,237,238,239,240,241,242,243,244,245,246,247,248,249,250,251,252,253,254,255,0,1,2,3,4,5,6,7,8
-			  ]
+			"signers": "Aw==",
+			"signature": [
+			  131,132,133,134,135,136,137,138,139,140,141,142,143,144,145,146,147,148,149,150,151,152,153,154,155,156,157,158,159,160,161,162,163,164,165,166,167,168,169,170,171,172,173,174,175,176,177,178,179,180,181,182,183,184,185,186,187,188,189,190,191,192,193,194,195
 			]
 		  },
 		  "body": {
@@ -107,14 +99,8 @@ func TestUnmarshallBlock(t *testing.T) {
 			MerkleRoot: [32]byte{33, 34, 35, 36, 37, 38, 39, 40, 41, 42, 43, 44, 45, 46, 47, 48, 49, 50, 51, 52, 53, 54, 55, 56, 57, 58, 59, 60, 61, 62, 63, 64},
 		},
 		Witness: Witness{
-			ValidatorsPublicKeys: []keys.PublicKeyBytes{
-				{65, 66, 67, 68, 69, 70, 71, 72, 73, 74, 75, 76, 77, 78, 79, 80, 81, 82, 83, 84, 85, 86, 87, 88, 89, 90, 91, 92, 93, 94, 95, 96, 97},
-				{98, 99, 100, 101, 102, 103, 104, 105, 106, 107, 108, 109, 110, 111, 112, 113, 114, 115, 116, 117, 118, 119, 120, 121, 122, 123, 124, 125, 126, 127, 128, 129, 130},
-			},
-			ValidatorsSignatures: []ss.SingleSignatureBytes{
-				{131, 132, 133, 134, 135, 136, 137, 138, 139, 140, 141, 142, 143, 144, 145, 146, 147, 148, 149, 150, 151, 152, 153, 154, 155, 156, 157, 158, 159, 160, 161, 162, 163, 164, 165, 166, 167, 168, 169, 170, 171, 172, 173, 174, 175, 176, 177, 178, 179, 180, 181, 182, 183, 184, 185, 186, 187, 188, 189, 190, 191, 192, 193, 194},
-				{200, 201, 202, 203, 204, 205, 206, 207, 208, 209, 210, 211, 212, 213, 214, 215, 216, 217, 218, 219, 220, 221, 222, 223, 224, 225, 226, 227, 228, 229, 230, 231, 232, 233, 234, 235, 236, 237, 238, 239, 240, 241, 242, 243, 244, 245, 246, 247, 248, 249, 250, 251, 252, 253, 254, 255, 0, 1, 2, 3, 4, 5, 6, 7, 8},
-			},
+			Signers:   []byte{3},
+			Signature: ms.AggregateSignatureBytes{131, 132, 133, 134, 135, 136, 137, 138, 139, 140, 141, 142, 143, 144, 145, 146, 147, 148, 149, 150, 151, 152, 153, 154, 155, 156, 157, 158, 159, 160, 161, 162, 163, 164, 165, 166, 167, 168, 169, 170, 171, 172, 173, 174, 175, 176, 177, 178, 179, 180, 181, 182, 183, 184, 185, 186, 187, 188, 189, 190, 191, 192, 193, 194, 195},
 		},
 		Body: Body{
 			Transactions: []tx.ITransaction{
@@ -162,3 +148,182 @@ func TestUnmarshallBlock(t *testing.T) {
 		})
 	}
 }
+
+func TestWitness_GetSigners(t *testing.T) {
+	validators := []keys.PublicKeyBytes{{4}, {1}, {3}, {2}, {9}, {8}, {7}, {6}, {5}}
+	tests := []struct {
+		name    string
+		signers []keys.PublicKeyBytes
+		wantErr bool
+	}{
+		{
+			name:    "Some validators signed",
+			signers: []keys.PublicKeyBytes{{1}, {3}, {9}},
+			wantErr: false,
+		},
+		{
+			name:    "All validators signed",
+			signers: keys.SortPublicKeys(validators),
+			wantErr: false,
+		},
+		{
+			name:    "Signers are not sorted",
+			signers: []keys.PublicKeyBytes{{3}, {1}},
+			wantErr: true,
+		},
+		{
+			name:    "Signer is not a validator",
+			signers: []keys.PublicKeyBytes{{1}, {10}},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			witness, err := NewWitness(validators, tt.signers, ms.AggregateSignatureBytes{})
+			if (err != nil) != tt.wantErr {
+				t.Errorf("NewWitness() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if tt.wantErr {
+				return
+			}
+			if len(witness.Signers) != 2 {
+				t.Errorf("NewWitness() bitmap length = %d, want 2", len(witness.Signers))
+			}
+			got, err := witness.GetSigners(validators)
+			if err != nil {
+				t.Errorf("GetSigners() error = %v", err)
+				return
+			}
+			if !reflect.DeepEqual(got, tt.signers) {
+				t.Errorf("GetSigners() got = %v, want %v", got, tt.signers)
+			}
+		})
+	}
+}
+
+func TestBlock_CheckLimits(t *testing.T) {
+	transactions := []tx.ITransaction{
+		tx.NewTransaction(tx.AccountCreation, ts.NewTxAccCreation(account.User, keys.PublicKeyBytes{1})),
+		tx.NewTransaction(tx.AccountCreation, ts.NewTxAccCreation(account.User, keys.PublicKeyBytes{2})),
+	}
+	b := NewBlock(transactions, [32]byte{})
+	size, err := b.EncodedSize()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name   string
+		params ChainParams
+		want   bool
+	}{
+		{name: "Within limits", params: ChainParams{MaxTransactions: 2, MaxBlockBytes: size}, want: true},
+		{name: "Too many transactions", params: ChainParams{MaxTransactions: 1, MaxBlockBytes: size}, want: false},
+		{name: "Too many bytes", params: ChainParams{MaxTransactions: 2, MaxBlockBytes: size - 1}, want: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := b.CheckLimits(tt.params); got != tt.want {
+				t.Errorf("CheckLimits() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	// A block that fit as a proposal still fits once validators attach their witness
+	b.SetWitness(Witness{Signers: make([]byte, 64)})
+	if !b.CheckLimits(ChainParams{MaxTransactions: 2, MaxBlockBytes: size}) {
+		t.Errorf("CheckLimits() = false with witness, want true")
+	}
+}
+
+func TestBlock_VerifyData(t *testing.T) {
+	voter := keys.PublicKeyBytes{1}
+	votingLink := [32]byte{1}
+	newIndexedData := func() *repository.IndexedData {
+		indexedData := repository.NewIndexedData()
+		indexedData.AccountManager.AddPubKey(voter, account_manager.User)
+		indexedData.VotingManager.AddNewVoting(indexed_votings.VotingDTO{
+			Hash:           votingLink,
+			ExpirationDate: uint32(time.Now().Add(time.Hour).Unix()),
+			Answers:        [][256]byte{{1}, {2}},
+			Whitelist:      [][33]byte{voter},
+		})
+		return indexedData
+	}
+	newVote := func(answer uint8) tx.ITransaction {
+		vote := tx.NewTransaction(tx.Vote, ts.NewTxVote(votingLink, answer))
+		vote.PublicKey = voter
+		return vote
+	}
+
+	voted := newIndexedData()
+	voted.VotingManager.AddVote(votingLink, voter, 0)
+
+	tests := []struct {
+		name         string
+		transactions []tx.ITransaction
+		indexedData  *repository.IndexedData
+		want         rejection.Code
+	}{
+		{name: "Single vote", transactions: []tx.ITransaction{newVote(0)}, indexedData: newIndexedData(), want: ""},
+		{name: "Invalid answer", transactions: []tx.ITransaction{newVote(2)}, indexedData: newIndexedData(), want: rejection.InvalidAnswer},
+		{name: "Two votes of one voter", transactions: []tx.ITransaction{newVote(0), newVote(1)}, indexedData: newIndexedData(), want: rejection.DuplicateVote},
+		{name: "Vote of a voter that voted before", transactions: []tx.ITransaction{newVote(1)}, indexedData: voted, want: rejection.DuplicateVote},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b := NewBlock(tt.transactions, [32]byte{})
+			if got := rejection.CodeOf(b.VerifyData(tt.indexedData)); got != tt.want {
+				t.Errorf("VerifyData() code = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestBlock_VerifyData_Evidence(t *testing.T) {
+	sign := ss.NewECDSA()
+	offender, _ := keys.Random(sign.Curve)
+	reporters := []keys.PublicKeyBytes{{1}, {2}}
+	vote := func(height uint64, blockHash [32]byte) evidence.Vote {
+		vote := evidence.Vote{VoteType: evidence.Precommit, Height: height, Round: 0, BlockHash: blockHash, Validator: offender.PublicToBytes()}
+		signature := sign.SignEdDSA(vote.GetSignatureMessage(), offender.GetPrivateKey(), offender.GetPublicKey())
+		vote.Signature = sign.EdwardsToSingleSignature(signature).EdwardsSignatureToBytes()
+		return vote
+	}
+	report := func(height uint64, reporter keys.PublicKeyBytes) tx.ITransaction {
+		equivocation := evidence.NewVoteEvidence(vote(height, [32]byte{1}), vote(height, [32]byte{2}))
+		transaction := tx.NewTransaction(tx.Evidence, ts.NewTxEvidence(*equivocation, reporter))
+		transaction.PublicKey = reporter
+		return transaction
+	}
+
+	// the offender joined the set at height 3, the chain has 6 blocks
+	indexedData := repository.NewIndexedData()
+	for _, reporter := range reporters {
+		indexedData.AccountManager.AddPubKey(reporter, account_manager.Validator)
+	}
+	indexedData.ValidatorHistory.Record(1, indexedData.AccountManager.ValidatorPubKeys)
+	indexedData.AccountManager.AddPubKey(offender.PublicToBytes(), account_manager.Validator)
+	indexedData.ValidatorHistory.Record(3, indexedData.AccountManager.ValidatorPubKeys)
+	indexedData.Height = 6
+
+	tests := []struct {
+		name         string
+		transactions []tx.ITransaction
+		want         rejection.Code
+	}{
+		{name: "Single evidence", transactions: []tx.ITransaction{report(4, reporters[0])}, want: ""},
+		{name: "Offender reported twice", transactions: []tx.ITransaction{report(4, reporters[0]), report(5, reporters[1])}, want: rejection.AlreadySlashed},
+		{name: "Offender was not a validator at the height", transactions: []tx.ITransaction{report(2, reporters[0])}, want: rejection.UnknownAccount},
+		{name: "Height above the chain", transactions: []tx.ITransaction{report(7, reporters[0])}, want: rejection.UnknownAccount},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b := NewBlock(tt.transactions, [32]byte{})
+			if got := rejection.CodeOf(b.VerifyData(indexedData)); got != tt.want {
+				t.Errorf("VerifyData() code = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,55 @@
+package block
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// ChainParams limit blocks of a chain, they are recorded in the header of its genesis block.
+// MaxBlockBytes limits the encoded header and body, the witness is not counted
+type ChainParams struct {
+	MaxTransactions int           `json:"max_transactions"`
+	MaxBlockBytes   int           `json:"max_block_bytes"`
+	BlockInterval   time.Duration `json:"block_interval"`
+}
+
+func DefaultChainParams() ChainParams {
+	return ChainParams{
+		MaxTransactions: 5,
+		MaxBlockBytes:   1 << 20,
+		BlockInterval:   10 * time.Second,
+	}
+}
+
+// NewGenesisBlock creates the first block of a chain, it has no transactions.
+// Accounts are part of its hash, so nodes started with different accounts do not share a chain
+func NewGenesisBlock(params ChainParams, accounts []Account) *Block {
+	return &Block{
+		Header: Header{
+			Params:   &params,
+			Accounts: accounts,
+		},
+	}
+}
+
+// EncodedSize is the length of the JSON representation of the header and body the block is sent over network in.
+// The witness is left out since it is added after the proposal was checked and grows with the validator set
+func (b *Block) EncodedSize() (int, error) {
+	marshalled, err := json.Marshal(struct {
+		Header Header `json:"header"`
+		Body   Body   `json:"body"`
+	}{b.Header, b.Body})
+	if err != nil {
+		return 0, err
+	}
+	return len(marshalled), nil
+}
+
+// CheckLimits reports whether the block fits into limits of the chain
+func (b *Block) CheckLimits(params ChainParams) bool {
+	if len(b.Body.Transactions) > params.MaxTransactions {
+		return false
+	}
+	size, err := b.EncodedSize()
+	return err == nil && size <= params.MaxBlockBytes
+}
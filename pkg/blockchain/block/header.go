@@ -2,6 +2,8 @@ package block
 
 import (
 	"fmt"
+	"github.com/Digital-Voting-Team/Digital-Voting/pkg/signature/keys"
+	"github.com/Digital-Voting-Team/Digital-Voting/pkg/validator/repository/account_manager"
 	"strings"
 )
 
@@ -10,6 +12,16 @@ type Header struct {
 	Previous   [32]byte `json:"previous"`
 	TimeStamp  uint64   `json:"time_stamp"`
 	MerkleRoot [32]byte `json:"merkle_root"`
+
+	// Params and Accounts are set in genesis block only
+	Params   *ChainParams `json:"params,omitempty"`
+	Accounts []Account    `json:"accounts,omitempty"`
+}
+
+// Account is an initial account of a chain with one of its types, the validators of genesis are accounts as well
+type Account struct {
+	PublicKey keys.PublicKeyBytes        `json:"public_key"`
+	Type      account_manager.Identifier `json:"type"`
 }
 
 func (h Header) GetConcatenation() string {
@@ -19,6 +31,13 @@ func (h Header) GetConcatenation() string {
 	sb.Write(h.Previous[:])
 	sb.WriteString(fmt.Sprint(h.TimeStamp))
 	sb.Write(h.MerkleRoot[:])
+	if h.Params != nil {
+		sb.WriteString(fmt.Sprint(*h.Params))
+	}
+	for _, account := range h.Accounts {
+		sb.Write(account.PublicKey[:])
+		sb.WriteString(fmt.Sprint(account.Type))
+	}
 
 	return sb.String()
 }
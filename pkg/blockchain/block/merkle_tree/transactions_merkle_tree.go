@@ -1,6 +1,7 @@
 package merkle_tree
 
 import (
+	"crypto/sha256"
 	tx "github.com/Digital-Voting-Team/Digital-Voting/pkg/blockchain/transaction"
 	"log"
 )
@@ -32,7 +33,11 @@ func getMerkleTree(transactions []tx.ITransaction) *MerkleTree {
 	return resultTree
 }
 
+// GetMerkleRoot returns zero root for a block without transactions
 func GetMerkleRoot(transactions []tx.ITransaction) [32]byte {
+	if len(transactions) == 0 {
+		return [32]byte{}
+	}
 	resultTree := getMerkleTree(transactions)
 
 	root := [32]byte{}
@@ -51,3 +56,44 @@ func VerifyContent(transaction tx.ITransaction, transactionList []tx.ITransactio
 
 	return result
 }
+
+// ProofStep is a sibling on the path from a transaction to the merkle root
+type ProofStep struct {
+	Hash  [32]byte `json:"hash"`
+	Right bool     `json:"right"`
+}
+
+// GetProof returns the path proving the transaction with given hash is included in the transactions,
+// false if it is not
+func GetProof(hash [32]byte, transactions []tx.ITransaction) ([]ProofStep, bool) {
+	for _, transaction := range transactions {
+		if transaction.GetHash() != hash {
+			continue
+		}
+
+		merklePath, index, err := getMerkleTree(transactions).GetMerklePath(TransactionContent{transaction: transaction})
+		if err != nil {
+			log.Fatal(err)
+		}
+		proof := make([]ProofStep, len(merklePath))
+		for i := range merklePath {
+			copy(proof[i].Hash[:], merklePath[i])
+			proof[i].Right = index[i] == 1
+		}
+		return proof, true
+	}
+	return nil, false
+}
+
+// VerifyProof checks that the proof leads from the transaction hash to the merkle root
+func VerifyProof(hash [32]byte, proof []ProofStep, merkleRoot [32]byte) bool {
+	current := hash
+	for _, step := range proof {
+		if step.Right {
+			current = sha256.Sum256(append(current[:], step.Hash[:]...))
+		} else {
+			current = sha256.Sum256(append(step.Hash[:], current[:]...))
+		}
+	}
+	return current == merkleRoot
+}
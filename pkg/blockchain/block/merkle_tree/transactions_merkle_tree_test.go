@@ -73,3 +73,29 @@ func TestVerifyContent(t *testing.T) {
 		})
 	}
 }
+
+func TestGetProof(t *testing.T) {
+	transactions := []tx.ITransaction{}
+	for i := byte(1); i <= 5; i++ {
+		transactions = append(transactions, tx.NewTransaction(tx.AccountCreation, ts.NewTxAccCreation(account.User, keys.PublicKeyBytes{i})))
+	}
+	root := GetMerkleRoot(transactions)
+	missing := tx.NewTransaction(tx.AccountCreation, ts.NewTxAccCreation(account.User, keys.PublicKeyBytes{6}))
+
+	for i, transaction := range transactions {
+		proof, ok := GetProof(transaction.GetHash(), transactions)
+		if !ok {
+			t.Fatalf("GetProof() of transaction %d = false", i)
+		}
+		if !VerifyProof(transaction.GetHash(), proof, root) {
+			t.Errorf("VerifyProof() of transaction %d = false", i)
+		}
+		if VerifyProof(missing.GetHash(), proof, root) {
+			t.Errorf("VerifyProof() of another transaction with proof of transaction %d = true", i)
+		}
+	}
+
+	if _, ok := GetProof(missing.GetHash(), transactions); ok {
+		t.Errorf("GetProof() of missing transaction = true")
+	}
+}
@@ -1,44 +1,80 @@
 package block
 
 import (
+	"fmt"
+	"github.com/Digital-Voting-Team/Digital-Voting/pkg/rejection"
 	"github.com/Digital-Voting-Team/Digital-Voting/pkg/signature/keys"
-	ss "github.com/Digital-Voting-Team/Digital-Voting/pkg/signature/signatures/single_signature"
-	"github.com/Digital-Voting-Team/Digital-Voting/pkg/validator/repository/account_manager"
-	"log"
+	ms "github.com/Digital-Voting-Team/Digital-Voting/pkg/signature/signatures/multi_signature"
 )
 
+// Witness is a single aggregate signature of the validators that approved the block.
+// Signers is a bitmap over the validator set sorted with keys.SortPublicKeys,
+// so the witness size and verification cost barely depend on the number of validators
 type Witness struct {
-	ValidatorsPublicKeys []keys.PublicKeyBytes     `json:"public_keys"`
-	ValidatorsSignatures []ss.SingleSignatureBytes `json:"signatures"`
+	Signers   []byte                     `json:"signers"`
+	Signature ms.AggregateSignatureBytes `json:"signature"`
 }
 
-func (w *Witness) addSignature(publicKey keys.PublicKeyBytes, signature ss.SingleSignatureBytes) {
-	w.ValidatorsPublicKeys = append(w.ValidatorsPublicKeys, publicKey)
-	w.ValidatorsSignatures = append(w.ValidatorsSignatures, signature)
-}
+// NewWitness marks signers in the bitmap over validators, signers must be listed in sorted order
+// since the aggregated key depends on the order of the keys
+func NewWitness(validators []keys.PublicKeyBytes, signers []keys.PublicKeyBytes, signature ms.AggregateSignatureBytes) (Witness, error) {
+	sorted := keys.SortPublicKeys(validators)
+	bitmap := make([]byte, (len(sorted)+7)/8)
 
-func (w *Witness) Verify(accountManager *account_manager.AccountManager, message string) bool {
-	if len(w.ValidatorsPublicKeys) == 0 {
-		log.Println("Witness is empty")
-		return false
+	next := 0
+	for i, validator := range sorted {
+		if next < len(signers) && signers[next] == validator {
+			bitmap[i/8] |= 1 << (i % 8)
+			next++
+		}
 	}
+	if next != len(signers) {
+		return Witness{}, fmt.Errorf("signers are not sorted validators")
+	}
+
+	return Witness{Signers: bitmap, Signature: signature}, nil
+}
 
-	if len(w.ValidatorsPublicKeys) != len(w.ValidatorsSignatures) {
-		log.Println("Witness is corrupted")
-		return false
+// GetSigners returns public keys of the signers in the order they were aggregated in
+func (w *Witness) GetSigners(validators []keys.PublicKeyBytes) ([]keys.PublicKeyBytes, error) {
+	sorted := keys.SortPublicKeys(validators)
+	if len(w.Signers) != (len(sorted)+7)/8 {
+		return nil, fmt.Errorf("bitmap of %d bytes does not match %d validators", len(w.Signers), len(sorted))
 	}
 
-	for i, publicKey := range w.ValidatorsPublicKeys {
-		if !accountManager.CheckPubKeyPresence(publicKey, account_manager.Validator) {
-			log.Println("Witness contains invalid public key")
-			return false
+	signers := []keys.PublicKeyBytes{}
+	for i := 0; i < len(w.Signers)*8; i++ {
+		if w.Signers[i/8]&(1<<(i%8)) == 0 {
+			continue
 		}
-
-		if !ss.NewECDSA().VerifyEdDSABytes(message, publicKey, w.ValidatorsSignatures[i]) {
-			log.Println("Witness contains invalid signature")
-			return false
+		if i >= len(sorted) {
+			return nil, fmt.Errorf("bitmap marks a validator out of the set")
 		}
+		signers = append(signers, sorted[i])
+	}
+
+	return signers, nil
+}
+
+// Quorum is the number of validators needed to commit a block, more than 2/3 of the set
+func Quorum(validatorsNumber int) int {
+	return validatorsNumber*2/3 + 1
+}
+
+// Verify checks that a quorum of validators signed message, validators are the set in force at the height of the block
+func (w *Witness) Verify(validators []keys.PublicKeyBytes, message string) error {
+	signers, err := w.GetSigners(validators)
+	if err != nil {
+		return rejection.New(rejection.BadWitness, "witness is corrupted: %v", err)
+	}
+
+	if len(signers) < Quorum(len(validators)) {
+		return rejection.New(rejection.BadWitness, "witness has %d signers of %d validators, %d needed", len(signers), len(validators), Quorum(len(validators)))
+	}
+
+	if !ms.NewMuSig().VerifyBytes(message, signers, w.Signature) {
+		return rejection.New(rejection.BadWitness, "witness contains invalid signature")
 	}
 
-	return true
+	return nil
 }
@@ -1,12 +1,17 @@
 package blockchain
 
 import (
+	"encoding/json"
 	"fmt"
 	blk "github.com/Digital-Voting-Team/Digital-Voting/pkg/blockchain/block"
+	tx "github.com/Digital-Voting-Team/Digital-Voting/pkg/blockchain/transaction"
+	"os"
+	"sync"
 )
 
 type Blockchain struct {
 	Blocks []*blk.Block
+	mutex  sync.RWMutex
 }
 
 func (b *Blockchain) AddBlock(block *blk.Block) error {
@@ -14,12 +19,16 @@ func (b *Blockchain) AddBlock(block *blk.Block) error {
 		return fmt.Errorf("blk is nil")
 	}
 
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
 	b.Blocks = append(b.Blocks, block)
 
 	return nil
 }
 
 func (b *Blockchain) GetBlock(hash [32]byte) (*blk.Block, error) {
+	b.mutex.RLock()
+	defer b.mutex.RUnlock()
 	for _, current := range b.Blocks {
 		if current.GetHash() == hash {
 			return current, nil
@@ -29,7 +38,109 @@ func (b *Blockchain) GetBlock(hash [32]byte) (*blk.Block, error) {
 	return nil, fmt.Errorf("blk with given hash was not found")
 }
 
+// GetBlockByHeight returns the block at height, genesis being at height 0
+func (b *Blockchain) GetBlockByHeight(height uint64) (*blk.Block, error) {
+	b.mutex.RLock()
+	defer b.mutex.RUnlock()
+	if height >= uint64(len(b.Blocks)) {
+		return nil, fmt.Errorf("blk at height %d was not found", height)
+	}
+
+	return b.Blocks[height], nil
+}
+
+// FindTransaction returns the transaction with given hash and height of the block it is included in
+func (b *Blockchain) FindTransaction(hash [32]byte) (tx.ITransaction, uint64, error) {
+	b.mutex.RLock()
+	defer b.mutex.RUnlock()
+	for height, current := range b.Blocks {
+		for _, transaction := range current.Body.Transactions {
+			if transaction.GetHash() == hash {
+				return transaction, uint64(height), nil
+			}
+		}
+	}
+
+	return nil, 0, fmt.Errorf("transaction with given hash was not found")
+}
+
 // GetLastBlockHash get last blk hash
 func (b *Blockchain) GetLastBlockHash() [32]byte {
+	b.mutex.RLock()
+	defer b.mutex.RUnlock()
 	return b.Blocks[len(b.Blocks)-1].GetHash()
 }
+
+// GetLastBlock get last blk
+func (b *Blockchain) GetLastBlock() *blk.Block {
+	b.mutex.RLock()
+	defer b.mutex.RUnlock()
+	return b.Blocks[len(b.Blocks)-1]
+}
+
+// Height returns the height the next blk will be added at, genesis being at height 0
+func (b *Blockchain) Height() uint64 {
+	b.mutex.RLock()
+	defer b.mutex.RUnlock()
+	return uint64(len(b.Blocks))
+}
+
+// Params returns chain parameters recorded in genesis, chains without them use DefaultChainParams
+func (b *Blockchain) Params() blk.ChainParams {
+	if b == nil {
+		return blk.DefaultChainParams()
+	}
+	b.mutex.RLock()
+	defer b.mutex.RUnlock()
+	if len(b.Blocks) == 0 || b.Blocks[0].Header.Params == nil {
+		return blk.DefaultChainParams()
+	}
+	return *b.Blocks[0].Header.Params
+}
+
+// GetBlocks returns a copy of the list of blocks starting with genesis
+func (b *Blockchain) GetBlocks() []*blk.Block {
+	b.mutex.RLock()
+	defer b.mutex.RUnlock()
+	return append([]*blk.Block{}, b.Blocks...)
+}
+
+// SaveToFile writes blocks as a JSON list, the file is replaced only once it was fully written
+func (b *Blockchain) SaveToFile(path string) error {
+	b.mutex.RLock()
+	marshalled, err := json.Marshal(b.Blocks)
+	b.mutex.RUnlock()
+	if err != nil {
+		return err
+	}
+
+	err = os.WriteFile(path+".tmp", marshalled, 0o600)
+	if err != nil {
+		return err
+	}
+	return os.Rename(path+".tmp", path)
+}
+
+// LoadFromFile reads blocks saved by SaveToFile
+func LoadFromFile(path string) (*Blockchain, error) {
+	marshalled, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	marshalledBlocks := []json.RawMessage{}
+	err = json.Unmarshal(marshalled, &marshalledBlocks)
+	if err != nil {
+		return nil, err
+	}
+
+	b := &Blockchain{}
+	for _, marshalledBlock := range marshalledBlocks {
+		block, err := blk.UnmarshallBlock(marshalledBlock)
+		if err != nil {
+			return nil, err
+		}
+		b.Blocks = append(b.Blocks, block)
+	}
+	return b, nil
+}
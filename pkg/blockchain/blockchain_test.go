@@ -3,7 +3,9 @@ package blockchain
 import (
 	blk "github.com/Digital-Voting-Team/Digital-Voting/pkg/blockchain/block"
 	"github.com/stretchr/testify/require"
+	"path/filepath"
 	"testing"
+	"time"
 )
 
 func TestBlockchain_GetBlock(t *testing.T) {
@@ -70,3 +72,49 @@ func TestBlockchain_AddBlock(t *testing.T) {
 		})
 	}
 }
+
+func TestBlockchain_Params(t *testing.T) {
+	params := blk.ChainParams{MaxTransactions: 2, MaxBlockBytes: 1024, BlockInterval: time.Second}
+
+	tests := []struct {
+		name string
+		b    *Blockchain
+		want blk.ChainParams
+	}{
+		{
+			name: "empty blockchain",
+			b:    &Blockchain{},
+			want: blk.DefaultChainParams(),
+		},
+		{
+			name: "genesis without params",
+			b:    &Blockchain{Blocks: []*blk.Block{{}}},
+			want: blk.DefaultChainParams(),
+		},
+		{
+			name: "genesis with params",
+			b:    &Blockchain{Blocks: []*blk.Block{blk.NewGenesisBlock(params, nil), {}}},
+			want: params,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.Equal(t, tt.want, tt.b.Params())
+		})
+	}
+}
+
+func TestBlockchain_SaveToFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "blocks.json")
+	b := &Blockchain{}
+	require.NoError(t, b.AddBlock(blk.NewGenesisBlock(blk.DefaultChainParams(), nil)))
+	require.NoError(t, b.AddBlock(blk.NewBlock(nil, b.GetLastBlockHash())))
+
+	require.NoError(t, b.SaveToFile(path))
+	loaded, err := LoadFromFile(path)
+	require.NoError(t, err)
+
+	require.Equal(t, b.Height(), loaded.Height())
+	require.Equal(t, b.GetLastBlockHash(), loaded.GetLastBlockHash())
+	require.Equal(t, b.Params(), loaded.Params())
+}
@@ -5,6 +5,7 @@ import (
 	"encoding/base64"
 	"encoding/json"
 	"fmt"
+	"github.com/Digital-Voting-Team/Digital-Voting/pkg/rejection"
 	"github.com/Digital-Voting-Team/Digital-Voting/pkg/signature/keys"
 	ss "github.com/Digital-Voting-Team/Digital-Voting/pkg/signature/signatures/single_signature"
 	"github.com/Digital-Voting-Team/Digital-Voting/pkg/validator/repository"
@@ -20,6 +21,7 @@ const (
 	VotingCreation
 	Vote
 	VoteAnonymous
+	Evidence
 )
 
 type Transaction struct {
@@ -106,12 +108,44 @@ func (tx *Transaction) VerifySignature() bool {
 	)
 }
 
-func (tx *Transaction) CheckOnCreate(indexedData *repository.IndexedData) bool {
-	return tx.TxBody.CheckOnCreate(indexedData, tx.PublicKey) && tx.VerifySignature()
+func (tx *Transaction) CheckOnCreate(indexedData *repository.IndexedData) error {
+	err := tx.CheckDataOnCreate(indexedData)
+	if err != nil {
+		return err
+	}
+	return tx.checkSignature()
 }
 
-func (tx *Transaction) Verify(indexedData *repository.IndexedData) bool {
-	return tx.TxBody.Verify(indexedData, tx.PublicKey) && tx.VerifySignature()
+func (tx *Transaction) Verify(indexedData *repository.IndexedData) error {
+	err := tx.VerifyData(indexedData)
+	if err != nil {
+		return err
+	}
+	return tx.checkSignature()
+}
+
+func (tx *Transaction) checkSignature() error {
+	if !tx.VerifySignature() {
+		return rejection.New(rejection.BadSignature, "signature does not match public key of the sender")
+	}
+	return nil
+}
+
+func (tx *Transaction) CheckDataOnCreate(indexedData *repository.IndexedData) error {
+	return tx.TxBody.CheckOnCreate(indexedData, tx.PublicKey)
+}
+
+func (tx *Transaction) VerifyData(indexedData *repository.IndexedData) error {
+	return tx.TxBody.Verify(indexedData, tx.PublicKey)
+}
+
+// GetBallot returns the vote cast by the transaction, false if its body is not a vote
+func (tx *Transaction) GetBallot() (Ballot, bool) {
+	body, ok := tx.TxBody.(BallotBody)
+	if !ok {
+		return Ballot{}, false
+	}
+	return body.GetBallot(tx.PublicKey), true
 }
 
 func (tx *Transaction) GetTxBody() TxBody {
@@ -7,7 +7,25 @@ import (
 
 type TxBody interface {
 	GetSignatureMessage() string
-	CheckOnCreate(indexedData *repository.IndexedData, publicKey keys.PublicKeyBytes) bool
-	Verify(indexedData *repository.IndexedData, publicKey keys.PublicKeyBytes) bool
-	CheckPublicKeyByRole(indexedData *repository.IndexedData, publicKey keys.PublicKeyBytes) bool
+	CheckOnCreate(indexedData *repository.IndexedData, publicKey keys.PublicKeyBytes) error
+	Verify(indexedData *repository.IndexedData, publicKey keys.PublicKeyBytes) error
+	CheckPublicKeyByRole(indexedData *repository.IndexedData, publicKey keys.PublicKeyBytes) error
+}
+
+// Ballot is a vote for an answer of a voting, every voter votes once in a voting.
+// Voter is the public key of the sender or the key image of an anonymous vote
+type Ballot struct {
+	VotingLink [32]byte
+	Voter      [33]byte
+	Answer     uint8
+}
+
+// BallotBody is implemented by bodies of votes
+type BallotBody interface {
+	GetBallot(voter keys.PublicKeyBytes) Ballot
+}
+
+// EvidenceBody is implemented by bodies of evidence, every offender is slashed once
+type EvidenceBody interface {
+	GetOffender() keys.PublicKeyBytes
 }
@@ -4,13 +4,23 @@ import (
 	"github.com/Digital-Voting-Team/Digital-Voting/pkg/validator/repository"
 )
 
+// ITransaction checks return rejection.Error describing why the transaction is invalid
 type ITransaction interface {
 	GetHashString() string
 	GetHash() [32]byte
 	Print()
 	GetTxType() TxType
-	CheckOnCreate(indexedData *repository.IndexedData) bool
-	Verify(indexedData *repository.IndexedData) bool
+	CheckOnCreate(indexedData *repository.IndexedData) error
+	Verify(indexedData *repository.IndexedData) error
 	VerifySignature() bool
+	// CheckDataOnCreate and VerifyData check the transaction against indexed data without the signature,
+	// so signatures can be verified separately without holding the lock of indexed data
+	CheckDataOnCreate(indexedData *repository.IndexedData) error
+	VerifyData(indexedData *repository.IndexedData) error
 	GetTxBody() TxBody
 }
+
+// BallotCaster is implemented by transactions that may cast a vote
+type BallotCaster interface {
+	GetBallot() (Ballot, bool)
+}
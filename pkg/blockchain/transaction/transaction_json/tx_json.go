@@ -2,12 +2,21 @@ package transaction_json
 
 import (
 	"encoding/json"
-	"fmt"
 	"github.com/Digital-Voting-Team/Digital-Voting/pkg/blockchain/transaction"
 	"github.com/Digital-Voting-Team/Digital-Voting/pkg/blockchain/transaction/transaction_specific"
+	"github.com/Digital-Voting-Team/Digital-Voting/pkg/rejection"
 	"github.com/Digital-Voting-Team/Digital-Voting/pkg/signature/keys"
 	rs "github.com/Digital-Voting-Team/Digital-Voting/pkg/signature/signatures/ring_signature"
 	ss "github.com/Digital-Voting-Team/Digital-Voting/pkg/signature/signatures/single_signature"
+	"math/rand"
+)
+
+// privateKeyField is rejected in any payload, transactions are signed by clients and keys never leave them
+const privateKeyField = "private_key"
+
+var (
+	ErrPrivateKeySent = rejection.New(rejection.PrivateKeySent, "private keys are not accepted, sign the transaction on the client")
+	ErrUnsigned       = rejection.New(rejection.Unsigned, "transaction is not signed")
 )
 
 type JSONTransaction struct {
@@ -16,10 +25,6 @@ type JSONTransaction struct {
 	TxBody     transaction.TxBody `json:"tx_body,omitempty"`
 	VotingLink [32]byte           `json:"voting_link,omitempty"`
 	Answer     uint8              `json:"answer,omitempty"`
-	RingSize   uint8              `json:"ring_size,omitempty"`
-
-	// TODO: consider not sending PrivateKey and moving signing to the client for security reasons
-	PrivateKey keys.PrivateKeyBytes `json:"private_key,omitempty"`
 
 	Data  []byte `json:"data,omitempty"`
 	Nonce uint32 `json:"nonce,omitempty"`
@@ -30,23 +35,52 @@ type JSONTransaction struct {
 	RingSignature rs.RingSignatureBytes `json:"ring_signature,omitempty"`
 	KeyImage      rs.KeyImageBytes      `json:"key_image,omitempty"`
 	PublicKeys    []keys.PublicKeyBytes `json:"public_keys,omitempty"`
+	PowNonce      uint64                `json:"pow_nonce,omitempty"`
 }
 
-// UnmarshallJSON unmarshalls the JSON representation of the ITransaction into the ITransaction itself
-// the function also unmarshalls other useful data like PrivateKey for signing and (or) RingSize for ring signature.
-// This function unmarshalls in 2 cases:
-// - when it is a new transaction ready to be signed
-// - when it is already signed transaction
+// UnmarshallJSON unmarshalls the JSON representation of a signed ITransaction into the ITransaction itself,
+// errors are rejections: unsigned transactions and payloads carrying a private key are not accepted
 func (tx *JSONTransaction) UnmarshallJSON(marshalledTransaction []byte) (transaction.ITransaction, error) {
-	temp := map[string]interface{}{}
-	err := json.Unmarshal(marshalledTransaction, &temp)
+	unmarshalled, err := tx.unmarshall(marshalledTransaction)
 	if err != nil {
 		return nil, err
 	}
-	_ = json.Unmarshal(marshalledTransaction, tx)
+	if !isSigned(unmarshalled) {
+		return nil, ErrUnsigned
+	}
+	return unmarshalled, nil
+}
+
+// UnmarshallUnsignedJSON unmarshalls a transaction that is about to be signed by the client,
+// zero nonce is replaced with a random one so the signing payload is final
+func (tx *JSONTransaction) UnmarshallUnsignedJSON(marshalledTransaction []byte) (transaction.ITransaction, error) {
+	unmarshalled, err := tx.unmarshall(marshalledTransaction)
+	if err != nil {
+		return nil, err
+	}
+	switch exact := unmarshalled.(type) {
+	case *transaction.Transaction:
+		if exact.Nonce == 0 {
+			exact.Nonce = uint32(rand.Int())
+		}
+	case *transaction_specific.TxVoteAnonymous:
+		if exact.Nonce == 0 {
+			exact.Nonce = uint32(rand.Int())
+		}
+	}
+	return unmarshalled, nil
+}
 
-	// Nonce is available only if it is already created and marshalled transaction
-	newTxFlag := tx.Nonce == 0
+func (tx *JSONTransaction) unmarshall(marshalledTransaction []byte) (transaction.ITransaction, error) {
+	temp := map[string]json.RawMessage{}
+	err := json.Unmarshal(marshalledTransaction, &temp)
+	if err != nil {
+		return nil, rejection.New(rejection.MalformedTransaction, "%v", err)
+	}
+	if _, exists := temp[privateKeyField]; exists {
+		return nil, ErrPrivateKeySent
+	}
+	_ = json.Unmarshal(marshalledTransaction, tx)
 
 	var txBody transaction.TxBody
 
@@ -60,23 +94,20 @@ func (tx *JSONTransaction) UnmarshallJSON(marshalledTransaction []byte) (transac
 		txBody = new(transaction_specific.TxVotingCreation)
 	case transaction.Vote:
 		txBody = new(transaction_specific.TxVote)
+	case transaction.Evidence:
+		txBody = new(transaction_specific.TxEvidence)
 	case transaction.VoteAnonymous:
 		// VoteAnonymous case is specific since this transaction is not usual and uses a different signature
-		var returnTransaction *transaction_specific.TxVoteAnonymous
-		// Check whether it is new transaction or just for verification
-		if newTxFlag {
-			returnTransaction = transaction_specific.NewTxVoteAnonymous(tx.VotingLink, tx.Answer)
-		} else {
-			returnTransaction = &transaction_specific.TxVoteAnonymous{
-				TxType:     tx.TxType,
-				VotingLink: tx.VotingLink,
-
-				Answer:        tx.Answer,
-				Nonce:         tx.Nonce,
-				RingSignature: tx.RingSignature,
-				KeyImage:      tx.KeyImage,
-				PublicKeys:    tx.PublicKeys,
-			}
+		returnTransaction := &transaction_specific.TxVoteAnonymous{
+			TxType:     tx.TxType,
+			VotingLink: tx.VotingLink,
+
+			Answer:        tx.Answer,
+			Nonce:         tx.Nonce,
+			RingSignature: tx.RingSignature,
+			KeyImage:      tx.KeyImage,
+			PublicKeys:    tx.PublicKeys,
+			PowNonce:      tx.PowNonce,
 		}
 
 		if len(tx.Data) != 0 {
@@ -85,31 +116,23 @@ func (tx *JSONTransaction) UnmarshallJSON(marshalledTransaction []byte) (transac
 
 		return returnTransaction, nil
 	default:
-		return nil, fmt.Errorf("unknown tx type: %d", tx.TxType)
+		return nil, rejection.New(rejection.MalformedTransaction, "unknown tx type: %d", tx.TxType)
 	}
 
-	// Marshall and unmarshall TxBody to set its fields
-	marshal, _ := json.Marshal(temp["tx_body"])
-
-	err = json.Unmarshal(marshal, txBody)
-	if err != nil {
-		return nil, err
+	if marshalledBody, exists := temp["tx_body"]; exists {
+		err = json.Unmarshal(marshalledBody, txBody)
+		if err != nil {
+			return nil, rejection.New(rejection.MalformedTransaction, "tx_body: %v", err)
+		}
 	}
 	tx.TxBody = txBody
 
-	var returnTransaction *transaction.Transaction
-
-	// Check whether it is new transaction or just for verification
-	if newTxFlag {
-		returnTransaction = transaction.NewTransaction(tx.TxType, tx.TxBody)
-	} else {
-		returnTransaction = &transaction.Transaction{
-			TxType:    tx.TxType,
-			TxBody:    tx.TxBody,
-			Nonce:     tx.Nonce,
-			Signature: tx.Signature,
-			PublicKey: tx.PublicKey,
-		}
+	returnTransaction := &transaction.Transaction{
+		TxType:    tx.TxType,
+		TxBody:    tx.TxBody,
+		Nonce:     tx.Nonce,
+		Signature: tx.Signature,
+		PublicKey: tx.PublicKey,
 	}
 
 	if len(tx.Data) != 0 {
@@ -118,3 +141,14 @@ func (tx *JSONTransaction) UnmarshallJSON(marshalledTransaction []byte) (transac
 
 	return returnTransaction, nil
 }
+
+func isSigned(unmarshalled transaction.ITransaction) bool {
+	switch exact := unmarshalled.(type) {
+	case *transaction.Transaction:
+		return exact.Signature != ss.SingleSignatureBytes{} && exact.PublicKey != keys.PublicKeyBytes{}
+	case *transaction_specific.TxVoteAnonymous:
+		return len(exact.RingSignature) != 0 && len(exact.PublicKeys) != 0 && exact.KeyImage != rs.KeyImageBytes{}
+	default:
+		return false
+	}
+}
@@ -1,8 +1,10 @@
 package transaction_json
 
 import (
+	"encoding/json"
 	"github.com/Digital-Voting-Team/Digital-Voting/pkg/blockchain/transaction"
 	"github.com/Digital-Voting-Team/Digital-Voting/pkg/blockchain/transaction/transaction_specific"
+	"github.com/Digital-Voting-Team/Digital-Voting/pkg/consensus/evidence"
 	"github.com/Digital-Voting-Team/Digital-Voting/pkg/models/account"
 	"github.com/Digital-Voting-Team/Digital-Voting/pkg/signature/keys"
 	rs "github.com/Digital-Voting-Team/Digital-Voting/pkg/signature/signatures/ring_signature"
@@ -27,8 +29,7 @@ func TestUnmarshallJSON(t *testing.T) {
 			],
 			"public_key": [
 				65, 66, 67, 68, 69, 70, 71, 72, 73, 74, 75, 76, 77, 78, 79, 80, 81, 82, 83, 84, 85, 86, 87, 88, 89, 90, 91, 92, 93, 94, 95, 96, 97
-			],
-			"private_key": [1, 2, 3]
+			]
 		  }`)
 
 	wantTxAccCreation := &transaction.Transaction{
@@ -54,8 +55,7 @@ func TestUnmarshallJSON(t *testing.T) {
 			"key_image": [1, 2, 3],
 			"public_keys": [
 				[1, 2, 3]
-			],
-			"private_key": [1, 2, 3]
+			]
 		  }`)
 
 	wantTxVoteAnonymous := &transaction_specific.TxVoteAnonymous{
@@ -68,6 +68,21 @@ func TestUnmarshallJSON(t *testing.T) {
 		PublicKeys:    []keys.PublicKeyBytes{{1, 2, 3}},
 	}
 
+	wantTxEvidence := &transaction.Transaction{
+		TxType: transaction.Evidence,
+		TxBody: transaction_specific.NewTxEvidence(*evidence.NewVoteEvidence(
+			evidence.Vote{VoteType: evidence.Precommit, Height: 2, BlockHash: [32]byte{1}, Validator: keys.PublicKeyBytes{7}, Signature: ss.SingleSignatureBytes{1}},
+			evidence.Vote{VoteType: evidence.Precommit, Height: 2, BlockHash: [32]byte{2}, Validator: keys.PublicKeyBytes{7}, Signature: ss.SingleSignatureBytes{2}},
+		), keys.PublicKeyBytes{8}),
+		Nonce:     1,
+		Signature: ss.SingleSignatureBytes{3},
+		PublicKey: keys.PublicKeyBytes{8},
+	}
+	marshalledTxEvidence, _ := json.Marshal(wantTxEvidence)
+
+	marshalledWithPrivateKey := []byte(`{"tx_type": 0, "tx_body": {"account_type": 0}, "nonce": 1, "private_key": [1, 2, 3]}`)
+	marshalledUnsigned := []byte(`{"tx_type": 0, "tx_body": {"account_type": 0}, "nonce": 1}`)
+
 	type args struct {
 		data []byte
 	}
@@ -75,7 +90,7 @@ func TestUnmarshallJSON(t *testing.T) {
 		name    string
 		args    args
 		want    transaction.ITransaction
-		wantErr bool
+		wantErr error
 	}{
 		{
 			name: "Unmarshall transaction account creation",
@@ -83,24 +98,49 @@ func TestUnmarshallJSON(t *testing.T) {
 				data: marshalledTxAccCreation,
 			},
 			want:    wantTxAccCreation,
-			wantErr: false,
+			wantErr: nil,
 		},
 		{
-			name: "Unmarshall new transaction account creation",
+			name: "Unmarshall transaction vote anonymous",
 			args: args{
 				data: marshalledTxVoteAnonymous,
 			},
 			want:    wantTxVoteAnonymous,
-			wantErr: false,
+			wantErr: nil,
+		},
+		{
+			name: "Unmarshall transaction evidence",
+			args: args{
+				data: marshalledTxEvidence,
+			},
+			want:    wantTxEvidence,
+			wantErr: nil,
+		},
+		{
+			name: "Reject private key",
+			args: args{
+				data: marshalledWithPrivateKey,
+			},
+			wantErr: ErrPrivateKeySent,
+		},
+		{
+			name: "Reject unsigned transaction",
+			args: args{
+				data: marshalledUnsigned,
+			},
+			wantErr: ErrUnsigned,
 		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			got, err := (&JSONTransaction{}).UnmarshallJSON(tt.args.data)
-			if (err != nil) != tt.wantErr {
+			if err != tt.wantErr {
 				t.Errorf("UnmarshallJSON() error = %v, wantErr %v", err, tt.wantErr)
 				return
 			}
+			if err != nil {
+				return
+			}
 			if !reflect.DeepEqual(got, tt.want) {
 				t.Errorf("UnmarshallJSON() got = %v, want %v", got, tt.want)
 			}
@@ -6,6 +6,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"github.com/Digital-Voting-Team/Digital-Voting/pkg/models/account"
+	"github.com/Digital-Voting-Team/Digital-Voting/pkg/rejection"
 	"github.com/Digital-Voting-Team/Digital-Voting/pkg/signature/keys"
 	"github.com/Digital-Voting-Team/Digital-Voting/pkg/validator/repository"
 	"github.com/Digital-Voting-Team/Digital-Voting/pkg/validator/repository/account_manager"
@@ -55,18 +56,24 @@ func (tx *TxAccountCreation) IsEqual(otherTransaction *TxAccountCreation) bool {
 	return tx.GetHash() == otherTransaction.GetHash()
 }
 
-func (tx *TxAccountCreation) CheckPublicKeyByRole(indexedData *repository.IndexedData, publicKey keys.PublicKeyBytes) bool {
-	return indexedData.AccountManager.CheckPubKeyPresence(publicKey, account_manager.RegistrationAdmin)
+func (tx *TxAccountCreation) CheckPublicKeyByRole(indexedData *repository.IndexedData, publicKey keys.PublicKeyBytes) error {
+	if !indexedData.AccountManager.CheckPubKeyPresence(publicKey, account_manager.RegistrationAdmin) {
+		return rejection.New(rejection.WrongRole, "sender is not a registration admin")
+	}
+	return nil
 }
 
-func (tx *TxAccountCreation) CheckOnCreate(indexedData *repository.IndexedData, publicKey keys.PublicKeyBytes) bool {
-	return !indexedData.AccountManager.CheckPubKeyPresence(tx.NewPublicKey, account_manager.User) &&
-		!indexedData.AccountManager.CheckPubKeyPresence(tx.NewPublicKey, account_manager.RegistrationAdmin) &&
-		!indexedData.AccountManager.CheckPubKeyPresence(tx.NewPublicKey, account_manager.VotingCreationAdmin) &&
-		tx.CheckPublicKeyByRole(indexedData, publicKey)
+func (tx *TxAccountCreation) CheckOnCreate(indexedData *repository.IndexedData, publicKey keys.PublicKeyBytes) error {
+	if indexedData.AccountManager.CheckPubKeyPresence(tx.NewPublicKey, account_manager.User) ||
+		indexedData.AccountManager.CheckPubKeyPresence(tx.NewPublicKey, account_manager.RegistrationAdmin) ||
+		indexedData.AccountManager.CheckPubKeyPresence(tx.NewPublicKey, account_manager.VotingCreationAdmin) {
+		return rejection.New(rejection.AccountExists, "account with the new public key already exists")
+	}
+
+	return tx.CheckPublicKeyByRole(indexedData, publicKey)
 }
 
-func (tx *TxAccountCreation) Verify(indexedData *repository.IndexedData, publicKey keys.PublicKeyBytes) bool {
+func (tx *TxAccountCreation) Verify(indexedData *repository.IndexedData, publicKey keys.PublicKeyBytes) error {
 	return tx.CheckPublicKeyByRole(indexedData, publicKey)
 }
 
@@ -0,0 +1,136 @@
+package transaction_specific
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"github.com/Digital-Voting-Team/Digital-Voting/pkg/consensus/evidence"
+	"github.com/Digital-Voting-Team/Digital-Voting/pkg/rejection"
+	"github.com/Digital-Voting-Team/Digital-Voting/pkg/signature/keys"
+	"github.com/Digital-Voting-Team/Digital-Voting/pkg/validator/repository"
+	"github.com/Digital-Voting-Team/Digital-Voting/pkg/validator/repository/account_manager"
+	"github.com/Digital-Voting-Team/Digital-Voting/pkg/validator/repository/indexed_slashings"
+	"strings"
+)
+
+// TxEvidence reports a validator that signed two different blocks in the same round,
+// once included the offender is removed from the validator set for good
+type TxEvidence struct {
+	Evidence evidence.Evidence   `json:"evidence"`
+	Reporter keys.PublicKeyBytes `json:"reporter"`
+}
+
+func NewTxEvidence(evidence evidence.Evidence, reporter keys.PublicKeyBytes) *TxEvidence {
+	return &TxEvidence{Evidence: evidence, Reporter: reporter}
+}
+
+func (tx *TxEvidence) GetSignatureMessage() string {
+	return fmt.Sprint(tx.Evidence.Votes, tx.Evidence.Proposals, tx.Reporter)
+}
+
+func (tx *TxEvidence) String() string {
+	str, _ := json.Marshal(tx)
+	return string(str)
+}
+
+func (tx *TxEvidence) GetHashString() string {
+	hash := tx.GetHash()
+
+	return base64.URLEncoding.EncodeToString(hash[:])
+}
+
+func (tx *TxEvidence) GetHash() [32]byte {
+	hasher := sha256.New()
+
+	bytes := []byte(tx.GetSignatureMessage())
+	hasher.Write(bytes)
+	bytes = hasher.Sum(nil)
+
+	hasher.Reset()
+	hasher.Write(bytes)
+
+	hash := [32]byte{}
+	copy(hash[:], hasher.Sum(nil)[:32])
+
+	return hash
+}
+
+func (tx *TxEvidence) IsEqual(otherTransaction *TxEvidence) bool {
+	return tx.GetHash() == otherTransaction.GetHash()
+}
+
+// CheckPublicKeyByRole accepts evidence from validators only, they are the ones that see consensus messages
+func (tx *TxEvidence) CheckPublicKeyByRole(indexedData *repository.IndexedData, publicKey keys.PublicKeyBytes) error {
+	if !indexedData.AccountManager.CheckPubKeyPresence(publicKey, account_manager.Validator) {
+		return rejection.New(rejection.WrongRole, "sender is not a validator")
+	}
+	if tx.Reporter != publicKey {
+		return rejection.New(rejection.WrongRole, "sender is not the reporter of the evidence")
+	}
+	return nil
+}
+
+func (tx *TxEvidence) CheckOnCreate(indexedData *repository.IndexedData, publicKey keys.PublicKeyBytes) error {
+	return tx.Verify(indexedData, publicKey)
+}
+
+// GetOffender returns the validator the evidence is against
+func (tx *TxEvidence) GetOffender() keys.PublicKeyBytes {
+	return tx.Evidence.Offender()
+}
+
+// Verify checks the offender against the validator set in force at the height of the evidence,
+// evidence of heights not decided yet is not accepted
+func (tx *TxEvidence) Verify(indexedData *repository.IndexedData, publicKey keys.PublicKeyBytes) error {
+	offender := tx.Evidence.Offender()
+	if indexedData.SlashingManager.IsSlashed(offender) {
+		return rejection.New(rejection.AlreadySlashed, "validator %x is already slashed", offender)
+	}
+	height, _ := tx.Evidence.Position()
+	if height > indexedData.Height {
+		return rejection.New(rejection.UnknownAccount, "evidence is of height %d above the height of the chain", height)
+	}
+	if !isValidatorAt(indexedData, offender, height) {
+		return rejection.New(rejection.UnknownAccount, "offender %x is not a validator at height %d", offender, height)
+	}
+
+	err := tx.Evidence.Verify()
+	if err != nil {
+		return err
+	}
+	return tx.CheckPublicKeyByRole(indexedData, publicKey)
+}
+
+func isValidatorAt(indexedData *repository.IndexedData, publicKey keys.PublicKeyBytes, height uint64) bool {
+	for _, validator := range indexedData.ValidatorHistory.At(height) {
+		if validator == publicKey {
+			return true
+		}
+	}
+	return false
+}
+
+// Slashing returns the record the evidence leaves once included
+func (tx *TxEvidence) Slashing() indexed_slashings.SlashingDTO {
+	height, round := tx.Evidence.Position()
+	kind := "proposal"
+	if tx.Evidence.IsVote() {
+		kind = strings.ToLower(tx.Evidence.Votes[0].VoteType.String())
+	}
+
+	return indexed_slashings.SlashingDTO{
+		Offender:     tx.Evidence.Offender(),
+		Reporter:     tx.Reporter,
+		Kind:         kind,
+		Height:       height,
+		Round:        round,
+		BlockHashes:  tx.Evidence.BlockHashes(),
+		EvidenceHash: tx.GetHash(),
+	}
+}
+
+func (tx *TxEvidence) ActualizeIndexedData(indexedData *repository.IndexedData) {
+	indexedData.AccountManager.RemovePubKey(tx.Evidence.Offender(), account_manager.Validator)
+	indexedData.SlashingManager.AddSlashing(tx.Slashing())
+}
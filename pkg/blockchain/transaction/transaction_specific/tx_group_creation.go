@@ -5,6 +5,7 @@ import (
 	"encoding/base64"
 	"encoding/json"
 	"fmt"
+	"github.com/Digital-Voting-Team/Digital-Voting/pkg/rejection"
 	"github.com/Digital-Voting-Team/Digital-Voting/pkg/signature/keys"
 	"github.com/Digital-Voting-Team/Digital-Voting/pkg/validator/repository"
 	"github.com/Digital-Voting-Team/Digital-Voting/pkg/validator/repository/account_manager"
@@ -83,30 +84,41 @@ func (tx *TxGroupCreation) IsEqual(otherTransaction *TxGroupCreation) bool {
 	return tx.GetHash() == otherTransaction.GetHash()
 }
 
-func (tx *TxGroupCreation) CheckPublicKeyByRole(indexedData *repository.IndexedData, publicKey keys.PublicKeyBytes) bool {
-	return indexedData.AccountManager.CheckPubKeyPresence(publicKey, account_manager.RegistrationAdmin)
+func (tx *TxGroupCreation) CheckPublicKeyByRole(indexedData *repository.IndexedData, publicKey keys.PublicKeyBytes) error {
+	if !indexedData.AccountManager.CheckPubKeyPresence(publicKey, account_manager.RegistrationAdmin) {
+		return rejection.New(rejection.WrongRole, "sender is not a registration admin")
+	}
+	return nil
 }
 
-func (tx *TxGroupCreation) checkData(indexedData *repository.IndexedData) bool {
+func (tx *TxGroupCreation) checkData(indexedData *repository.IndexedData) error {
+	if len(tx.MembersPublicKeys) == 0 || tx.GroupIdentifier == [33]byte{} || tx.GroupName == [256]byte{} {
+		return rejection.New(rejection.MissingField, "group needs identifier, name and members")
+	}
+
 	for _, pubKey := range tx.MembersPublicKeys {
 		if !indexedData.AccountManager.CheckPubKeyPresence(pubKey, account_manager.User) {
-			return false
+			return rejection.New(rejection.UnknownAccount, "member %x is not a user", pubKey)
 		}
 	}
 
-	return len(tx.MembersPublicKeys) > 0 && tx.GroupIdentifier != [33]byte{} && tx.GroupName != [256]byte{}
+	return nil
 }
 
-func (tx *TxGroupCreation) CheckOnCreate(indexedData *repository.IndexedData, publicKey keys.PublicKeyBytes) bool {
+func (tx *TxGroupCreation) CheckOnCreate(indexedData *repository.IndexedData, publicKey keys.PublicKeyBytes) error {
 	if indexedData.AccountManager.CheckPubKeyPresence(tx.GroupIdentifier, account_manager.GroupIdentifier) {
-		return false
+		return rejection.New(rejection.GroupExists, "group with the identifier already exists")
 	}
 
-	return tx.checkData(indexedData) && tx.CheckPublicKeyByRole(indexedData, publicKey)
+	return tx.Verify(indexedData, publicKey)
 }
 
-func (tx *TxGroupCreation) Verify(indexedData *repository.IndexedData, publicKey keys.PublicKeyBytes) bool {
-	return tx.checkData(indexedData) && tx.CheckPublicKeyByRole(indexedData, publicKey)
+func (tx *TxGroupCreation) Verify(indexedData *repository.IndexedData, publicKey keys.PublicKeyBytes) error {
+	err := tx.checkData(indexedData)
+	if err != nil {
+		return err
+	}
+	return tx.CheckPublicKeyByRole(indexedData, publicKey)
 }
 
 func (tx *TxGroupCreation) ActualizeIndexedData(indexedData *repository.IndexedData) {
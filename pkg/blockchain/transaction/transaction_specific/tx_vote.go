@@ -5,6 +5,8 @@ import (
 	"encoding/base64"
 	"encoding/json"
 	"fmt"
+	"github.com/Digital-Voting-Team/Digital-Voting/pkg/blockchain/transaction"
+	"github.com/Digital-Voting-Team/Digital-Voting/pkg/rejection"
 	"github.com/Digital-Voting-Team/Digital-Voting/pkg/signature/keys"
 	"github.com/Digital-Voting-Team/Digital-Voting/pkg/validator/repository"
 	"github.com/Digital-Voting-Team/Digital-Voting/pkg/validator/repository/account_manager"
@@ -55,38 +57,61 @@ func (tx *TxVote) IsEqual(otherTransaction *TxVote) bool {
 	return tx.GetHash() == otherTransaction.GetHash()
 }
 
-func (tx *TxVote) CheckPublicKeyByRole(indexedData *repository.IndexedData, publicKey keys.PublicKeyBytes) bool {
+func (tx *TxVote) CheckPublicKeyByRole(indexedData *repository.IndexedData, publicKey keys.PublicKeyBytes) error {
+	return checkVoter(indexedData, tx.VotingLink, publicKey)
+}
+
+// checkVoter checks that the voter is a user whitelisted in the voting directly or through a group
+func checkVoter(indexedData *repository.IndexedData, votingLink [32]byte, publicKey keys.PublicKeyBytes) error {
 	if !indexedData.AccountManager.CheckPubKeyPresence(publicKey, account_manager.User) {
-		return false
+		return rejection.New(rejection.WrongRole, "voter %x is not a user", publicKey)
 	}
 
-	whiteList := indexedData.VotingManager.GetVoting(tx.VotingLink).Whitelist
+	whiteList := indexedData.VotingManager.GetVoting(votingLink).Whitelist
 	for _, identifier := range whiteList {
 		if indexedData.GroupManager.IsGroupMember(identifier, publicKey) || identifier == publicKey {
-			return true
+			return nil
 		}
 	}
 
-	return false
+	return rejection.New(rejection.NotWhitelisted, "voter %x is not in the whitelist", publicKey)
 }
 
-func (tx *TxVote) checkData(indexedData *repository.IndexedData) bool {
-	indexedVoting := indexedData.VotingManager.GetVoting(tx.VotingLink)
+// checkVoting checks that the voting exists, is not expired and has the answer
+func checkVoting(indexedData *repository.IndexedData, votingLink [32]byte, answer uint8) error {
+	indexedVoting := indexedData.VotingManager.GetVoting(votingLink)
 	if indexedVoting.Hash == [32]byte{} {
-		return false
+		return rejection.New(rejection.UnknownVoting, "voting %x does not exist", votingLink)
 	}
 
-	if uint32(time.Now().Unix()) > indexedVoting.ExpirationDate || tx.Answer < 0 || tx.Answer >= uint8(len(indexedVoting.Answers)) {
-		return false
+	if uint32(time.Now().Unix()) > indexedVoting.ExpirationDate {
+		return rejection.New(rejection.VotingExpired, "voting %x expired", votingLink)
 	}
 
-	return true
+	if answer >= uint8(len(indexedVoting.Answers)) {
+		return rejection.New(rejection.InvalidAnswer, "voting has %d answers, got answer %d", len(indexedVoting.Answers), answer)
+	}
+
+	return nil
+}
+
+func (tx *TxVote) CheckOnCreate(indexedData *repository.IndexedData, publicKey keys.PublicKeyBytes) error {
+	return tx.Verify(indexedData, publicKey)
 }
 
-func (tx *TxVote) CheckOnCreate(indexedData *repository.IndexedData, publicKey keys.PublicKeyBytes) bool {
-	return tx.checkData(indexedData) && tx.CheckPublicKeyByRole(indexedData, publicKey)
+func (tx *TxVote) Verify(indexedData *repository.IndexedData, publicKey keys.PublicKeyBytes) error {
+	err := checkVoting(indexedData, tx.VotingLink, tx.Answer)
+	if err != nil {
+		return err
+	}
+
+	if indexedData.VotingManager.HasVoted(tx.VotingLink, publicKey) {
+		return rejection.New(rejection.DuplicateVote, "voter %x already voted", publicKey)
+	}
+
+	return tx.CheckPublicKeyByRole(indexedData, publicKey)
 }
 
-func (tx *TxVote) Verify(indexedData *repository.IndexedData, publicKey keys.PublicKeyBytes) bool {
-	return tx.checkData(indexedData) && tx.CheckPublicKeyByRole(indexedData, publicKey)
+func (tx *TxVote) GetBallot(voter keys.PublicKeyBytes) transaction.Ballot {
+	return transaction.Ballot{VotingLink: tx.VotingLink, Voter: voter, Answer: tx.Answer}
 }
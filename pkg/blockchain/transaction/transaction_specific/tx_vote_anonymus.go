@@ -1,18 +1,21 @@
 package transaction_specific
 
 import (
+	"bytes"
 	"crypto/sha256"
 	"encoding/base64"
+	"encoding/binary"
 	"encoding/json"
 	"fmt"
 	"github.com/Digital-Voting-Team/Digital-Voting/pkg/blockchain/transaction"
+	"github.com/Digital-Voting-Team/Digital-Voting/pkg/rejection"
 	"github.com/Digital-Voting-Team/Digital-Voting/pkg/signature/keys"
 	rs "github.com/Digital-Voting-Team/Digital-Voting/pkg/signature/signatures/ring_signature"
 	"github.com/Digital-Voting-Team/Digital-Voting/pkg/validator/repository"
-	"github.com/Digital-Voting-Team/Digital-Voting/pkg/validator/repository/account_manager"
 	"log"
+	"math/bits"
 	"math/rand"
-	"time"
+	"sort"
 )
 
 type TxVoteAnonymous struct {
@@ -24,6 +27,9 @@ type TxVoteAnonymous struct {
 	RingSignature rs.RingSignatureBytes `json:"ring_signature"`
 	KeyImage      rs.KeyImageBytes      `json:"key_image"`
 	PublicKeys    []keys.PublicKeyBytes `json:"public_keys"`
+
+	// PowNonce proves work spent on the vote for admission to MemPool, it is not part of the hash
+	PowNonce uint64 `json:"pow_nonce,omitempty"`
 }
 
 func (tx *TxVoteAnonymous) GetTxType() transaction.TxType {
@@ -88,6 +94,45 @@ func (tx *TxVoteAnonymous) GetHash() [32]byte {
 	return hash
 }
 
+// GetWorkHash commits to the hash of the vote and PowNonce
+func (tx *TxVoteAnonymous) GetWorkHash() [32]byte {
+	return workHash(tx.GetHash(), tx.PowNonce)
+}
+
+// CheckProofOfWork reports whether the work hash starts with at least difficulty zero bits
+func (tx *TxVoteAnonymous) CheckProofOfWork(difficulty uint8) bool {
+	return leadingZeroBits(tx.GetWorkHash()) >= int(difficulty)
+}
+
+// SolveProofOfWork searches for PowNonce, it has to be called after signing since the signature is part of the hash
+func (tx *TxVoteAnonymous) SolveProofOfWork(difficulty uint8) {
+	hash := tx.GetHash()
+	for nonce := uint64(0); ; nonce++ {
+		if leadingZeroBits(workHash(hash, nonce)) >= int(difficulty) {
+			tx.PowNonce = nonce
+			return
+		}
+	}
+}
+
+func workHash(hash [32]byte, nonce uint64) [32]byte {
+	bytes := make([]byte, 0, len(hash)+8)
+	bytes = append(bytes, hash[:]...)
+	bytes = binary.BigEndian.AppendUint64(bytes, nonce)
+	return sha256.Sum256(bytes)
+}
+
+func leadingZeroBits(hash [32]byte) int {
+	count := 0
+	for _, b := range hash {
+		if b != 0 {
+			return count + bits.LeadingZeros8(b)
+		}
+		count += 8
+	}
+	return count
+}
+
 func (tx *TxVoteAnonymous) IsEqual(otherTransaction *TxVoteAnonymous) bool {
 	return tx.GetHash() == otherTransaction.GetHash()
 }
@@ -97,46 +142,83 @@ func (tx *TxVoteAnonymous) VerifySignature() bool {
 	return ecdsaRs.VerifyBytes(tx.GetSignatureMessage(), tx.PublicKeys, tx.RingSignature, tx.KeyImage)
 }
 
-func (tx *TxVoteAnonymous) checkData(indexedData *repository.IndexedData) bool {
-	indexedVoting := indexedData.VotingManager.GetVoting(tx.VotingLink)
-	if indexedVoting.Hash == [32]byte{} {
-		return false
+func (tx *TxVoteAnonymous) checkData(indexedData *repository.IndexedData) error {
+	err := checkVoting(indexedData, tx.VotingLink, tx.Answer)
+	if err != nil {
+		return err
 	}
 
-	if uint32(time.Now().Unix()) > indexedVoting.ExpirationDate || tx.Answer < 0 || tx.Answer >= uint8(len(indexedVoting.Answers)) {
-		return false
+	if indexedData.VotingManager.HasVoted(tx.VotingLink, tx.KeyImage) {
+		return rejection.New(rejection.DuplicateKeyImage, "key image %x already voted", tx.KeyImage)
 	}
 
-	whiteList := indexedData.VotingManager.GetVoting(tx.VotingLink).Whitelist
-
 	for _, pubKey := range tx.PublicKeys {
-		if !indexedData.AccountManager.CheckPubKeyPresence(pubKey, account_manager.User) {
-			return false
+		err = checkVoter(indexedData, tx.VotingLink, pubKey)
+		if err != nil {
+			return err
 		}
+	}
 
-		flag := false
+	return nil
+}
 
-		for _, identifier := range whiteList {
-			if indexedData.GroupManager.IsGroupMember(identifier, pubKey) || identifier == pubKey {
-				flag = true
-				break
-			}
+// RingMembers returns keys allowed to be in the ring of an anonymous vote in a voting:
+// users in its whitelist directly or through a group, ordered by key
+func RingMembers(indexedData *repository.IndexedData, votingLink [32]byte) []keys.PublicKeyBytes {
+	candidates := map[keys.PublicKeyBytes]struct{}{}
+	for _, identifier := range indexedData.VotingManager.GetVoting(votingLink).Whitelist {
+		candidates[identifier] = struct{}{}
+		for _, member := range indexedData.GroupManager.GetGroup(identifier).MembersPublicKeys {
+			candidates[member] = struct{}{}
 		}
+	}
 
-		if !flag {
-			return false
+	members := make([]keys.PublicKeyBytes, 0, len(candidates))
+	for candidate := range candidates {
+		if checkVoter(indexedData, votingLink, candidate) == nil {
+			members = append(members, candidate)
 		}
 	}
+	sort.Slice(members, func(i, j int) bool {
+		return bytes.Compare(members[i][:], members[j][:]) < 0
+	})
+	return members
+}
+
+func (tx *TxVoteAnonymous) CheckOnCreate(indexedData *repository.IndexedData) error {
+	err := tx.CheckDataOnCreate(indexedData)
+	if err != nil {
+		return err
+	}
+	return tx.checkSignature()
+}
+
+func (tx *TxVoteAnonymous) Verify(indexedData *repository.IndexedData) error {
+	err := tx.VerifyData(indexedData)
+	if err != nil {
+		return err
+	}
+	return tx.checkSignature()
+}
+
+func (tx *TxVoteAnonymous) checkSignature() error {
+	if !tx.VerifySignature() {
+		return rejection.New(rejection.BadSignature, "ring signature does not match the ring")
+	}
+	return nil
+}
 
-	return true
+func (tx *TxVoteAnonymous) CheckDataOnCreate(indexedData *repository.IndexedData) error {
+	return tx.checkData(indexedData)
 }
 
-func (tx *TxVoteAnonymous) CheckOnCreate(indexedData *repository.IndexedData) bool {
-	return tx.checkData(indexedData) && tx.VerifySignature()
+func (tx *TxVoteAnonymous) VerifyData(indexedData *repository.IndexedData) error {
+	return tx.checkData(indexedData)
 }
 
-func (tx *TxVoteAnonymous) Verify(indexedData *repository.IndexedData) bool {
-	return tx.checkData(indexedData) && tx.VerifySignature()
+// GetBallot identifies the voter by the key image, it is the same for all votes of one ring member
+func (tx *TxVoteAnonymous) GetBallot() (transaction.Ballot, bool) {
+	return transaction.Ballot{VotingLink: tx.VotingLink, Voter: tx.KeyImage, Answer: tx.Answer}, true
 }
 
 func (tx *TxVoteAnonymous) GetTxBody() transaction.TxBody {
@@ -5,6 +5,7 @@ import (
 	"encoding/base64"
 	"encoding/json"
 	"fmt"
+	"github.com/Digital-Voting-Team/Digital-Voting/pkg/rejection"
 	"github.com/Digital-Voting-Team/Digital-Voting/pkg/signature/keys"
 	"github.com/Digital-Voting-Team/Digital-Voting/pkg/validator/repository"
 	"github.com/Digital-Voting-Team/Digital-Voting/pkg/validator/repository/account_manager"
@@ -69,27 +70,38 @@ func (tx *TxVotingCreation) IsEqual(otherTransaction *TxAccountCreation) bool {
 	return tx.GetHash() == otherTransaction.GetHash()
 }
 
-func (tx *TxVotingCreation) CheckPublicKeyByRole(indexedData *repository.IndexedData, publicKey keys.PublicKeyBytes) bool {
-	return indexedData.AccountManager.CheckPubKeyPresence(publicKey, account_manager.VotingCreationAdmin)
+func (tx *TxVotingCreation) CheckPublicKeyByRole(indexedData *repository.IndexedData, publicKey keys.PublicKeyBytes) error {
+	if !indexedData.AccountManager.CheckPubKeyPresence(publicKey, account_manager.VotingCreationAdmin) {
+		return rejection.New(rejection.WrongRole, "sender is not a voting creation admin")
+	}
+	return nil
 }
 
-func (tx *TxVotingCreation) checkData(indexedData *repository.IndexedData) bool {
+func (tx *TxVotingCreation) checkData(indexedData *repository.IndexedData) error {
 	// TODO: think of date validation
+	if len(tx.Answers) == 0 || len(tx.Whitelist) == 0 || tx.VotingDescription == [1024]byte{} {
+		return rejection.New(rejection.MissingField, "voting needs description, answers and whitelist")
+	}
+
 	for _, pubKey := range tx.Whitelist {
 		if !indexedData.AccountManager.CheckPubKeyPresence(pubKey, account_manager.User) &&
 			!indexedData.AccountManager.CheckPubKeyPresence(pubKey, account_manager.GroupIdentifier) {
-			return false
+			return rejection.New(rejection.UnknownAccount, "whitelisted %x is neither a user nor a group", pubKey)
 		}
 	}
-	return len(tx.Answers) > 0 && len(tx.Whitelist) > 0 && tx.VotingDescription != [1024]byte{}
+	return nil
 }
 
-func (tx *TxVotingCreation) CheckOnCreate(indexedData *repository.IndexedData, publicKey keys.PublicKeyBytes) bool {
-	return tx.checkData(indexedData) && tx.CheckPublicKeyByRole(indexedData, publicKey)
+func (tx *TxVotingCreation) CheckOnCreate(indexedData *repository.IndexedData, publicKey keys.PublicKeyBytes) error {
+	return tx.Verify(indexedData, publicKey)
 }
 
-func (tx *TxVotingCreation) Verify(indexedData *repository.IndexedData, publicKey keys.PublicKeyBytes) bool {
-	return tx.checkData(indexedData) && tx.CheckPublicKeyByRole(indexedData, publicKey)
+func (tx *TxVotingCreation) Verify(indexedData *repository.IndexedData, publicKey keys.PublicKeyBytes) error {
+	err := tx.checkData(indexedData)
+	if err != nil {
+		return err
+	}
+	return tx.CheckPublicKeyByRole(indexedData, publicKey)
 }
 
 func (tx *TxVotingCreation) ActualizeIndexedData(indexedData *repository.IndexedData) {
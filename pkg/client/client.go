@@ -0,0 +1,95 @@
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/Digital-Voting-Team/Digital-Voting/pkg/signature/keys"
+	"github.com/Digital-Voting-Team/Digital-Voting/pkg/signer"
+	"github.com/Digital-Voting-Team/Digital-Voting/pkg/validator/admission"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// DefaultPollInterval is how often AwaitInclusion asks whether a transaction is included
+const DefaultPollInterval = time.Second
+
+// Client builds, signs and submits transactions of one key pair, the private key never leaves the client
+type Client struct {
+	KeyPair *keys.KeyPair
+	Signer  *signer.TransactionSigner
+
+	// RestURL is the base URL of the rest api, e.g. http://localhost:8082
+	RestURL    string
+	HTTPClient *http.Client
+
+	// WebSocketURL is the base URL of the network node, e.g. ws://localhost:8081, used by SubmitWebSocket
+	WebSocketURL string
+
+	// Validators is a trusted validator set witnesses of proven blocks are checked against,
+	// without it Proof shows inclusion in the chain of the node only
+	Validators []keys.PublicKeyBytes
+
+	PollInterval time.Duration
+	// WorkDifficulty is the proof of work anonymous votes are solved with, it has to satisfy admission of the validator
+	WorkDifficulty uint8
+}
+
+func NewClient(restURL string, keyPair *keys.KeyPair) *Client {
+	return &Client{
+		KeyPair:        keyPair,
+		Signer:         signer.NewTransactionSigner(),
+		RestURL:        strings.TrimSuffix(restURL, "/"),
+		HTTPClient:     http.DefaultClient,
+		PollInterval:   DefaultPollInterval,
+		WorkDifficulty: admission.DefaultConfig().AnonymousVoteDifficulty,
+	}
+}
+
+// APIError is an error answered by a validator, Code is a rejection code or a code of the rest api
+type APIError struct {
+	Status  int    `json:"-"`
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
+
+func (e *APIError) Error() string {
+	return fmt.Sprintf("%s: %s", e.Code, e.Message)
+}
+
+// do sends request to the rest api and decodes the answer into result, answers with errors are returned as APIError
+func (c *Client) do(ctx context.Context, method, path string, body []byte, result interface{}) error {
+	var reader io.Reader
+	if body != nil {
+		reader = bytes.NewReader(body)
+	}
+	request, err := http.NewRequestWithContext(ctx, method, c.RestURL+path, reader)
+	if err != nil {
+		return err
+	}
+	if body != nil {
+		request.Header.Set("Content-Type", "application/json")
+	}
+
+	response, err := c.HTTPClient.Do(request)
+	if err != nil {
+		return err
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode != http.StatusOK {
+		answer := struct {
+			Error *APIError `json:"error"`
+		}{}
+		err = json.NewDecoder(response.Body).Decode(&answer)
+		if err != nil || answer.Error == nil {
+			return &APIError{Status: response.StatusCode, Code: "unknown", Message: response.Status}
+		}
+		answer.Error.Status = response.StatusCode
+		return answer.Error
+	}
+	return json.NewDecoder(response.Body).Decode(result)
+}
@@ -0,0 +1,165 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"github.com/Digital-Voting-Team/Digital-Voting/pkg/blockchain"
+	blk "github.com/Digital-Voting-Team/Digital-Voting/pkg/blockchain/block"
+	tx "github.com/Digital-Voting-Team/Digital-Voting/pkg/blockchain/transaction"
+	"github.com/Digital-Voting-Team/Digital-Voting/pkg/models/account"
+	"github.com/Digital-Voting-Team/Digital-Voting/pkg/rejection"
+	"github.com/Digital-Voting-Team/Digital-Voting/pkg/signature/curve"
+	"github.com/Digital-Voting-Team/Digital-Voting/pkg/signature/keys"
+	"github.com/Digital-Voting-Team/Digital-Voting/pkg/validator"
+	"github.com/Digital-Voting-Team/Digital-Voting/pkg/validator/connections/rest_api"
+	"github.com/Digital-Voting-Team/Digital-Voting/pkg/validator/query"
+	"github.com/Digital-Voting-Team/Digital-Voting/pkg/validator/repository"
+	"github.com/Digital-Voting-Team/Digital-Voting/pkg/validator/repository/account_manager"
+	"github.com/Digital-Voting-Team/Digital-Voting/pkg/validator/repository/indexed_votings"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+// testSubmitter includes every transaction with a valid signature in a new block right away
+type testSubmitter struct {
+	blockchain *blockchain.Blockchain
+}
+
+func (ts *testSubmitter) SubmitTransaction(transaction tx.ITransaction) error {
+	if !transaction.VerifySignature() {
+		return rejection.New(rejection.BadSignature, "signature does not match")
+	}
+	return ts.blockchain.AddBlock(blk.NewBlock([]tx.ITransaction{transaction}, ts.blockchain.GetLastBlockHash()))
+}
+
+func TestClient(t *testing.T) {
+	keyPair, _ := keys.Random(curve.NewCurve25519())
+	bc := &blockchain.Blockchain{}
+	_ = bc.AddBlock(blk.NewGenesisBlock(blk.DefaultChainParams(), nil))
+
+	indexedData := repository.NewIndexedData()
+	indexedData.AccountManager.AddPubKey(keyPair.PublicToBytes(), account_manager.User)
+	voting := indexed_votings.VotingDTO{
+		Hash:           [32]byte{1},
+		ExpirationDate: uint32(time.Now().Add(time.Hour).Unix()),
+		Answers:        [][256]byte{{'y', 'e', 's'}},
+		Whitelist:      [][33]byte{keyPair.PublicToBytes()},
+	}
+	indexedData.VotingManager.AddNewVoting(voting)
+
+	api := rest_api.NewRestApi("localhost:0", bc, indexedData, validator.NewMemPool())
+	api.Submitter = &testSubmitter{blockchain: bc}
+	server := httptest.NewServer(api.Handler())
+	defer server.Close()
+
+	client := NewClient(server.URL, keyPair)
+	client.PollInterval = 10 * time.Millisecond
+	client.WorkDifficulty = 4
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	t.Run("Submit and prove inclusion", func(t *testing.T) {
+		transaction := NewAccountCreation(account.User, keys.PublicKeyBytes{1})
+		client.Sign(transaction)
+		if err := client.Submit(ctx, transaction); err != nil {
+			t.Fatalf("Submit() error = %v", err)
+		}
+
+		included, err := client.AwaitInclusion(ctx, transaction.GetHash())
+		if err != nil || included.BlockHeight != 1 {
+			t.Fatalf("AwaitInclusion() = %v, %v, want block at height 1", included, err)
+		}
+		if _, err = client.Proof(ctx, transaction.GetHash()); err != nil {
+			t.Errorf("Proof() error = %v", err)
+		}
+
+		// the block was committed without a witness, so no trusted validator signed it
+		trusting := *client
+		trusting.Validators = []keys.PublicKeyBytes{keyPair.PublicToBytes()}
+		if _, err = trusting.Proof(ctx, transaction.GetHash()); rejection.CodeOf(err) != rejection.BadWitness {
+			t.Errorf("Proof() error = %v, want %s", err, rejection.BadWitness)
+		}
+
+		// a node answering with a merkle root of its own making is caught by the header of the block
+		forging := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if !strings.HasSuffix(r.URL.Path, "/proof") {
+				api.Handler().ServeHTTP(w, r)
+				return
+			}
+			hash, blockHash := transaction.GetHash(), bc.GetLastBlockHash()
+			_ = json.NewEncoder(w).Encode(query.ProofDTO{
+				Transaction: query.EncodeHex(hash[:]),
+				BlockHeight: 1,
+				BlockHash:   query.EncodeHex(blockHash[:]),
+				MerkleRoot:  query.EncodeHex(hash[:]),
+			})
+		}))
+		defer forging.Close()
+		forged := *client
+		forged.RestURL = forging.URL
+		if _, err = forged.Proof(ctx, transaction.GetHash()); !errors.Is(err, ErrProofBlockMismatch) {
+			t.Errorf("Proof() error = %v, want %v", err, ErrProofBlockMismatch)
+		}
+	})
+
+	t.Run("Unsigned transaction is rejected", func(t *testing.T) {
+		err := client.Submit(ctx, NewAccountCreation(account.User, keys.PublicKeyBytes{2}))
+		apiError := &APIError{}
+		if !errors.As(err, &apiError) || apiError.Code != string(rejection.Unsigned) {
+			t.Errorf("Submit() error = %v, want %s", err, rejection.Unsigned)
+		}
+	})
+
+	t.Run("Anonymous vote", func(t *testing.T) {
+		vote, err := client.VoteAnonymously(ctx, voting.Hash, 0, 0)
+		if err != nil {
+			t.Fatalf("VoteAnonymously() error = %v", err)
+		}
+		if !vote.VerifySignature() || !vote.CheckProofOfWork(client.WorkDifficulty) {
+			t.Errorf("vote is not signed or has no proof of work")
+		}
+	})
+}
+
+func TestChooseRing(t *testing.T) {
+	own := keys.PublicKeyBytes{2}
+	eligible := []keys.PublicKeyBytes{{1}, own, {3}, {4}}
+
+	tests := []struct {
+		name     string
+		eligible []keys.PublicKeyBytes
+		size     int
+		wantSize int
+		wantErr  error
+	}{
+		{name: "Part of the eligible set", eligible: eligible, size: 2, wantSize: 2},
+		{name: "Zero size takes all", eligible: eligible, size: 0, wantSize: 4},
+		{name: "Size over the eligible set takes all", eligible: eligible, size: 10, wantSize: 4},
+		{name: "Not eligible", eligible: []keys.PublicKeyBytes{{1}}, size: 1, wantErr: ErrNotEligible},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ring, err := ChooseRing(tt.eligible, own, tt.size)
+			if err != tt.wantErr {
+				t.Fatalf("ChooseRing() error = %v, want %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			if len(ring) != tt.wantSize {
+				t.Errorf("len(ring) = %d, want %d", len(ring), tt.wantSize)
+			}
+			found := false
+			for _, member := range ring {
+				found = found || member == own
+			}
+			if !found {
+				t.Errorf("ring %v does not contain own key", ring)
+			}
+		})
+	}
+}
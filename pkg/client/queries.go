@@ -0,0 +1,29 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"github.com/Digital-Voting-Team/Digital-Voting/pkg/validator/query"
+	"net/http"
+)
+
+// Voting returns a voting with its whitelist and results
+func (c *Client) Voting(ctx context.Context, votingLink [32]byte) (query.VotingDTO, error) {
+	voting := query.VotingDTO{}
+	err := c.do(ctx, http.MethodGet, "/votings/"+query.EncodeHex(votingLink[:]), nil, &voting)
+	return voting, err
+}
+
+// Results counts votes for every answer of a voting
+func (c *Client) Results(ctx context.Context, votingLink [32]byte) (query.ResultsDTO, error) {
+	results := query.ResultsDTO{}
+	err := c.do(ctx, http.MethodGet, "/votings/"+query.EncodeHex(votingLink[:])+"/results", nil, &results)
+	return results, err
+}
+
+// Block returns the block at height with its header and witness, transactions are listed by hash
+func (c *Client) Block(ctx context.Context, height uint64) (query.BlockDTO, error) {
+	block := query.BlockDTO{}
+	err := c.do(ctx, http.MethodGet, fmt.Sprintf("/blocks/%d", height), nil, &block)
+	return block, err
+}
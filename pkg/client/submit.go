@@ -0,0 +1,149 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/Digital-Voting-Team/Digital-Voting/pkg/blockchain/block/merkle_tree"
+	tx "github.com/Digital-Voting-Team/Digital-Voting/pkg/blockchain/transaction"
+	"github.com/Digital-Voting-Team/Digital-Voting/pkg/rejection"
+	"github.com/Digital-Voting-Team/Digital-Voting/pkg/validator/query"
+	"github.com/gorilla/websocket"
+	"net/http"
+	"time"
+)
+
+var (
+	// ErrInvalidProof is returned when a merkle path does not lead to the merkle root of its block
+	ErrInvalidProof = errors.New("inclusion proof does not match the merkle root")
+	// ErrProofBlockMismatch is returned when the header of the block does not hash to the block of the proof
+	// or has another merkle root
+	ErrProofBlockMismatch = errors.New("inclusion proof does not match the header of its block")
+)
+
+// Submit posts a signed transaction to the rest api, it returns once the transaction is accepted to MemPool
+func (c *Client) Submit(ctx context.Context, transaction tx.ITransaction) error {
+	marshalled, err := json.Marshal(transaction)
+	if err != nil {
+		return err
+	}
+	submitted := struct {
+		Hash string `json:"hash"`
+	}{}
+	return c.do(ctx, http.MethodPost, "/transactions", marshalled, &submitted)
+}
+
+// SubmitWebSocket sends a signed transaction to /transaction of the network node at WebSocketURL
+func (c *Client) SubmitWebSocket(ctx context.Context, transaction tx.ITransaction) error {
+	conn, _, err := websocket.DefaultDialer.DialContext(ctx, c.WebSocketURL+"/transaction", nil)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+	if deadline, ok := ctx.Deadline(); ok {
+		_ = conn.SetReadDeadline(deadline)
+	}
+
+	err = conn.WriteJSON(transaction)
+	if err != nil {
+		return err
+	}
+	answer := struct {
+		Response bool             `json:"response"`
+		Error    *rejection.Error `json:"error,omitempty"`
+	}{}
+	err = conn.ReadJSON(&answer)
+	if err != nil {
+		return err
+	}
+	if !answer.Response {
+		if answer.Error == nil {
+			return &APIError{Code: string(rejection.Internal), Message: "transaction was not accepted"}
+		}
+		return &APIError{Code: string(answer.Error.Code), Message: answer.Error.Message}
+	}
+	return nil
+}
+
+// AwaitInclusion polls the rest api until the transaction with hash is included in a block or ctx is done
+func (c *Client) AwaitInclusion(ctx context.Context, hash [32]byte) (query.TransactionDTO, error) {
+	ticker := time.NewTicker(c.PollInterval)
+	defer ticker.Stop()
+
+	for {
+		transaction := query.TransactionDTO{}
+		err := c.do(ctx, http.MethodGet, "/transactions/"+query.EncodeHex(hash[:]), nil, &transaction)
+		apiError := &APIError{}
+		switch {
+		case err == nil:
+			return transaction, nil
+		case !errors.As(err, &apiError) || apiError.Status != http.StatusNotFound:
+			return query.TransactionDTO{}, err
+		}
+
+		select {
+		case <-ctx.Done():
+			return query.TransactionDTO{}, ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+// Proof fetches the merkle path of an included transaction and checks it leads to the merkle root of its block.
+// The merkle root is taken from the header of the block, which has to hash to the block of the proof,
+// and the witness of the block is checked against Validators if they are set
+func (c *Client) Proof(ctx context.Context, hash [32]byte) (query.ProofDTO, error) {
+	proof := query.ProofDTO{}
+	err := c.do(ctx, http.MethodGet, "/transactions/"+query.EncodeHex(hash[:])+"/proof", nil, &proof)
+	if err != nil {
+		return query.ProofDTO{}, err
+	}
+
+	merkleRoot, err := query.DecodeHash(proof.MerkleRoot)
+	if err != nil {
+		return query.ProofDTO{}, fmt.Errorf("merkle root: %w", err)
+	}
+	path := make([]merkle_tree.ProofStep, len(proof.Path))
+	for i, step := range proof.Path {
+		path[i].Right = step.Right
+		path[i].Hash, err = query.DecodeHash(step.Hash)
+		if err != nil {
+			return query.ProofDTO{}, fmt.Errorf("proof step %d: %w", i, err)
+		}
+	}
+
+	if !merkle_tree.VerifyProof(hash, path, merkleRoot) {
+		return query.ProofDTO{}, ErrInvalidProof
+	}
+
+	err = c.verifyProofBlock(ctx, proof, merkleRoot)
+	if err != nil {
+		return query.ProofDTO{}, err
+	}
+	return proof, nil
+}
+
+// verifyProofBlock checks the block of the proof has merkleRoot in a header that hashes to the block hash
+func (c *Client) verifyProofBlock(ctx context.Context, proof query.ProofDTO, merkleRoot [32]byte) error {
+	blockHash, err := query.DecodeHash(proof.BlockHash)
+	if err != nil {
+		return fmt.Errorf("block hash: %w", err)
+	}
+	dto, err := c.Block(ctx, proof.BlockHeight)
+	if err != nil {
+		return err
+	}
+	block, err := query.DecodeBlock(dto)
+	if err != nil {
+		return err
+	}
+	if block.GetHash() != blockHash || block.Header.MerkleRoot != merkleRoot {
+		return ErrProofBlockMismatch
+	}
+
+	if len(c.Validators) == 0 {
+		return nil
+	}
+	return block.Witness.Verify(c.Validators, block.GetHashString())
+}
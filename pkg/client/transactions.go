@@ -0,0 +1,152 @@
+package client
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/json"
+	"errors"
+	"fmt"
+	tx "github.com/Digital-Voting-Team/Digital-Voting/pkg/blockchain/transaction"
+	ts "github.com/Digital-Voting-Team/Digital-Voting/pkg/blockchain/transaction/transaction_specific"
+	"github.com/Digital-Voting-Team/Digital-Voting/pkg/models/account"
+	"github.com/Digital-Voting-Team/Digital-Voting/pkg/signature/curve"
+	"github.com/Digital-Voting-Team/Digital-Voting/pkg/signature/keys"
+	"github.com/Digital-Voting-Team/Digital-Voting/pkg/validator/query"
+	"math/big"
+	"net/http"
+	"sort"
+	"time"
+)
+
+// ErrNotEligible is returned when the key of the client may not vote in a voting
+var ErrNotEligible = errors.New("public key is not eligible to vote in the voting")
+
+// Transactions are built with a random nonce, they are signed with Sign or SignAnonymous before submission
+
+func NewAccountCreation(accountType account.Type, publicKey keys.PublicKeyBytes) *tx.Transaction {
+	return tx.NewTransaction(tx.AccountCreation, ts.NewTxAccCreation(accountType, publicKey))
+}
+
+func NewGroupCreation(groupName string, members []keys.PublicKeyBytes) *tx.Transaction {
+	return tx.NewTransaction(tx.GroupCreation, ts.NewTxGroupCreation(groupName, members))
+}
+
+// NewVotingCreation builds a voting, whitelist holds public keys of voters and identifiers of groups
+func NewVotingCreation(expirationDate time.Time, description string, answers []string, whitelist [][33]byte) *tx.Transaction {
+	return tx.NewTransaction(tx.VotingCreation, ts.NewTxVotingCreation(expirationDate, description, answers, whitelist))
+}
+
+func NewVote(votingLink [32]byte, answer uint8) *tx.Transaction {
+	return tx.NewTransaction(tx.Vote, ts.NewTxVote(votingLink, answer))
+}
+
+func NewAnonymousVote(votingLink [32]byte, answer uint8) *ts.TxVoteAnonymous {
+	return ts.NewTxVoteAnonymous(votingLink, answer)
+}
+
+func (c *Client) PublicKey() keys.PublicKeyBytes {
+	return c.KeyPair.PublicToBytes()
+}
+
+// Sign signs transaction with the key pair of the client
+func (c *Client) Sign(transaction *tx.Transaction) {
+	c.Signer.SignTransaction(c.KeyPair, transaction)
+}
+
+// SignAnonymous signs vote with a ring containing the key of the client and solves its proof of work
+func (c *Client) SignAnonymous(vote *ts.TxVoteAnonymous, ring []keys.PublicKeyBytes) error {
+	index := -1
+	for i, member := range ring {
+		if member == c.PublicKey() {
+			index = i
+		}
+	}
+	if index == -1 {
+		return fmt.Errorf("ring does not contain the key of the client")
+	}
+
+	points := make([]*curve.Point, len(ring))
+	for i, member := range ring {
+		points[i] = curve.BytesToPoint(curve.PointCompressed(member), c.Signer.TxSignerAnonymous.Curve)
+	}
+	c.Signer.SignTransactionAnonymous(c.KeyPair, points, index, vote)
+	vote.SolveProofOfWork(c.WorkDifficulty)
+	return nil
+}
+
+// EligibleRing returns keys allowed to be in the ring of an anonymous vote in a voting
+func (c *Client) EligibleRing(ctx context.Context, votingLink [32]byte) ([]keys.PublicKeyBytes, error) {
+	marshalled, err := json.Marshal(NewAnonymousVote(votingLink, 0))
+	if err != nil {
+		return nil, err
+	}
+	prepared := query.PreparedTransactionDTO{}
+	err = c.do(ctx, http.MethodPost, "/transactions/prepare", marshalled, &prepared)
+	if err != nil {
+		return nil, err
+	}
+
+	ring := make([]keys.PublicKeyBytes, len(prepared.Ring))
+	for i, member := range prepared.Ring {
+		ring[i], err = query.DecodeKey(member)
+		if err != nil {
+			return nil, fmt.Errorf("ring member %s: %w", member, err)
+		}
+	}
+	return ring, nil
+}
+
+// ChooseRing picks size random members of eligible with own among them, zero size or size
+// over the eligible set picks all of it; members are ordered by key so the position does not reveal own
+func ChooseRing(eligible []keys.PublicKeyBytes, own keys.PublicKeyBytes, size int) ([]keys.PublicKeyBytes, error) {
+	others := make([]keys.PublicKeyBytes, 0, len(eligible))
+	found := false
+	for _, member := range eligible {
+		if member == own {
+			found = true
+			continue
+		}
+		others = append(others, member)
+	}
+	if !found {
+		return nil, ErrNotEligible
+	}
+
+	if size <= 0 || size > len(others)+1 {
+		size = len(others) + 1
+	}
+	// the ring is chosen with crypto/rand since a predictable choice narrows down who voted
+	for i := len(others) - 1; i > 0; i-- {
+		j, err := rand.Int(rand.Reader, big.NewInt(int64(i+1)))
+		if err != nil {
+			return nil, err
+		}
+		others[i], others[j.Int64()] = others[j.Int64()], others[i]
+	}
+
+	ring := append([]keys.PublicKeyBytes{own}, others[:size-1]...)
+	sort.Slice(ring, func(i, j int) bool {
+		return bytes.Compare(ring[i][:], ring[j][:]) < 0
+	})
+	return ring, nil
+}
+
+// VoteAnonymously builds an anonymous vote signed with a ring of ringSize members eligible in the voting
+func (c *Client) VoteAnonymously(ctx context.Context, votingLink [32]byte, answer uint8, ringSize int) (*ts.TxVoteAnonymous, error) {
+	eligible, err := c.EligibleRing(ctx, votingLink)
+	if err != nil {
+		return nil, err
+	}
+	ring, err := ChooseRing(eligible, c.PublicKey(), ringSize)
+	if err != nil {
+		return nil, err
+	}
+
+	vote := NewAnonymousVote(votingLink, answer)
+	err = c.SignAnonymous(vote, ring)
+	if err != nil {
+		return nil, err
+	}
+	return vote, nil
+}
@@ -0,0 +1,274 @@
+package config
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"gopkg.in/yaml.v3"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// EnvPrefix starts names of environment variables of options, e.g. DV_DATA_DIR for -data-dir
+const EnvPrefix = "DV_"
+
+// APIs a node may serve besides the network node every validator runs
+const (
+	RestAPI = "rest"
+	GRPCAPI = "grpc"
+	UserAPI = "user_api"
+)
+
+var knownAPIs = map[string]struct{}{RestAPI: {}, GRPCAPI: {}, UserAPI: {}}
+
+// Config of a node, values are taken from defaults, the YAML file, environment variables and flags,
+// each overriding the previous one
+type Config struct {
+	DataDir string `yaml:"data_dir"`
+	// KeystoreDir defaults to the keystore directory in DataDir
+	KeystoreDir string `yaml:"keystore_dir"`
+	// GenesisFile describes the first block and initial accounts, a chain without one starts with no admins
+	GenesisFile string `yaml:"genesis_file"`
+	// NodeConnector replaces built-in discovery from Seeds when set
+	NodeConnector string   `yaml:"node_connector"`
+	Seeds         []string `yaml:"seeds"`
+	LogLevel      string   `yaml:"log_level"`
+
+	Listen Listen   `yaml:"listen"`
+	APIs   []string `yaml:"apis"`
+	Chain  Chain    `yaml:"chain"`
+}
+
+// Listen holds addresses servers listen on, the node address is also announced to the node connector
+type Listen struct {
+	Node    string `yaml:"node"`
+	Rest    string `yaml:"rest"`
+	GRPC    string `yaml:"grpc"`
+	UserAPI string `yaml:"user_api"`
+}
+
+// Chain holds parameters of the genesis block of a chain started without a genesis file
+type Chain struct {
+	MaxTransactions int           `yaml:"max_transactions"`
+	MaxBlockBytes   int           `yaml:"max_block_bytes"`
+	BlockInterval   time.Duration `yaml:"block_interval"`
+}
+
+func Default() Config {
+	return Config{
+		DataDir:  "data",
+		LogLevel: LevelInfo,
+		Listen: Listen{
+			Node:    "localhost:8081",
+			Rest:    "localhost:8082",
+			GRPC:    "localhost:8083",
+			UserAPI: "localhost:8084",
+		},
+		APIs: []string{RestAPI, GRPCAPI},
+		Chain: Chain{
+			MaxTransactions: 5,
+			MaxBlockBytes:   1 << 20,
+			BlockInterval:   10 * time.Second,
+		},
+	}
+}
+
+// option is a setting that can be set with a flag and an environment variable
+type option struct {
+	name  string
+	usage string
+	get   func(c *Config) string
+	set   func(c *Config, value string) error
+}
+
+func stringOption(name string, usage string, field func(c *Config) *string) option {
+	return option{
+		name:  name,
+		usage: usage,
+		get:   func(c *Config) string { return *field(c) },
+		set: func(c *Config, value string) error {
+			*field(c) = value
+			return nil
+		},
+	}
+}
+
+func intOption(name string, usage string, field func(c *Config) *int) option {
+	return option{
+		name:  name,
+		usage: usage,
+		get:   func(c *Config) string { return strconv.Itoa(*field(c)) },
+		set: func(c *Config, value string) (err error) {
+			*field(c), err = strconv.Atoi(value)
+			return err
+		},
+	}
+}
+
+func listOption(name string, usage string, field func(c *Config) *[]string) option {
+	return option{
+		name:  name,
+		usage: usage,
+		get:   func(c *Config) string { return strings.Join(*field(c), ",") },
+		set: func(c *Config, value string) error {
+			list := []string{}
+			for _, item := range strings.Split(value, ",") {
+				if item = strings.TrimSpace(item); item != "" {
+					list = append(list, item)
+				}
+			}
+			*field(c) = list
+			return nil
+		},
+	}
+}
+
+var options = []option{
+	stringOption("data-dir", "directory the chain and pending transactions are kept in", func(c *Config) *string { return &c.DataDir }),
+	stringOption("keystore-dir", "directory the validator key is kept in, defaults to keystore in -data-dir", func(c *Config) *string { return &c.KeystoreDir }),
+	stringOption("genesis-file", "YAML file with parameters and initial accounts of the chain", func(c *Config) *string { return &c.GenesisFile }),
+	stringOption("node-connector", "address of the node connector, peers are discovered from -seeds without it", func(c *Config) *string { return &c.NodeConnector }),
+	listOption("seeds", "comma separated addresses of network nodes to discover peers from", func(c *Config) *[]string { return &c.Seeds }),
+	stringOption("log-level", "debug, info, error or off", func(c *Config) *string { return &c.LogLevel }),
+	stringOption("listen-node", "address the network node listens on and announces", func(c *Config) *string { return &c.Listen.Node }),
+	stringOption("listen-rest", "address the rest api listens on", func(c *Config) *string { return &c.Listen.Rest }),
+	stringOption("listen-grpc", "address the grpc api listens on", func(c *Config) *string { return &c.Listen.GRPC }),
+	stringOption("listen-user-api", "address the websocket user api listens on", func(c *Config) *string { return &c.Listen.UserAPI }),
+	listOption("apis", "comma separated apis to serve: rest, grpc, user_api", func(c *Config) *[]string { return &c.APIs }),
+	intOption("max-transactions", "maximum number of transactions in a block", func(c *Config) *int { return &c.Chain.MaxTransactions }),
+	intOption("max-block-bytes", "maximum size of an encoded block", func(c *Config) *int { return &c.Chain.MaxBlockBytes }),
+	{
+		name:  "block-interval",
+		usage: "time between blocks",
+		get:   func(c *Config) string { return c.Chain.BlockInterval.String() },
+		set: func(c *Config, value string) (err error) {
+			c.Chain.BlockInterval, err = time.ParseDuration(value)
+			return err
+		},
+	},
+}
+
+// EnvName is the environment variable of the option set by flag name
+func EnvName(name string) string {
+	return EnvPrefix + strings.ToUpper(strings.ReplaceAll(name, "-", "_"))
+}
+
+// Load builds the config of a node from args, getenv and the file named by -config or DV_CONFIG
+func Load(args []string, getenv func(string) string) (Config, error) {
+	defaults := Default()
+	flags := flag.NewFlagSet("node", flag.ContinueOnError)
+	configFile := flags.String("config", getenv(EnvName("config")), "YAML config file, also "+EnvName("config"))
+	values := make(map[string]*string, len(options))
+	for _, opt := range options {
+		values[opt.name] = flags.String(opt.name, opt.get(&defaults), opt.usage+", also "+EnvName(opt.name))
+	}
+	err := flags.Parse(args)
+	if err != nil {
+		return Config{}, err
+	}
+	if flags.NArg() != 0 {
+		return Config{}, fmt.Errorf("unexpected arguments %v", flags.Args())
+	}
+
+	config := Default()
+	if *configFile != "" {
+		config, err = LoadFile(*configFile)
+		if err != nil {
+			return Config{}, err
+		}
+	}
+
+	for _, opt := range options {
+		if value := getenv(EnvName(opt.name)); value != "" {
+			if err = opt.set(&config, value); err != nil {
+				return Config{}, fmt.Errorf("%s: %w", EnvName(opt.name), err)
+			}
+		}
+	}
+	flags.Visit(func(f *flag.Flag) {
+		for _, opt := range options {
+			if err == nil && opt.name == f.Name {
+				if err = opt.set(&config, *values[f.Name]); err != nil {
+					err = fmt.Errorf("-%s: %w", f.Name, err)
+				}
+			}
+		}
+	})
+	if err != nil {
+		return Config{}, err
+	}
+
+	if config.KeystoreDir == "" {
+		config.KeystoreDir = filepath.Join(config.DataDir, "keystore")
+	}
+	return config, config.Validate()
+}
+
+// LoadFile reads a YAML config, options missing from the file keep their defaults
+func LoadFile(path string) (Config, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return Config{}, err
+	}
+	defer file.Close()
+
+	config := Default()
+	decoder := yaml.NewDecoder(file)
+	decoder.KnownFields(true)
+	err = decoder.Decode(&config)
+	if err != nil && !errors.Is(err, io.EOF) {
+		return Config{}, fmt.Errorf("%s: %w", path, err)
+	}
+	return config, nil
+}
+
+// Validate checks the config before any server is started
+func (c Config) Validate() error {
+	if c.DataDir == "" {
+		return errors.New("data dir must be set")
+	}
+	if c.Listen.Node == "" {
+		return errors.New("node address must be set")
+	}
+	if _, exists := logLevels[c.LogLevel]; !exists {
+		return fmt.Errorf("unknown log level %q", c.LogLevel)
+	}
+	for _, api := range c.APIs {
+		if _, exists := knownAPIs[api]; !exists {
+			return fmt.Errorf("unknown api %q", api)
+		}
+		if c.listenAddress(api) == "" {
+			return fmt.Errorf("%s is enabled without a listen address", api)
+		}
+	}
+	if c.Chain.MaxTransactions <= 0 || c.Chain.MaxBlockBytes <= 0 || c.Chain.BlockInterval <= 0 {
+		return errors.New("chain parameters must be positive")
+	}
+	return nil
+}
+
+// Enabled reports whether api is served
+func (c Config) Enabled(api string) bool {
+	for _, enabled := range c.APIs {
+		if enabled == api {
+			return true
+		}
+	}
+	return false
+}
+
+func (c Config) listenAddress(api string) string {
+	switch api {
+	case RestAPI:
+		return c.Listen.Rest
+	case GRPCAPI:
+		return c.Listen.GRPC
+	case UserAPI:
+		return c.Listen.UserAPI
+	}
+	return ""
+}
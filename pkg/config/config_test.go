@@ -0,0 +1,147 @@
+package config
+
+import (
+	"bytes"
+	"github.com/Digital-Voting-Team/Digital-Voting/pkg/logging"
+	"github.com/Digital-Voting-Team/Digital-Voting/pkg/validator/repository/account_manager"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func writeFile(t *testing.T, name string, content string) string {
+	path := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestLoad(t *testing.T) {
+	configFile := writeFile(t, "node.yaml", "data_dir: file\nlog_level: error\nlisten:\n  node: localhost:9001\n  rest: localhost:9002\nchain:\n  block_interval: 2s\n")
+
+	tests := []struct {
+		name    string
+		args    []string
+		env     map[string]string
+		check   func(c Config) bool
+		wantErr bool
+	}{
+		{
+			name:  "Defaults",
+			check: func(c Config) bool { return c.DataDir == "data" && c.KeystoreDir == filepath.Join("data", "keystore") },
+		},
+		{
+			name: "File overrides defaults",
+			args: []string{"-config", configFile},
+			check: func(c Config) bool {
+				return c.DataDir == "file" && c.Listen.Node == "localhost:9001" && c.Listen.GRPC == "localhost:8083" &&
+					c.Chain.BlockInterval == 2*time.Second && c.Chain.MaxTransactions == 5
+			},
+		},
+		{
+			name:  "Environment overrides file",
+			args:  []string{"-config", configFile},
+			env:   map[string]string{"DV_DATA_DIR": "env", "DV_APIS": "grpc, user_api"},
+			check: func(c Config) bool { return c.DataDir == "env" && len(c.APIs) == 2 && !c.Enabled(RestAPI) },
+		},
+		{
+			name: "Flags override environment",
+			args: []string{"-data-dir", "flag", "-listen-node", "localhost:9101"},
+			env:  map[string]string{"DV_CONFIG": configFile, "DV_DATA_DIR": "env"},
+			check: func(c Config) bool {
+				return c.DataDir == "flag" && c.Listen.Node == "localhost:9101" && c.LogLevel == LevelError
+			},
+		},
+		{name: "Unknown api", args: []string{"-apis", "rest,soap"}, wantErr: true},
+		{name: "Unknown log level", env: map[string]string{"DV_LOG_LEVEL": "verbose"}, wantErr: true},
+		{name: "Invalid interval", args: []string{"-block-interval", "soon"}, wantErr: true},
+		{name: "Enabled api without address", args: []string{"-listen-rest", ""}, wantErr: true},
+		{name: "Unknown field in file", args: []string{"-config", writeFile(t, "bad.yaml", "data_directory: x\n")}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Load(tt.args, func(name string) string { return tt.env[name] })
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Load() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err == nil && !tt.check(got) {
+				t.Errorf("Load() = %+v", got)
+			}
+		})
+	}
+}
+
+func TestLoadGenesis(t *testing.T) {
+	publicKey := "02" + strings.Repeat("ab", 32)
+
+	tests := []struct {
+		name         string
+		content      string
+		wantAccounts int
+		wantErr      bool
+	}{
+		{
+			name:         "Accounts with several types",
+			content:      "chain:\n  max_transactions: 100\naccounts:\n  - public_key: " + publicKey + "\n    types: [registration_admin, voting_creation_admin]\n",
+			wantAccounts: 2,
+		},
+		{name: "Unknown type", content: "accounts:\n  - public_key: " + publicKey + "\n    types: [root]\n", wantErr: true},
+		{name: "Short key", content: "accounts:\n  - public_key: 02ab\n    types: [user]\n", wantErr: true},
+		{name: "No types", content: "accounts:\n  - public_key: " + publicKey + "\n", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			genesis, err := LoadGenesis(writeFile(t, "genesis.yaml", tt.content), Default().Chain)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("LoadGenesis() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			accounts, _ := genesis.InitialAccounts()
+			if len(accounts) != tt.wantAccounts || accounts[0].Type != account_manager.RegistrationAdmin {
+				t.Errorf("InitialAccounts() = %v, want %d accounts", accounts, tt.wantAccounts)
+			}
+			genesisBlock, _ := genesis.Block()
+			params := genesisBlock.Header.Params
+			if params.MaxTransactions != 100 || params.BlockInterval != Default().Chain.BlockInterval {
+				t.Errorf("genesis params = %+v, want file values over defaults", params)
+			}
+		})
+	}
+}
+
+func TestSetupLogging(t *testing.T) {
+	defer SetupLogging(LevelInfo, os.Stderr)
+
+	output := &bytes.Buffer{}
+	SetupLogging(LevelError, output)
+	log.Println("Successfully verified block")
+	log.Println("Failed attempts are counted")
+	logging.Errorln("Connection reset")
+
+	if got := output.String(); strings.Contains(got, "verified") || strings.Contains(got, "attempts") || !strings.Contains(got, "ERROR Connection reset") {
+		t.Errorf("error level logged %q", got)
+	}
+}
+
+func TestGenesis_Block(t *testing.T) {
+	withAccount := NewGenesis(Default().Chain)
+	withAccount.Accounts = []GenesisAccount{{PublicKey: "02" + strings.Repeat("ab", 32), Types: []string{"validator"}}}
+
+	empty, _ := NewGenesis(Default().Chain).Block()
+	block, err := withAccount.Block()
+	if err != nil {
+		t.Fatalf("Block() error = %v", err)
+	}
+	if len(block.Header.Accounts) != 1 || block.Header.Accounts[0].Type != account_manager.Validator {
+		t.Errorf("genesis accounts = %v, want the validator", block.Header.Accounts)
+	}
+	if block.GetHash() == empty.GetHash() {
+		t.Errorf("genesis hash does not depend on accounts")
+	}
+}
@@ -0,0 +1,97 @@
+package config
+
+import (
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"github.com/Digital-Voting-Team/Digital-Voting/pkg/blockchain/block"
+	"github.com/Digital-Voting-Team/Digital-Voting/pkg/signature/keys"
+	"github.com/Digital-Voting-Team/Digital-Voting/pkg/validator/repository/account_manager"
+	"gopkg.in/yaml.v3"
+	"os"
+	"strings"
+)
+
+// Genesis describes how a chain starts, its parameters and accounts are recorded in the genesis block,
+// so nodes started with different genesis files do not share a chain, e.g.
+//
+//	chain:
+//	  max_transactions: 100
+//	  block_interval: 5s
+//	accounts:
+//	  - public_key: 02ab...
+//	    types: [registration_admin, voting_creation_admin]
+type Genesis struct {
+	Chain    Chain            `yaml:"chain"`
+	Accounts []GenesisAccount `yaml:"accounts"`
+}
+
+type GenesisAccount struct {
+	PublicKey string   `yaml:"public_key"`
+	Types     []string `yaml:"types"`
+}
+
+// Account is an initial account of a chain with one of its types
+type Account = block.Account
+
+// NewGenesis is the genesis of a chain with chain parameters and no accounts
+func NewGenesis(chain Chain) Genesis {
+	return Genesis{Chain: chain}
+}
+
+// LoadGenesis reads a genesis file, chain parameters missing from the file are taken from chain
+func LoadGenesis(path string, chain Chain) (Genesis, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return Genesis{}, err
+	}
+
+	genesis := NewGenesis(chain)
+	err = yaml.Unmarshal(data, &genesis)
+	if err != nil {
+		return Genesis{}, fmt.Errorf("%s: %w", path, err)
+	}
+	_, err = genesis.InitialAccounts()
+	if err != nil {
+		return Genesis{}, fmt.Errorf("%s: %w", path, err)
+	}
+	return genesis, nil
+}
+
+// Block is the first block of the chain, initial accounts are applied by replaying it
+func (g Genesis) Block() (*block.Block, error) {
+	accounts, err := g.InitialAccounts()
+	if err != nil {
+		return nil, err
+	}
+	return block.NewGenesisBlock(block.ChainParams{
+		MaxTransactions: g.Chain.MaxTransactions,
+		MaxBlockBytes:   g.Chain.MaxBlockBytes,
+		BlockInterval:   g.Chain.BlockInterval,
+	}, accounts), nil
+}
+
+// InitialAccounts decodes accounts the chain starts with, an account with several types is listed once per type
+func (g Genesis) InitialAccounts() ([]Account, error) {
+	accounts := []Account{}
+	for _, genesisAccount := range g.Accounts {
+		publicKey := keys.PublicKeyBytes{}
+		decoded, err := hex.DecodeString(strings.TrimPrefix(genesisAccount.PublicKey, "0x"))
+		if err != nil || len(decoded) != len(publicKey) {
+			return nil, fmt.Errorf("public key %q must be %d hex encoded bytes", genesisAccount.PublicKey, len(publicKey))
+		}
+		copy(publicKey[:], decoded)
+		if len(genesisAccount.Types) == 0 {
+			return nil, fmt.Errorf("account %s has no types", genesisAccount.PublicKey)
+		}
+
+		for _, name := range genesisAccount.Types {
+			accountType, exists := account_manager.ParseIdentifier(name)
+			if !exists || accountType == account_manager.GroupIdentifier {
+				return nil, errors.New("unknown account type " + name)
+			}
+			accounts = append(accounts, Account{PublicKey: publicKey, Type: accountType})
+		}
+	}
+	return accounts, nil
+}
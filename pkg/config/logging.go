@@ -0,0 +1,31 @@
+package config
+
+import (
+	"github.com/Digital-Voting-Team/Digital-Voting/pkg/logging"
+	"io"
+)
+
+// Log levels of the node: error keeps lines logged with logging.Errorf and logging.Errorln,
+// info adds lines of the standard logger and debug adds file and line of the call
+const (
+	LevelDebug = "debug"
+	LevelInfo  = "info"
+	LevelError = "error"
+	LevelOff   = "off"
+)
+
+var logLevels = map[string]logging.Level{
+	LevelDebug: logging.Debug,
+	LevelInfo:  logging.Info,
+	LevelError: logging.Error,
+	LevelOff:   logging.Off,
+}
+
+// SetupLogging configures the standard logger and the error logger for level, writing to w
+func SetupLogging(level string, w io.Writer) {
+	logLevel, exists := logLevels[level]
+	if !exists {
+		logLevel = logging.Info
+	}
+	logging.Setup(logLevel, w)
+}
@@ -0,0 +1,58 @@
+package consensus
+
+import (
+	"errors"
+	blk "github.com/Digital-Voting-Team/Digital-Voting/pkg/blockchain/block"
+	"github.com/Digital-Voting-Team/Digital-Voting/pkg/consensus/evidence"
+	"github.com/Digital-Voting-Team/Digital-Voting/pkg/signature/keys"
+	ms "github.com/Digital-Voting-Team/Digital-Voting/pkg/signature/signatures/multi_signature"
+	ss "github.com/Digital-Voting-Team/Digital-Voting/pkg/signature/signatures/single_signature"
+)
+
+var (
+	ErrNotRunning        = errors.New("consensus engine is not running")
+	ErrEmptyValidatorSet = errors.New("validator set is empty")
+)
+
+// Engine agrees with the other validators on the next block of the chain
+type Engine interface {
+	// Start begins taking part in consensus
+	Start()
+	// Stop stops the engine, blocks submitted and not yet decided are restored
+	Stop()
+	// Submit hands a block created by this validator to the engine and returns
+	// once the block was either committed or rejected
+	Submit(block *blk.Block)
+	// HandleMessage processes a consensus message received from a peer
+	HandleMessage(message *Message)
+	// Proposer returns the proposer of the height and round the engine is in
+	Proposer() (keys.PublicKeyBytes, error)
+}
+
+// Backend is the part of the validator the engine relies on
+type Backend interface {
+	// Height returns the height of the next block to be committed
+	Height() uint64
+	LastBlockHash() [32]byte
+	// ValidatorSet returns public keys of the current validators
+	ValidatorSet() []keys.PublicKeyBytes
+	PublicKey() keys.PublicKeyBytes
+	Sign(message string) ss.SingleSignatureBytes
+	// CreateNonce starts a signing session of the block witness
+	CreateNonce(session string) (ms.NonceBytes, error)
+	// SignPartial signs the block witness with the nonce of the session, the nonce can't be used again
+	SignPartial(session string, block *blk.Block, signers []keys.PublicKeyBytes, nonces []ms.NonceBytes) (ms.PartialSignatureBytes, error)
+	// VerifyProposal verifies a proposed block, it has no witness yet, the error tells why it is invalid
+	VerifyProposal(block *blk.Block) error
+	// CommitBlock adds a decided block to the chain
+	CommitBlock(block *blk.Block) error
+	// RestoreBlock returns transactions of a block that was not committed to the MemPool
+	RestoreBlock(block *blk.Block)
+	// ReportEvidence submits evidence of a validator that signed two different blocks in the same round
+	ReportEvidence(evidence *evidence.Evidence)
+}
+
+// Broadcaster delivers consensus messages to all other validators
+type Broadcaster interface {
+	Broadcast(message *Message)
+}
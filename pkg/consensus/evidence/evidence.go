@@ -0,0 +1,187 @@
+package evidence
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/base64"
+	"fmt"
+	"github.com/Digital-Voting-Team/Digital-Voting/pkg/rejection"
+	"github.com/Digital-Voting-Team/Digital-Voting/pkg/signature/keys"
+	ss "github.com/Digital-Voting-Team/Digital-Voting/pkg/signature/signatures/single_signature"
+)
+
+type VoteType uint8
+
+const (
+	Prevote VoteType = iota
+	Precommit
+)
+
+func (vt VoteType) String() string {
+	switch vt {
+	case Prevote:
+		return "Prevote"
+	case Precommit:
+		return "Precommit"
+	default:
+		return fmt.Sprintf("%d", int(vt))
+	}
+}
+
+// Vote is a prevote or a precommit for a block, zero BlockHash stands for a vote for nil.
+// It lives here and not in consensus so transactions can carry votes as evidence
+type Vote struct {
+	VoteType  VoteType                `json:"vote_type"`
+	Height    uint64                  `json:"height"`
+	Round     uint32                  `json:"round"`
+	BlockHash [32]byte                `json:"block_hash"`
+	Validator keys.PublicKeyBytes     `json:"validator"`
+	Signature ss.SingleSignatureBytes `json:"signature"`
+}
+
+func (v *Vote) IsNil() bool {
+	return v.BlockHash == [32]byte{}
+}
+
+func (v *Vote) GetSignatureMessage() string {
+	return HashMessage(fmt.Sprint("Vote ", v.VoteType, v.Height, v.Round, v.BlockHash, v.Validator))
+}
+
+func (v *Vote) VerifySignature() bool {
+	return ss.NewECDSA().VerifyEdDSABytes(v.GetSignatureMessage(), v.Validator, v.Signature)
+}
+
+// ProposalHeader is the signed part of a proposal, the block itself is represented by its hash
+type ProposalHeader struct {
+	Height     uint64                  `json:"height"`
+	Round      uint32                  `json:"round"`
+	ValidRound int32                   `json:"valid_round"`
+	BlockHash  [32]byte                `json:"block_hash"`
+	Proposer   keys.PublicKeyBytes     `json:"proposer"`
+	Signature  ss.SingleSignatureBytes `json:"signature"`
+}
+
+func (ph *ProposalHeader) GetSignatureMessage() string {
+	return HashMessage(fmt.Sprint("Proposal ", ph.Height, ph.Round, ph.ValidRound, ph.BlockHash, ph.Proposer))
+}
+
+func (ph *ProposalHeader) VerifySignature() bool {
+	return ss.NewECDSA().VerifyEdDSABytes(ph.GetSignatureMessage(), ph.Proposer, ph.Signature)
+}
+
+// HashMessage is the message signed for a consensus message with the given concatenation
+func HashMessage(message string) string {
+	hasher := sha256.New()
+
+	hasher.Write([]byte(message))
+	bytes := hasher.Sum(nil)
+
+	hasher.Reset()
+	hasher.Write(bytes)
+
+	return base64.URLEncoding.EncodeToString(hasher.Sum(nil))
+}
+
+// Evidence proves that a validator signed two different blocks in the same round:
+// either two votes of the same type or two proposals. Statements are ordered by block hash,
+// so the same equivocation always makes the same evidence
+type Evidence struct {
+	Votes     []Vote           `json:"votes,omitempty"`
+	Proposals []ProposalHeader `json:"proposals,omitempty"`
+}
+
+// NewVoteEvidence makes evidence of two conflicting votes
+func NewVoteEvidence(first, second Vote) *Evidence {
+	if bytes.Compare(first.BlockHash[:], second.BlockHash[:]) > 0 {
+		first, second = second, first
+	}
+	return &Evidence{Votes: []Vote{first, second}}
+}
+
+// NewProposalEvidence makes evidence of two conflicting proposals
+func NewProposalEvidence(first, second ProposalHeader) *Evidence {
+	if bytes.Compare(first.BlockHash[:], second.BlockHash[:]) > 0 {
+		first, second = second, first
+	}
+	return &Evidence{Proposals: []ProposalHeader{first, second}}
+}
+
+func (e *Evidence) IsVote() bool {
+	return len(e.Votes) != 0
+}
+
+// Offender returns the key of the validator that signed both statements
+func (e *Evidence) Offender() keys.PublicKeyBytes {
+	if e.IsVote() {
+		return e.Votes[0].Validator
+	}
+	if len(e.Proposals) != 0 {
+		return e.Proposals[0].Proposer
+	}
+	return keys.PublicKeyBytes{}
+}
+
+// Position returns the height and the round statements were signed for
+func (e *Evidence) Position() (uint64, uint32) {
+	if e.IsVote() {
+		return e.Votes[0].Height, e.Votes[0].Round
+	}
+	if len(e.Proposals) != 0 {
+		return e.Proposals[0].Height, e.Proposals[0].Round
+	}
+	return 0, 0
+}
+
+// BlockHashes returns hashes of the two blocks the offender signed
+func (e *Evidence) BlockHashes() [2][32]byte {
+	if e.IsVote() {
+		return [2][32]byte{e.Votes[0].BlockHash, e.Votes[1].BlockHash}
+	}
+	if len(e.Proposals) == 2 {
+		return [2][32]byte{e.Proposals[0].BlockHash, e.Proposals[1].BlockHash}
+	}
+	return [2][32]byte{}
+}
+
+// Verify checks that statements conflict and both are signed by the offender
+func (e *Evidence) Verify() error {
+	switch {
+	case len(e.Votes) == 2 && len(e.Proposals) == 0:
+		first, second := e.Votes[0], e.Votes[1]
+		if first.Validator != second.Validator || first.VoteType != second.VoteType ||
+			first.Height != second.Height || first.Round != second.Round {
+			return rejection.New(rejection.InvalidEvidence, "votes are not of the same validator, type, height and round")
+		}
+		err := checkOrder(first.BlockHash, second.BlockHash)
+		if err != nil {
+			return err
+		}
+		if !first.VerifySignature() || !second.VerifySignature() {
+			return rejection.New(rejection.InvalidEvidence, "vote is not signed by the validator")
+		}
+		return nil
+	case len(e.Proposals) == 2 && len(e.Votes) == 0:
+		first, second := e.Proposals[0], e.Proposals[1]
+		if first.Proposer != second.Proposer || first.Height != second.Height || first.Round != second.Round {
+			return rejection.New(rejection.InvalidEvidence, "proposals are not of the same proposer, height and round")
+		}
+		err := checkOrder(first.BlockHash, second.BlockHash)
+		if err != nil {
+			return err
+		}
+		if !first.VerifySignature() || !second.VerifySignature() {
+			return rejection.New(rejection.InvalidEvidence, "proposal is not signed by the proposer")
+		}
+		return nil
+	default:
+		return rejection.New(rejection.InvalidEvidence, "evidence needs either two votes or two proposals")
+	}
+}
+
+// checkOrder rejects statements for the same block and the ones out of order, so evidence has one form only
+func checkOrder(first, second [32]byte) error {
+	if bytes.Compare(first[:], second[:]) >= 0 {
+		return rejection.New(rejection.InvalidEvidence, "statements are for the same block or not ordered by block hash")
+	}
+	return nil
+}
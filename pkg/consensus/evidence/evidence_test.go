@@ -0,0 +1,108 @@
+package evidence
+
+import (
+	"github.com/Digital-Voting-Team/Digital-Voting/pkg/rejection"
+	"github.com/Digital-Voting-Team/Digital-Voting/pkg/signature/curve"
+	"github.com/Digital-Voting-Team/Digital-Voting/pkg/signature/keys"
+	ss "github.com/Digital-Voting-Team/Digital-Voting/pkg/signature/signatures/single_signature"
+	"testing"
+)
+
+func sign(keyPair *keys.KeyPair, message string) ss.SingleSignatureBytes {
+	ecdsa := ss.NewECDSA()
+	signature := ecdsa.SignEdDSA(message, keyPair.GetPrivateKey(), keyPair.GetPublicKey())
+	return ecdsa.EdwardsToSingleSignature(signature).EdwardsSignatureToBytes()
+}
+
+func signedVote(keyPair *keys.KeyPair, round uint32, blockHash [32]byte) Vote {
+	vote := Vote{VoteType: Prevote, Height: 3, Round: round, BlockHash: blockHash, Validator: keyPair.PublicToBytes()}
+	vote.Signature = sign(keyPair, vote.GetSignatureMessage())
+	return vote
+}
+
+func signedProposal(keyPair *keys.KeyPair, blockHash [32]byte) ProposalHeader {
+	header := ProposalHeader{Height: 3, Round: 1, ValidRound: -1, BlockHash: blockHash, Proposer: keyPair.PublicToBytes()}
+	header.Signature = sign(keyPair, header.GetSignatureMessage())
+	return header
+}
+
+func TestEvidence_Verify(t *testing.T) {
+	offender, _ := keys.Random(curve.NewCurve25519())
+	other, _ := keys.Random(curve.NewCurve25519())
+
+	forged := signedVote(offender, 1, [32]byte{2})
+	forged.Signature = sign(other, forged.GetSignatureMessage())
+	swapped := NewVoteEvidence(signedVote(offender, 1, [32]byte{1}), signedVote(offender, 1, [32]byte{2}))
+	swapped.Votes[0], swapped.Votes[1] = swapped.Votes[1], swapped.Votes[0]
+
+	tests := []struct {
+		name     string
+		evidence *Evidence
+		wantErr  bool
+	}{
+		{
+			name:     "Two votes for different blocks",
+			evidence: NewVoteEvidence(signedVote(offender, 1, [32]byte{2}), signedVote(offender, 1, [32]byte{1})),
+			wantErr:  false,
+		},
+		{
+			name:     "Vote for a block and a vote for nil",
+			evidence: NewVoteEvidence(signedVote(offender, 1, [32]byte{}), signedVote(offender, 1, [32]byte{1})),
+			wantErr:  false,
+		},
+		{
+			name:     "Votes for the same block",
+			evidence: NewVoteEvidence(signedVote(offender, 1, [32]byte{1}), signedVote(offender, 1, [32]byte{1})),
+			wantErr:  true,
+		},
+		{
+			name:     "Votes of different rounds",
+			evidence: NewVoteEvidence(signedVote(offender, 1, [32]byte{1}), signedVote(offender, 2, [32]byte{2})),
+			wantErr:  true,
+		},
+		{
+			name:     "Votes of different validators",
+			evidence: NewVoteEvidence(signedVote(offender, 1, [32]byte{1}), signedVote(other, 1, [32]byte{2})),
+			wantErr:  true,
+		},
+		{
+			name:     "Vote signed by another key",
+			evidence: NewVoteEvidence(signedVote(offender, 1, [32]byte{1}), forged),
+			wantErr:  true,
+		},
+		{
+			name:     "Votes out of order",
+			evidence: swapped,
+			wantErr:  true,
+		},
+		{
+			name:     "Two proposals of different blocks",
+			evidence: NewProposalEvidence(signedProposal(offender, [32]byte{1}), signedProposal(offender, [32]byte{2})),
+			wantErr:  false,
+		},
+		{
+			name:     "Proposals of different proposers",
+			evidence: NewProposalEvidence(signedProposal(offender, [32]byte{1}), signedProposal(other, [32]byte{2})),
+			wantErr:  true,
+		},
+		{
+			name:     "Empty evidence",
+			evidence: &Evidence{},
+			wantErr:  true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.evidence.Verify()
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Verify() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil && rejection.CodeOf(err) != rejection.InvalidEvidence {
+				t.Errorf("Verify() code = %v, want %v", rejection.CodeOf(err), rejection.InvalidEvidence)
+			}
+			if err == nil && tt.evidence.Offender() != offender.PublicToBytes() {
+				t.Errorf("Offender() = %x, want %x", tt.evidence.Offender(), offender.PublicToBytes())
+			}
+		})
+	}
+}
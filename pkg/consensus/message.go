@@ -0,0 +1,170 @@
+package consensus
+
+import (
+	"encoding/json"
+	"fmt"
+	blk "github.com/Digital-Voting-Team/Digital-Voting/pkg/blockchain/block"
+	"github.com/Digital-Voting-Team/Digital-Voting/pkg/consensus/evidence"
+	"github.com/Digital-Voting-Team/Digital-Voting/pkg/signature/keys"
+	ms "github.com/Digital-Voting-Team/Digital-Voting/pkg/signature/signatures/multi_signature"
+	ss "github.com/Digital-Voting-Team/Digital-Voting/pkg/signature/signatures/single_signature"
+)
+
+type MessageType uint8
+
+const (
+	ProposalMessage MessageType = iota
+	VoteMessage
+	NonceMessage
+	FinalizeMessage
+	PartialMessage
+)
+
+func (mt MessageType) String() string {
+	switch mt {
+	case ProposalMessage:
+		return "Proposal"
+	case VoteMessage:
+		return "Vote"
+	case NonceMessage:
+		return "Nonce"
+	case FinalizeMessage:
+		return "Finalize"
+	case PartialMessage:
+		return "Partial"
+	default:
+		return fmt.Sprintf("%d", int(mt))
+	}
+}
+
+// VoteType and Vote are defined in evidence, since transactions carry votes as evidence of equivocation
+type VoteType = evidence.VoteType
+
+const (
+	Prevote   = evidence.Prevote
+	Precommit = evidence.Precommit
+)
+
+type Vote = evidence.Vote
+
+type Message struct {
+	MessageType MessageType `json:"message_type"`
+	Proposal    *Proposal   `json:"proposal,omitempty"`
+	Vote        *Vote       `json:"vote,omitempty"`
+	Nonce       *Nonce      `json:"nonce,omitempty"`
+	Finalize    *Finalize   `json:"finalize,omitempty"`
+	Partial     *Partial    `json:"partial,omitempty"`
+}
+
+// Proposal is a block proposed by the proposer of the round,
+// ValidRound is the round the block got a prevote quorum in or -1
+type Proposal struct {
+	Height     uint64                  `json:"height"`
+	Round      uint32                  `json:"round"`
+	ValidRound int32                   `json:"valid_round"`
+	Block      *blk.Block              `json:"block"`
+	Proposer   keys.PublicKeyBytes     `json:"proposer"`
+	Signature  ss.SingleSignatureBytes `json:"signature"`
+}
+
+// Header returns the signed part of the proposal
+func (p *Proposal) Header() evidence.ProposalHeader {
+	return evidence.ProposalHeader{
+		Height:     p.Height,
+		Round:      p.Round,
+		ValidRound: p.ValidRound,
+		BlockHash:  p.Block.GetHash(),
+		Proposer:   p.Proposer,
+		Signature:  p.Signature,
+	}
+}
+
+func (p *Proposal) GetSignatureMessage() string {
+	header := p.Header()
+	return header.GetSignatureMessage()
+}
+
+func (p *Proposal) VerifySignature() bool {
+	if p.Block == nil {
+		return false
+	}
+	header := p.Header()
+	return header.VerifySignature()
+}
+
+// UnmarshalJSON is needed since transactions of the block can be unmarshalled only through blk.UnmarshallBlock
+func (p *Proposal) UnmarshalJSON(data []byte) error {
+	type proposal Proposal
+	temp := &struct {
+		*proposal
+		Block json.RawMessage `json:"block"`
+	}{proposal: (*proposal)(p)}
+
+	err := json.Unmarshal(data, temp)
+	if err != nil {
+		return err
+	}
+
+	p.Block, err = blk.UnmarshallBlock(temp.Block)
+	return err
+}
+
+// Nonce opens signing of the witness of the decided block, validators send a fresh one on every attempt
+type Nonce struct {
+	Height    uint64                  `json:"height"`
+	Attempt   uint32                  `json:"attempt"`
+	BlockHash [32]byte                `json:"block_hash"`
+	Validator keys.PublicKeyBytes     `json:"validator"`
+	Nonce     ms.NonceBytes           `json:"nonce"`
+	Signature ss.SingleSignatureBytes `json:"signature"`
+}
+
+func (n *Nonce) GetSignatureMessage() string {
+	return hashMessage(fmt.Sprint(NonceMessage, n.Height, n.Attempt, n.BlockHash, n.Validator, n.Nonce))
+}
+
+func (n *Nonce) VerifySignature() bool {
+	return ss.NewECDSA().VerifyEdDSABytes(n.GetSignatureMessage(), n.Validator, n.Signature)
+}
+
+// Finalize fixes the signers of the attempt and their nonces, it is sent by the aggregator of the attempt.
+// Signers are sorted the way the witness lists them
+type Finalize struct {
+	Height     uint64                  `json:"height"`
+	Attempt    uint32                  `json:"attempt"`
+	BlockHash  [32]byte                `json:"block_hash"`
+	Aggregator keys.PublicKeyBytes     `json:"aggregator"`
+	Signers    []keys.PublicKeyBytes   `json:"signers"`
+	Nonces     []ms.NonceBytes         `json:"nonces"`
+	Signature  ss.SingleSignatureBytes `json:"signature"`
+}
+
+func (f *Finalize) GetSignatureMessage() string {
+	return hashMessage(fmt.Sprint(FinalizeMessage, f.Height, f.Attempt, f.BlockHash, f.Aggregator, f.Signers, f.Nonces))
+}
+
+func (f *Finalize) VerifySignature() bool {
+	return ss.NewECDSA().VerifyEdDSABytes(f.GetSignatureMessage(), f.Aggregator, f.Signature)
+}
+
+// Partial is the share of a signer listed in Finalize of the attempt
+type Partial struct {
+	Height    uint64                   `json:"height"`
+	Attempt   uint32                   `json:"attempt"`
+	BlockHash [32]byte                 `json:"block_hash"`
+	Validator keys.PublicKeyBytes      `json:"validator"`
+	Partial   ms.PartialSignatureBytes `json:"partial"`
+	Signature ss.SingleSignatureBytes  `json:"signature"`
+}
+
+func (p *Partial) GetSignatureMessage() string {
+	return hashMessage(fmt.Sprint(PartialMessage, p.Height, p.Attempt, p.BlockHash, p.Validator, p.Partial))
+}
+
+func (p *Partial) VerifySignature() bool {
+	return ss.NewECDSA().VerifyEdDSABytes(p.GetSignatureMessage(), p.Validator, p.Signature)
+}
+
+func hashMessage(message string) string {
+	return evidence.HashMessage(message)
+}
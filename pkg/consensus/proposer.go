@@ -0,0 +1,40 @@
+package consensus
+
+import (
+	blk "github.com/Digital-Voting-Team/Digital-Voting/pkg/blockchain/block"
+	"github.com/Digital-Voting-Team/Digital-Voting/pkg/signature/keys"
+)
+
+// SortValidators returns a copy of the validator set in a deterministic order
+func SortValidators(validators []keys.PublicKeyBytes) []keys.PublicKeyBytes {
+	return keys.SortPublicKeys(validators)
+}
+
+// Proposer selects the proposer of the given height and round in round-robin fashion,
+// validators are expected to be sorted with SortValidators
+func Proposer(validators []keys.PublicKeyBytes, height uint64, round uint32) keys.PublicKeyBytes {
+	if len(validators) == 0 {
+		return keys.PublicKeyBytes{}
+	}
+	return validators[(height+uint64(round))%uint64(len(validators))]
+}
+
+// Quorum is the number of validators needed to commit a block, witnesses are checked against the same number
+func Quorum(validatorsNumber int) int {
+	return blk.Quorum(validatorsNumber)
+}
+
+// SkipThreshold is the number of validators in a higher round that makes a validator catch up,
+// at least one of them is honest
+func SkipThreshold(validatorsNumber int) int {
+	return validatorsNumber - Quorum(validatorsNumber) + 1
+}
+
+func isValidator(validators []keys.PublicKeyBytes, publicKey keys.PublicKeyBytes) bool {
+	for _, key := range validators {
+		if key == publicKey {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,103 @@
+package consensus
+
+import (
+	"errors"
+	blk "github.com/Digital-Voting-Team/Digital-Voting/pkg/blockchain/block"
+	"github.com/Digital-Voting-Team/Digital-Voting/pkg/logging"
+	"github.com/Digital-Voting-Team/Digital-Voting/pkg/signature/keys"
+	ms "github.com/Digital-Voting-Team/Digital-Voting/pkg/signature/signatures/multi_signature"
+	"github.com/Digital-Voting-Team/Digital-Voting/pkg/signer"
+	"log"
+	"sync"
+)
+
+var ErrNotSoleValidator = errors.New("simple consensus needs this validator to be the only validator")
+
+// SimpleConsensus is the one-shot flow: a submitted block is verified, its witness is signed and
+// it is committed at once. It has no rounds and no messages, so it works only while this validator
+// is the whole validator set. It is meant for tests and trusted setups and is never used by cmd/main
+type SimpleConsensus struct {
+	backend     Backend
+	blockSigner *signer.BlockSigner
+
+	mutex   sync.Mutex
+	running bool
+}
+
+func NewSimpleConsensus(backend Backend) *SimpleConsensus {
+	return &SimpleConsensus{
+		backend:     backend,
+		blockSigner: signer.NewBlockSigner(),
+	}
+}
+
+func (sc *SimpleConsensus) Start() {
+	sc.mutex.Lock()
+	defer sc.mutex.Unlock()
+	sc.running = true
+}
+
+func (sc *SimpleConsensus) Stop() {
+	sc.mutex.Lock()
+	defer sc.mutex.Unlock()
+	sc.running = false
+}
+
+// Submit commits the block with the witness of this validator, the block is restored if it can't be committed
+func (sc *SimpleConsensus) Submit(block *blk.Block) {
+	sc.mutex.Lock()
+	defer sc.mutex.Unlock()
+
+	err := sc.commit(block)
+	if err != nil {
+		logging.Errorf("Block with hash %s was not committed: %v", block.GetHashString(), err)
+		sc.backend.RestoreBlock(block)
+		return
+	}
+	log.Printf("Committed block with hash %s", block.GetHashString())
+}
+
+func (sc *SimpleConsensus) commit(block *blk.Block) error {
+	if !sc.running {
+		return ErrNotRunning
+	}
+	validators := sc.backend.ValidatorSet()
+	if len(validators) != 1 || validators[0] != sc.backend.PublicKey() {
+		return ErrNotSoleValidator
+	}
+
+	err := sc.backend.VerifyProposal(block)
+	if err != nil {
+		return err
+	}
+
+	session := block.GetHashString()
+	nonce, err := sc.backend.CreateNonce(session)
+	if err != nil {
+		return err
+	}
+	partial, err := sc.backend.SignPartial(session, block, validators, []ms.NonceBytes{nonce})
+	if err != nil {
+		return err
+	}
+	err = sc.blockSigner.AggregateWitness(block, validators, validators, []ms.NonceBytes{nonce}, []ms.PartialSignatureBytes{partial})
+	if err != nil {
+		return err
+	}
+
+	return sc.backend.CommitBlock(block)
+}
+
+func (sc *SimpleConsensus) HandleMessage(message *Message) {
+	log.Printf("Consensus message of type %s ignored in simple mode", message.MessageType)
+}
+
+// Proposer is always this validator, it is the only one
+func (sc *SimpleConsensus) Proposer() (keys.PublicKeyBytes, error) {
+	sc.mutex.Lock()
+	defer sc.mutex.Unlock()
+	if !sc.running {
+		return keys.PublicKeyBytes{}, ErrNotRunning
+	}
+	return sc.backend.PublicKey(), nil
+}
@@ -0,0 +1,65 @@
+package consensus
+
+import (
+	ms "github.com/Digital-Voting-Team/Digital-Voting/pkg/signature/signatures/multi_signature"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+func TestSimpleConsensus_Submit(t *testing.T) {
+	tests := []struct {
+		name       string
+		validators int
+		start      bool
+		committed  bool
+	}{
+		{
+			name:       "Only validator commits the block",
+			validators: 1,
+			start:      true,
+			committed:  true,
+		},
+		{
+			name:       "Block is restored when there are other validators",
+			validators: 2,
+			start:      true,
+			committed:  false,
+		},
+		{
+			name:       "Block is restored when the engine is not running",
+			validators: 1,
+			start:      false,
+			committed:  false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			backends, sorted := newTestValidators(t, tt.validators)
+			backend := backends[0]
+			engine := NewSimpleConsensus(backend)
+			if tt.start {
+				engine.Start()
+				defer engine.Stop()
+			}
+
+			block := newTestBlock(backend.LastBlockHash())
+			engine.Submit(block)
+
+			if !tt.committed {
+				require.Len(t, backend.chain, 1)
+				require.Len(t, backend.restored, 1)
+				return
+			}
+			require.Len(t, backend.chain, 2)
+			committed := <-backend.committed
+			require.Equal(t, block.GetHash(), committed.GetHash())
+			signers, err := committed.Witness.GetSigners(sorted)
+			require.NoError(t, err)
+			require.True(t, ms.NewMuSig().VerifyBytes(committed.GetHashString(), signers, committed.Witness.Signature))
+
+			proposer, err := engine.Proposer()
+			require.NoError(t, err)
+			require.Equal(t, backend.PublicKey(), proposer)
+		})
+	}
+}
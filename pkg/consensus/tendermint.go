@@ -0,0 +1,712 @@
+package consensus
+
+import (
+	"fmt"
+	blk "github.com/Digital-Voting-Team/Digital-Voting/pkg/blockchain/block"
+	"github.com/Digital-Voting-Team/Digital-Voting/pkg/consensus/evidence"
+	"github.com/Digital-Voting-Team/Digital-Voting/pkg/logging"
+	"github.com/Digital-Voting-Team/Digital-Voting/pkg/signature/keys"
+	"github.com/Digital-Voting-Team/Digital-Voting/pkg/signer"
+	"log"
+	"sync"
+	"time"
+)
+
+const maxFutureMessages = 1024
+
+// maxAttemptsAhead bounds how many signing attempts ahead of the current one messages are kept for
+const maxAttemptsAhead = 8
+
+// maxRoundsAhead bounds how many rounds ahead of the current one proposals and votes are kept for
+const maxRoundsAhead = 8
+
+type Step uint8
+
+const (
+	StepPropose Step = iota
+	StepPrevote
+	StepPrecommit
+	// StepCommit is the step after the block is decided, validators aggregate its witness
+	StepCommit
+)
+
+func (s Step) String() string {
+	switch s {
+	case StepPropose:
+		return "Propose"
+	case StepPrevote:
+		return "Prevote"
+	case StepPrecommit:
+		return "Precommit"
+	case StepCommit:
+		return "Commit"
+	default:
+		return fmt.Sprintf("%d", int(s))
+	}
+}
+
+// Timeouts of each step, every next round waits longer by the corresponding delta
+type Timeouts struct {
+	Propose        time.Duration
+	ProposeDelta   time.Duration
+	Prevote        time.Duration
+	PrevoteDelta   time.Duration
+	Precommit      time.Duration
+	PrecommitDelta time.Duration
+	Finalize       time.Duration
+	FinalizeDelta  time.Duration
+}
+
+func DefaultTimeouts() Timeouts {
+	return Timeouts{
+		Propose:        3 * time.Second,
+		ProposeDelta:   500 * time.Millisecond,
+		Prevote:        time.Second,
+		PrevoteDelta:   500 * time.Millisecond,
+		Precommit:      time.Second,
+		PrecommitDelta: 500 * time.Millisecond,
+		Finalize:       2 * time.Second,
+		FinalizeDelta:  500 * time.Millisecond,
+	}
+}
+
+func (t Timeouts) forStep(step Step, round uint32) time.Duration {
+	switch step {
+	case StepPropose:
+		return t.Propose + t.ProposeDelta*time.Duration(round)
+	case StepPrevote:
+		return t.Prevote + t.PrevoteDelta*time.Duration(round)
+	case StepCommit:
+		return t.Finalize + t.FinalizeDelta*time.Duration(round)
+	default:
+		return t.Precommit + t.PrecommitDelta*time.Duration(round)
+	}
+}
+
+// timeoutInfo of StepCommit holds the signing attempt instead of the round
+type timeoutInfo struct {
+	height uint64
+	round  uint32
+	step   Step
+}
+
+type position struct {
+	running    bool
+	height     uint64
+	round      uint32
+	validators []keys.PublicKeyBytes
+}
+
+type submission struct {
+	block *blk.Block
+	done  chan struct{}
+}
+
+type queuedMessage struct {
+	message *Message
+	own     bool
+}
+
+// Tendermint is a propose/prevote/precommit BFT engine with round-robin proposers,
+// round changes on timeouts and locking on blocks that got a prevote quorum.
+// https://arxiv.org/abs/1807.04938
+//
+// Once a block is decided validators aggregate its witness: each sends a nonce, the aggregator
+// of the attempt fixes a quorum of signers in Finalize and the signers send partial signatures.
+// An attempt that times out is repeated with fresh nonces and the next aggregator.
+type Tendermint struct {
+	backend     Backend
+	broadcaster Broadcaster
+	timeouts    Timeouts
+	blockSigner *signer.BlockSigner
+
+	messages    chan *Message
+	submissions chan submission
+	timeoutsC   chan timeoutInfo
+	quit        chan struct{}
+	stopped     chan struct{}
+	startOnce   sync.Once
+	stopOnce    sync.Once
+
+	// position is the height, round and validators of the run loop, it is read by Proposer
+	position      position
+	positionMutex sync.Mutex
+
+	// Everything below is owned by the run loop
+	height     uint64
+	round      uint32
+	step       Step
+	validators []keys.PublicKeyBytes
+	publicKey  keys.PublicKeyBytes
+	active     bool
+	candidate  *submission
+
+	proposals    map[uint32]*Proposal
+	blocks       map[[32]byte]*blk.Block
+	validity     map[[32]byte]bool
+	prevotes     map[uint32]*voteSet
+	precommits   map[uint32]*voteSet
+	roundSenders map[uint32]map[keys.PublicKeyBytes]struct{}
+	reported     map[keys.PublicKeyBytes]struct{}
+
+	lockedRound int32
+	lockedHash  [32]byte
+	validRound  int32
+	validBlock  *blk.Block
+
+	proposalSent              bool
+	lockHandled               bool
+	prevoteTimeoutScheduled   bool
+	precommitTimeoutScheduled bool
+
+	decided     *blk.Block
+	attempt     uint32
+	nonces      map[uint32]map[keys.PublicKeyBytes]*Nonce
+	finalizes   map[uint32]*Finalize
+	partials    map[uint32]map[keys.PublicKeyBytes]*Partial
+	partialSent map[uint32]bool
+	aggregated  map[uint32]bool
+
+	// pendingFinalizes wait for nonces of their signers, Finalize is accepted only with the nonces the signers sent
+	pendingFinalizes map[uint32]*Finalize
+
+	queue  []queuedMessage
+	future []*Message
+}
+
+func NewTendermint(backend Backend, broadcaster Broadcaster, timeouts Timeouts) *Tendermint {
+	return &Tendermint{
+		backend:     backend,
+		broadcaster: broadcaster,
+		timeouts:    timeouts,
+		blockSigner: signer.NewBlockSigner(),
+
+		messages:    make(chan *Message, 256),
+		submissions: make(chan submission),
+		timeoutsC:   make(chan timeoutInfo, 16),
+		quit:        make(chan struct{}),
+		stopped:     make(chan struct{}),
+	}
+}
+
+func (t *Tendermint) Start() {
+	t.startOnce.Do(func() {
+		go t.run()
+	})
+}
+
+func (t *Tendermint) Stop() {
+	t.stopOnce.Do(func() {
+		close(t.quit)
+	})
+	t.startOnce.Do(func() {
+		close(t.stopped)
+	})
+	<-t.stopped
+}
+
+func (t *Tendermint) Submit(block *blk.Block) {
+	s := submission{block: block, done: make(chan struct{})}
+	select {
+	case t.submissions <- s:
+	case <-t.quit:
+		t.backend.RestoreBlock(block)
+		return
+	}
+
+	select {
+	case <-s.done:
+	case <-t.stopped:
+	}
+}
+
+// Proposer returns the proposer of the current height and round, validators not running consensus
+// forward transactions to it
+func (t *Tendermint) Proposer() (keys.PublicKeyBytes, error) {
+	t.positionMutex.Lock()
+	defer t.positionMutex.Unlock()
+	if !t.position.running {
+		return keys.PublicKeyBytes{}, ErrNotRunning
+	}
+	if len(t.position.validators) == 0 {
+		return keys.PublicKeyBytes{}, ErrEmptyValidatorSet
+	}
+	return Proposer(t.position.validators, t.position.height, t.position.round), nil
+}
+
+func (t *Tendermint) HandleMessage(message *Message) {
+	select {
+	case t.messages <- message:
+	case <-t.quit:
+	}
+}
+
+func (t *Tendermint) run() {
+	defer close(t.stopped)
+	defer func() {
+		t.positionMutex.Lock()
+		t.position.running = false
+		t.positionMutex.Unlock()
+	}()
+
+	t.publicKey = t.backend.PublicKey()
+	t.newHeight()
+	t.processQueue()
+
+	for {
+		select {
+		case <-t.quit:
+			t.resolveCandidate([32]byte{})
+			return
+		case message := <-t.messages:
+			t.handleMessage(message, false)
+		case s := <-t.submissions:
+			t.handleSubmission(s)
+		case ti := <-t.timeoutsC:
+			t.handleTimeout(ti)
+		}
+		t.processQueue()
+	}
+}
+
+func (t *Tendermint) processQueue() {
+	for len(t.queue) > 0 {
+		queued := t.queue[0]
+		t.queue = t.queue[1:]
+		t.handleMessage(queued.message, queued.own)
+	}
+}
+
+func (t *Tendermint) newHeight() {
+	t.height = t.backend.Height()
+	t.validators = SortValidators(t.backend.ValidatorSet())
+	t.active = false
+
+	t.proposals = map[uint32]*Proposal{}
+	t.blocks = map[[32]byte]*blk.Block{}
+	t.validity = map[[32]byte]bool{}
+	t.prevotes = map[uint32]*voteSet{}
+	t.precommits = map[uint32]*voteSet{}
+	t.roundSenders = map[uint32]map[keys.PublicKeyBytes]struct{}{}
+	t.reported = map[keys.PublicKeyBytes]struct{}{}
+
+	t.lockedRound = -1
+	t.lockedHash = [32]byte{}
+	t.validRound = -1
+	t.validBlock = nil
+
+	t.decided = nil
+	t.attempt = 0
+	t.nonces = map[uint32]map[keys.PublicKeyBytes]*Nonce{}
+	t.finalizes = map[uint32]*Finalize{}
+	t.pendingFinalizes = map[uint32]*Finalize{}
+	t.partials = map[uint32]map[keys.PublicKeyBytes]*Partial{}
+	t.partialSent = map[uint32]bool{}
+	t.aggregated = map[uint32]bool{}
+
+	if t.candidate != nil {
+		if t.candidate.block.Header.Previous != t.backend.LastBlockHash() {
+			t.resolveCandidate([32]byte{})
+		} else {
+			t.active = true
+		}
+	}
+
+	t.startRound(0)
+
+	for _, message := range t.future {
+		t.queue = append(t.queue, queuedMessage{message: message})
+	}
+	t.future = nil
+}
+
+func (t *Tendermint) startRound(round uint32) {
+	t.round = round
+	t.updatePosition()
+	t.step = StepPropose
+	t.proposalSent = false
+	t.lockHandled = false
+	t.prevoteTimeoutScheduled = false
+	t.precommitTimeoutScheduled = false
+
+	if t.isProposer() {
+		t.tryPropose()
+	}
+	if !t.proposalSent && t.active {
+		t.schedule(StepPropose)
+	}
+
+	t.evaluateRound()
+}
+
+func (t *Tendermint) updatePosition() {
+	t.positionMutex.Lock()
+	defer t.positionMutex.Unlock()
+	t.position = position{running: true, height: t.height, round: t.round, validators: t.validators}
+}
+
+func (t *Tendermint) isProposer() bool {
+	return len(t.validators) > 0 && Proposer(t.validators, t.height, t.round) == t.publicKey
+}
+
+func (t *Tendermint) tryPropose() {
+	block, validRound := t.validBlock, t.validRound
+	if block == nil && t.candidate != nil {
+		block, validRound = t.candidate.block, -1
+	}
+	if block == nil {
+		return
+	}
+
+	proposal := &Proposal{
+		Height:     t.height,
+		Round:      t.round,
+		ValidRound: validRound,
+		Block:      block,
+		Proposer:   t.publicKey,
+	}
+	proposal.Signature = t.backend.Sign(proposal.GetSignatureMessage())
+	t.proposalSent = true
+
+	log.Printf("Proposing block with hash %s; Height: %d; Round: %d", block.GetHashString(), t.height, t.round)
+	t.send(&Message{MessageType: ProposalMessage, Proposal: proposal})
+}
+
+func (t *Tendermint) send(message *Message) {
+	t.queue = append(t.queue, queuedMessage{message: message, own: true})
+	t.broadcaster.Broadcast(message)
+}
+
+func (t *Tendermint) handleSubmission(s submission) {
+	if s.block.Header.Previous != t.backend.LastBlockHash() || t.candidate != nil {
+		t.backend.RestoreBlock(s.block)
+		close(s.done)
+		return
+	}
+
+	t.candidate = &s
+	t.markActive()
+	if t.step == StepPropose && !t.proposalSent && t.isProposer() {
+		t.tryPropose()
+	}
+}
+
+// resolveCandidate restores the block created by this validator unless it is the committed one
+func (t *Tendermint) resolveCandidate(committedHash [32]byte) {
+	if t.candidate == nil {
+		return
+	}
+	if t.candidate.block.GetHash() != committedHash {
+		t.backend.RestoreBlock(t.candidate.block)
+	}
+	close(t.candidate.done)
+	t.candidate = nil
+}
+
+func (t *Tendermint) markActive() {
+	if t.active {
+		return
+	}
+	t.active = true
+	if t.step == StepPropose && !t.proposalSent {
+		t.schedule(StepPropose)
+	}
+}
+
+func (t *Tendermint) schedule(step Step) {
+	t.scheduleTimeout(timeoutInfo{height: t.height, round: t.round, step: step})
+}
+
+func (t *Tendermint) scheduleTimeout(ti timeoutInfo) {
+	time.AfterFunc(t.timeouts.forStep(ti.step, ti.round), func() {
+		select {
+		case t.timeoutsC <- ti:
+		case <-t.quit:
+		}
+	})
+}
+
+func (t *Tendermint) handleTimeout(ti timeoutInfo) {
+	if ti.height != t.height {
+		return
+	}
+	if ti.step == StepCommit {
+		if t.decided != nil && ti.round == t.attempt {
+			logging.Errorf("Witness of height %d was not aggregated in attempt %d", t.height, t.attempt)
+			t.startAttempt(t.attempt + 1)
+		}
+		return
+	}
+	if ti.round != t.round || t.decided != nil {
+		return
+	}
+
+	switch ti.step {
+	case StepPropose:
+		if t.step == StepPropose {
+			log.Printf("Propose timeout; Height: %d; Round: %d", t.height, t.round)
+			t.prevote([32]byte{})
+		}
+	case StepPrevote:
+		if t.step == StepPrevote {
+			t.precommit([32]byte{})
+		}
+	case StepPrecommit:
+		log.Printf("Round %d of height %d timed out, starting round %d", t.round, t.height, t.round+1)
+		t.startRound(t.round + 1)
+	}
+}
+
+func (t *Tendermint) handleMessage(message *Message, own bool) {
+	if len(t.validators) == 0 {
+		t.validators = SortValidators(t.backend.ValidatorSet())
+		t.updatePosition()
+	}
+
+	var round uint32
+	switch message.MessageType {
+	case ProposalMessage:
+		proposal := message.Proposal
+		if proposal == nil || proposal.Block == nil || !t.acceptHeight(message, proposal.Height) || !t.acceptRound(proposal.Round) {
+			return
+		}
+		if proposal.Proposer != Proposer(t.validators, t.height, proposal.Round) {
+			log.Printf("Proposal from unexpected proposer; Height: %d; Round: %d", proposal.Height, proposal.Round)
+			return
+		}
+		existing, exists := t.proposals[proposal.Round]
+		if exists && existing.Block.GetHash() == proposal.Block.GetHash() {
+			return
+		}
+		if !own && !proposal.VerifySignature() {
+			logging.Errorln("Proposal contains invalid signature")
+			return
+		}
+		if exists {
+			t.reportEquivocation(evidence.NewProposalEvidence(existing.Header(), proposal.Header()))
+			return
+		}
+
+		t.proposals[proposal.Round] = proposal
+		t.blocks[proposal.Block.GetHash()] = proposal.Block
+		t.addRoundSender(proposal.Round, proposal.Proposer)
+		round = proposal.Round
+	case VoteMessage:
+		vote := message.Vote
+		if vote == nil || !t.acceptHeight(message, vote.Height) || !t.acceptRound(vote.Round) {
+			return
+		}
+		if !isValidator(t.validators, vote.Validator) {
+			log.Println("Vote from unknown validator")
+			return
+		}
+		if !own && !t.verifyVote(vote) {
+			logging.Errorln("Vote contains invalid signature")
+			return
+		}
+
+		votes := t.votes(vote.VoteType, vote.Round)
+		if !votes.add(vote) {
+			if existing := votes.votes[vote.Validator]; existing.BlockHash != vote.BlockHash {
+				t.reportEquivocation(evidence.NewVoteEvidence(*existing, *vote))
+			}
+			return
+		}
+		t.addRoundSender(vote.Round, vote.Validator)
+		round = vote.Round
+	case NonceMessage, FinalizeMessage, PartialMessage:
+		t.handleSigningMessage(message, own)
+		return
+	default:
+		log.Printf("unknown consensus message type %d", message.MessageType)
+		return
+	}
+
+	t.markActive()
+	t.evaluate(round)
+}
+
+// acceptHeight buffers messages of the next height and drops stale ones
+func (t *Tendermint) acceptHeight(message *Message, height uint64) bool {
+	if height == t.height+1 && len(t.future) < maxFutureMessages {
+		t.future = append(t.future, message)
+	}
+	return height == t.height
+}
+
+func (t *Tendermint) acceptRound(round uint32) bool {
+	return round < t.round+maxRoundsAhead
+}
+
+// reportEquivocation hands evidence to the backend once per offender and height,
+// the offender keeps its place in the set of this height until the evidence is committed
+func (t *Tendermint) reportEquivocation(e *evidence.Evidence) {
+	offender := e.Offender()
+	if _, exists := t.reported[offender]; exists {
+		return
+	}
+	t.reported[offender] = struct{}{}
+
+	height, round := e.Position()
+	log.Printf("Validator %x signed two different blocks; Height: %d; Round: %d", offender, height, round)
+	t.backend.ReportEvidence(e)
+}
+
+func (t *Tendermint) verifyVote(vote *Vote) bool {
+	return vote.VerifySignature()
+}
+
+func (t *Tendermint) votes(voteType VoteType, round uint32) *voteSet {
+	sets := t.prevotes
+	if voteType == Precommit {
+		sets = t.precommits
+	}
+	if _, exists := sets[round]; !exists {
+		sets[round] = newVoteSet()
+	}
+	return sets[round]
+}
+
+func (t *Tendermint) addRoundSender(round uint32, publicKey keys.PublicKeyBytes) {
+	if _, exists := t.roundSenders[round]; !exists {
+		t.roundSenders[round] = map[keys.PublicKeyBytes]struct{}{}
+	}
+	t.roundSenders[round][publicKey] = struct{}{}
+}
+
+func (t *Tendermint) isValid(block *blk.Block) bool {
+	hash := block.GetHash()
+	valid, checked := t.validity[hash]
+	if !checked {
+		err := t.backend.VerifyProposal(block)
+		if err != nil {
+			logging.Errorf("Proposed block with hash %s is invalid: %v", block.GetHashString(), err)
+		}
+		valid = err == nil
+		t.validity[hash] = valid
+	}
+	return valid
+}
+
+// evaluate applies the rules triggered by a message of the given round
+func (t *Tendermint) evaluate(round uint32) {
+	if t.decided != nil {
+		t.evaluateWitness()
+		return
+	}
+
+	quorum := Quorum(len(t.validators))
+
+	// A block is decided once it gets a precommit quorum in any round,
+	// the proposal may arrive later than the precommits so all rounds are checked
+	for _, precommits := range t.precommits {
+		if hash, ok := precommits.majority(quorum); ok && hash != [32]byte{} {
+			if block, known := t.blocks[hash]; known {
+				t.decide(block)
+				return
+			}
+		}
+	}
+
+	// Catch up with the round more than a third of the validators are already in
+	if round > t.round && len(t.roundSenders[round]) >= SkipThreshold(len(t.validators)) {
+		t.startRound(round)
+		return
+	}
+
+	t.evaluateRound()
+}
+
+// evaluateRound applies the rules of the current round
+func (t *Tendermint) evaluateRound() {
+	quorum := Quorum(len(t.validators))
+	prevotes := t.votes(Prevote, t.round)
+	precommits := t.votes(Precommit, t.round)
+	proposal := t.proposals[t.round]
+
+	if t.step == StepPropose && proposal != nil {
+		hash := proposal.Block.GetHash()
+		if proposal.ValidRound == -1 {
+			if t.isValid(proposal.Block) && (t.lockedRound == -1 || t.lockedHash == hash) {
+				t.prevote(hash)
+			} else {
+				t.prevote([32]byte{})
+			}
+		} else if proposal.ValidRound >= 0 && uint32(proposal.ValidRound) < t.round &&
+			t.votes(Prevote, uint32(proposal.ValidRound)).count(hash) >= quorum {
+			if t.isValid(proposal.Block) && (t.lockedRound <= proposal.ValidRound || t.lockedHash == hash) {
+				t.prevote(hash)
+			} else {
+				t.prevote([32]byte{})
+			}
+		}
+	}
+
+	if t.step == StepPrevote && prevotes.total() >= quorum && !t.prevoteTimeoutScheduled {
+		t.prevoteTimeoutScheduled = true
+		t.schedule(StepPrevote)
+	}
+
+	if t.step >= StepPrevote && proposal != nil && !t.lockHandled {
+		hash := proposal.Block.GetHash()
+		if prevotes.count(hash) >= quorum && t.isValid(proposal.Block) {
+			t.lockHandled = true
+			if t.step == StepPrevote {
+				t.lockedRound = int32(t.round)
+				t.lockedHash = hash
+				t.precommit(hash)
+			}
+			t.validRound = int32(t.round)
+			t.validBlock = proposal.Block
+		}
+	}
+
+	if t.step == StepPrevote && prevotes.count([32]byte{}) >= quorum {
+		t.precommit([32]byte{})
+	}
+
+	if precommits.total() >= quorum && !t.precommitTimeoutScheduled {
+		t.precommitTimeoutScheduled = true
+		t.schedule(StepPrecommit)
+	}
+}
+
+func (t *Tendermint) prevote(hash [32]byte) {
+	t.step = StepPrevote
+	t.vote(Prevote, hash)
+}
+
+func (t *Tendermint) precommit(hash [32]byte) {
+	t.step = StepPrecommit
+	t.vote(Precommit, hash)
+}
+
+func (t *Tendermint) vote(voteType VoteType, hash [32]byte) {
+	if !isValidator(t.validators, t.publicKey) {
+		return
+	}
+
+	vote := &Vote{
+		VoteType:  voteType,
+		Height:    t.height,
+		Round:     t.round,
+		BlockHash: hash,
+		Validator: t.publicKey,
+	}
+	vote.Signature = t.backend.Sign(vote.GetSignatureMessage())
+
+	t.send(&Message{MessageType: VoteMessage, Vote: vote})
+}
+
+// commit hands the block with the aggregated witness over to the backend
+func (t *Tendermint) commit(block *blk.Block, attempt uint32) {
+	err := t.backend.CommitBlock(block)
+	if err != nil {
+		logging.Errorf("Block with hash %s was decided but not committed: %v", block.GetHashString(), err)
+	} else {
+		log.Printf("Committed block with hash %s; Height: %d; Attempt: %d", block.GetHashString(), t.height, attempt)
+	}
+
+	t.resolveCandidate(block.GetHash())
+	t.newHeight()
+}
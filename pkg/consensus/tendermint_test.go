@@ -0,0 +1,401 @@
+package consensus
+
+import (
+	"encoding/json"
+	"fmt"
+	blk "github.com/Digital-Voting-Team/Digital-Voting/pkg/blockchain/block"
+	tx "github.com/Digital-Voting-Team/Digital-Voting/pkg/blockchain/transaction"
+	ts "github.com/Digital-Voting-Team/Digital-Voting/pkg/blockchain/transaction/transaction_specific"
+	"github.com/Digital-Voting-Team/Digital-Voting/pkg/consensus/evidence"
+	"github.com/Digital-Voting-Team/Digital-Voting/pkg/models/account"
+	"github.com/Digital-Voting-Team/Digital-Voting/pkg/signature/curve"
+	"github.com/Digital-Voting-Team/Digital-Voting/pkg/signature/keys"
+	ms "github.com/Digital-Voting-Team/Digital-Voting/pkg/signature/signatures/multi_signature"
+	ss "github.com/Digital-Voting-Team/Digital-Voting/pkg/signature/signatures/single_signature"
+	"github.com/Digital-Voting-Team/Digital-Voting/pkg/signer"
+	"github.com/stretchr/testify/require"
+	"sync"
+	"testing"
+	"time"
+)
+
+var testTimeouts = Timeouts{
+	Propose:        400 * time.Millisecond,
+	ProposeDelta:   100 * time.Millisecond,
+	Prevote:        200 * time.Millisecond,
+	PrevoteDelta:   100 * time.Millisecond,
+	Precommit:      200 * time.Millisecond,
+	PrecommitDelta: 100 * time.Millisecond,
+	Finalize:       time.Second,
+	FinalizeDelta:  100 * time.Millisecond,
+}
+
+type testBackend struct {
+	mutex      sync.Mutex
+	keyPair    *keys.KeyPair
+	signer     *signer.BlockSigner
+	validators []keys.PublicKeyBytes
+	chain      []*blk.Block
+	restored   []*blk.Block
+	evidence   []*evidence.Evidence
+	committed  chan *blk.Block
+}
+
+func (b *testBackend) Height() uint64 {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	return uint64(len(b.chain))
+}
+
+func (b *testBackend) LastBlockHash() [32]byte {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	return b.chain[len(b.chain)-1].GetHash()
+}
+
+func (b *testBackend) ValidatorSet() []keys.PublicKeyBytes {
+	return b.validators
+}
+
+func (b *testBackend) PublicKey() keys.PublicKeyBytes {
+	return b.keyPair.PublicToBytes()
+}
+
+func (b *testBackend) Sign(message string) ss.SingleSignatureBytes {
+	ecdsa := ss.NewECDSA()
+	signature := ecdsa.SignEdDSA(message, b.keyPair.GetPrivateKey(), b.keyPair.GetPublicKey())
+	return ecdsa.EdwardsToSingleSignature(signature).EdwardsSignatureToBytes()
+}
+
+func (b *testBackend) CreateNonce(session string) (ms.NonceBytes, error) {
+	return b.signer.CreateNonce(session)
+}
+
+func (b *testBackend) SignPartial(session string, block *blk.Block, signers []keys.PublicKeyBytes, nonces []ms.NonceBytes) (ms.PartialSignatureBytes, error) {
+	return b.signer.SignPartial(b.keyPair, session, block, signers, nonces)
+}
+
+func (b *testBackend) VerifyProposal(block *blk.Block) error {
+	if block.Header.Previous != b.LastBlockHash() {
+		return fmt.Errorf("block does not follow the last block")
+	}
+	return nil
+}
+
+func (b *testBackend) CommitBlock(block *blk.Block) error {
+	b.mutex.Lock()
+	b.chain = append(b.chain, block)
+	b.mutex.Unlock()
+	b.committed <- block
+	return nil
+}
+
+func (b *testBackend) RestoreBlock(block *blk.Block) {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	b.restored = append(b.restored, block)
+}
+
+func (b *testBackend) ReportEvidence(e *evidence.Evidence) {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	b.evidence = append(b.evidence, e)
+}
+
+// testNetwork delivers every broadcast message to all other connected engines
+type testNetwork struct {
+	engines map[keys.PublicKeyBytes]*Tendermint
+}
+
+type testBroadcaster struct {
+	network *testNetwork
+	from    keys.PublicKeyBytes
+}
+
+func (b *testBroadcaster) Broadcast(message *Message) {
+	for publicKey, engine := range b.network.engines {
+		if publicKey != b.from {
+			go engine.HandleMessage(message)
+		}
+	}
+}
+
+func newTestValidators(t *testing.T, number int) ([]*testBackend, []keys.PublicKeyBytes) {
+	genesis := &blk.Block{}
+	backends := make([]*testBackend, number)
+	validators := make([]keys.PublicKeyBytes, number)
+	for i := range backends {
+		keyPair, err := keys.Random(curve.NewCurve25519())
+		require.NoError(t, err)
+		backends[i] = &testBackend{
+			keyPair:   keyPair,
+			signer:    signer.NewBlockSigner(),
+			chain:     []*blk.Block{genesis},
+			committed: make(chan *blk.Block, 1),
+		}
+		validators[i] = keyPair.PublicToBytes()
+	}
+	for _, backend := range backends {
+		backend.validators = validators
+	}
+	return backends, SortValidators(validators)
+}
+
+func newTestBlock(previous [32]byte) *blk.Block {
+	return &blk.Block{
+		Header: blk.Header{
+			Previous:  previous,
+			TimeStamp: uint64(time.Now().UnixNano()),
+		},
+	}
+}
+
+func TestProposer(t *testing.T) {
+	validators := SortValidators([]keys.PublicKeyBytes{{3}, {1}, {2}})
+	tests := []struct {
+		name   string
+		height uint64
+		round  uint32
+		want   keys.PublicKeyBytes
+	}{
+		{name: "First height", height: 0, round: 0, want: keys.PublicKeyBytes{1}},
+		{name: "Next height", height: 1, round: 0, want: keys.PublicKeyBytes{2}},
+		{name: "Next round", height: 1, round: 1, want: keys.PublicKeyBytes{3}},
+		{name: "Wraps around", height: 2, round: 2, want: keys.PublicKeyBytes{2}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.Equal(t, tt.want, Proposer(validators, tt.height, tt.round))
+		})
+	}
+}
+
+func TestTendermint_Proposer(t *testing.T) {
+	backends, sorted := newTestValidators(t, 4)
+	empty, _ := newTestValidators(t, 1)
+	empty[0].validators = nil
+
+	tests := []struct {
+		name    string
+		backend *testBackend
+		start   bool
+		want    keys.PublicKeyBytes
+		wantErr error
+	}{
+		{name: "Engine is not running", backend: backends[0], start: false, wantErr: ErrNotRunning},
+		{name: "Proposer of the first round", backend: backends[0], start: true, want: Proposer(sorted, 1, 0)},
+		{name: "Empty validator set", backend: empty[0], start: true, wantErr: ErrEmptyValidatorSet},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			engine := NewTendermint(tt.backend, &testBroadcaster{network: &testNetwork{}}, testTimeouts)
+			if tt.start {
+				engine.Start()
+				defer engine.Stop()
+			}
+
+			// the run loop takes the first position right after start
+			var got keys.PublicKeyBytes
+			var err error
+			for i := 0; i < 100; i++ {
+				if got, err = engine.Proposer(); err != ErrNotRunning || !tt.start {
+					break
+				}
+				time.Sleep(10 * time.Millisecond)
+			}
+			if got != tt.want || err != tt.wantErr {
+				t.Errorf("Proposer() = %x, %v, want %x, %v", got, err, tt.want, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestTendermint_Commit(t *testing.T) {
+	tests := []struct {
+		name    string
+		crashed int
+	}{
+		{
+			name:    "All validators are online",
+			crashed: 0,
+		},
+		{
+			name:    "Proposer of the first round is offline",
+			crashed: 1,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			backends, sorted := newTestValidators(t, 4)
+			proposer := Proposer(sorted, 1, 0)
+
+			network := &testNetwork{engines: map[keys.PublicKeyBytes]*Tendermint{}}
+			online := []*testBackend{}
+			for _, backend := range backends {
+				if tt.crashed > 0 && backend.PublicKey() == proposer {
+					continue
+				}
+				online = append(online, backend)
+				broadcaster := &testBroadcaster{network: network, from: backend.PublicKey()}
+				network.engines[backend.PublicKey()] = NewTendermint(backend, broadcaster, testTimeouts)
+			}
+			for _, engine := range network.engines {
+				engine.Start()
+				defer engine.Stop()
+			}
+
+			// The block is submitted by a validator that isn't the proposer of the first round
+			submitter := online[0]
+			if submitter.PublicKey() == proposer {
+				submitter = online[1]
+			}
+			block := newTestBlock(submitter.LastBlockHash())
+			go network.engines[submitter.PublicKey()].Submit(block)
+
+			for _, backend := range online {
+				select {
+				case committed := <-backend.committed:
+					require.Equal(t, block.GetHash(), committed.GetHash())
+					signers, err := committed.Witness.GetSigners(sorted)
+					require.NoError(t, err)
+					require.GreaterOrEqual(t, len(signers), Quorum(len(backends)))
+					require.True(t, ms.NewMuSig().VerifyBytes(committed.GetHashString(), signers, committed.Witness.Signature))
+				case <-time.After(20 * time.Second):
+					t.Fatal("block was not committed in time")
+				}
+			}
+		})
+	}
+}
+
+func TestTendermint_ReportsEquivocation(t *testing.T) {
+	backends, sorted := newTestValidators(t, 4)
+	proposer := Proposer(sorted, 1, 0)
+	var observer, offender *testBackend
+	for _, backend := range backends {
+		if backend.PublicKey() == proposer {
+			offender = backend
+		} else if observer == nil {
+			observer = backend
+		}
+	}
+
+	vote := func(blockHash [32]byte) *Message {
+		vote := &Vote{VoteType: Precommit, Height: 1, Round: 0, BlockHash: blockHash, Validator: offender.PublicKey()}
+		vote.Signature = offender.Sign(vote.GetSignatureMessage())
+		return &Message{MessageType: VoteMessage, Vote: vote}
+	}
+	proposal := func() *Message {
+		proposal := &Proposal{Height: 1, Round: 0, ValidRound: -1, Block: newTestBlock(offender.LastBlockHash()), Proposer: offender.PublicKey()}
+		proposal.Signature = offender.Sign(proposal.GetSignatureMessage())
+		return &Message{MessageType: ProposalMessage, Proposal: proposal}
+	}
+	repeated := vote([32]byte{1})
+
+	tests := []struct {
+		name     string
+		messages []*Message
+		want     int
+	}{
+		{name: "Same vote twice", messages: []*Message{repeated, repeated}, want: 0},
+		{name: "Votes for different blocks", messages: []*Message{vote([32]byte{1}), vote([32]byte{2})}, want: 1},
+		{name: "Offender is reported once a height", messages: []*Message{vote([32]byte{1}), vote([32]byte{2}), vote([32]byte{3})}, want: 1},
+		{name: "Proposals of different blocks", messages: []*Message{proposal(), proposal()}, want: 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			observer.evidence = nil
+			engine := NewTendermint(observer, &testBroadcaster{network: &testNetwork{}}, testTimeouts)
+			engine.publicKey = observer.PublicKey()
+			engine.newHeight()
+
+			for _, message := range tt.messages {
+				engine.handleMessage(message, false)
+			}
+
+			require.Len(t, observer.evidence, tt.want)
+			for _, e := range observer.evidence {
+				require.NoError(t, e.Verify())
+				require.Equal(t, offender.PublicKey(), e.Offender())
+			}
+		})
+	}
+}
+
+func TestProposal_UnmarshalJSON(t *testing.T) {
+	keyPair, err := keys.Random(curve.NewCurve25519())
+	require.NoError(t, err)
+
+	transaction := tx.NewTransaction(tx.AccountCreation, ts.NewTxAccCreation(account.User, keyPair.PublicToBytes()))
+	signer.NewTransactionSigner().SignTransaction(keyPair, transaction)
+	block := blk.NewBlock([]tx.ITransaction{transaction}, [32]byte{1})
+
+	backend := &testBackend{keyPair: keyPair}
+	proposal := &Proposal{Height: 1, Round: 2, ValidRound: -1, Block: block, Proposer: backend.PublicKey()}
+	proposal.Signature = backend.Sign(proposal.GetSignatureMessage())
+
+	marshalled, err := json.Marshal(&Message{MessageType: ProposalMessage, Proposal: proposal})
+	require.NoError(t, err)
+
+	message := &Message{}
+	require.NoError(t, json.Unmarshal(marshalled, message))
+	require.Equal(t, block.GetHash(), message.Proposal.Block.GetHash())
+	require.Equal(t, proposal.ValidRound, message.Proposal.ValidRound)
+	require.True(t, message.Proposal.VerifySignature())
+}
+
+func TestTendermint_AcceptFinalize(t *testing.T) {
+	_, validators := newTestValidators(t, 2)
+	hash := [32]byte{1}
+	sent := []ms.NonceBytes{{1}, {2}}
+
+	tests := []struct {
+		name        string
+		received    int
+		nonces      []ms.NonceBytes
+		wantAccept  bool
+		wantPending bool
+	}{
+		{name: "Nonces the signers sent", received: 2, nonces: sent, wantAccept: true},
+		{name: "Nonce replaced by the aggregator", received: 2, nonces: []ms.NonceBytes{{1}, {3}}},
+		{name: "Nonce not received yet", received: 1, nonces: sent, wantPending: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			engine := &Tendermint{
+				nonces:           map[uint32]map[keys.PublicKeyBytes]*Nonce{0: {}},
+				finalizes:        map[uint32]*Finalize{},
+				pendingFinalizes: map[uint32]*Finalize{},
+			}
+			for i := 0; i < tt.received; i++ {
+				engine.nonces[0][validators[i]] = &Nonce{BlockHash: hash, Validator: validators[i], Nonce: sent[i]}
+			}
+
+			engine.acceptFinalize(&Finalize{BlockHash: hash, Signers: validators, Nonces: tt.nonces})
+			_, accepted := engine.finalizes[0]
+			_, pending := engine.pendingFinalizes[0]
+			require.Equal(t, tt.wantAccept, accepted)
+			require.Equal(t, tt.wantPending, pending)
+		})
+	}
+}
+
+func TestTendermint_AcceptRound(t *testing.T) {
+	tests := []struct {
+		name  string
+		round uint32
+		want  bool
+	}{
+		{name: "Past round", round: 1, want: true},
+		{name: "Current round", round: 2, want: true},
+		{name: "Last round kept ahead", round: 2 + maxRoundsAhead - 1, want: true},
+		{name: "Round too far ahead", round: 2 + maxRoundsAhead, want: false},
+		{name: "Largest round", round: ^uint32(0), want: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			engine := &Tendermint{round: 2}
+			require.Equal(t, tt.want, engine.acceptRound(tt.round))
+		})
+	}
+}
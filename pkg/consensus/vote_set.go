@@ -0,0 +1,56 @@
+package consensus
+
+import (
+	"github.com/Digital-Voting-Team/Digital-Voting/pkg/signature/keys"
+)
+
+// voteSet holds votes of one type for one round
+type voteSet struct {
+	votes  map[keys.PublicKeyBytes]*Vote
+	counts map[[32]byte]int
+}
+
+func newVoteSet() *voteSet {
+	return &voteSet{
+		votes:  map[keys.PublicKeyBytes]*Vote{},
+		counts: map[[32]byte]int{},
+	}
+}
+
+// add returns false if the validator has already voted in this round
+func (vs *voteSet) add(vote *Vote) bool {
+	if _, exists := vs.votes[vote.Validator]; exists {
+		return false
+	}
+	vs.votes[vote.Validator] = vote
+	vs.counts[vote.BlockHash]++
+	return true
+}
+
+func (vs *voteSet) total() int {
+	return len(vs.votes)
+}
+
+func (vs *voteSet) count(blockHash [32]byte) int {
+	return vs.counts[blockHash]
+}
+
+// majority returns the hash that has at least quorum votes, zero hash stands for nil
+func (vs *voteSet) majority(quorum int) ([32]byte, bool) {
+	for hash, count := range vs.counts {
+		if count >= quorum {
+			return hash, true
+		}
+	}
+	return [32]byte{}, false
+}
+
+func (vs *voteSet) votesFor(blockHash [32]byte) []*Vote {
+	result := []*Vote{}
+	for _, vote := range vs.votes {
+		if vote.BlockHash == blockHash {
+			result = append(result, vote)
+		}
+	}
+	return result
+}
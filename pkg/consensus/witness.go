@@ -0,0 +1,283 @@
+package consensus
+
+import (
+	"bytes"
+	"fmt"
+	blk "github.com/Digital-Voting-Team/Digital-Voting/pkg/blockchain/block"
+	"github.com/Digital-Voting-Team/Digital-Voting/pkg/logging"
+	"github.com/Digital-Voting-Team/Digital-Voting/pkg/signature/keys"
+	ms "github.com/Digital-Voting-Team/Digital-Voting/pkg/signature/signatures/multi_signature"
+	"log"
+)
+
+// decide stops the rounds of the height and starts aggregating the witness of the block
+func (t *Tendermint) decide(block *blk.Block) {
+	t.decided = block
+	t.step = StepCommit
+	log.Printf("Decided block with hash %s; Height: %d; Round: %d", block.GetHashString(), t.height, t.round)
+
+	t.startAttempt(0)
+}
+
+// startAttempt sends a fresh nonce, the nonces of the previous attempts are never reused
+func (t *Tendermint) startAttempt(attempt uint32) {
+	t.attempt = attempt
+	t.scheduleTimeout(timeoutInfo{height: t.height, round: attempt, step: StepCommit})
+
+	if isValidator(t.validators, t.publicKey) {
+		nonce, err := t.backend.CreateNonce(t.session(attempt))
+		if err != nil {
+			logging.Errorln("Error creating nonce:", err)
+		} else {
+			message := &Nonce{
+				Height:    t.height,
+				Attempt:   attempt,
+				BlockHash: t.decided.GetHash(),
+				Validator: t.publicKey,
+				Nonce:     nonce,
+			}
+			message.Signature = t.backend.Sign(message.GetSignatureMessage())
+			t.send(&Message{MessageType: NonceMessage, Nonce: message})
+		}
+	}
+
+	t.evaluateWitness()
+}
+
+func (t *Tendermint) session(attempt uint32) string {
+	return fmt.Sprintf("%d/%d/%s", t.height, attempt, t.decided.GetHashString())
+}
+
+// aggregator collects nonces of the attempt and sends Finalize, the role rotates between attempts
+func (t *Tendermint) aggregator(attempt uint32) keys.PublicKeyBytes {
+	return Proposer(t.validators, t.height, attempt)
+}
+
+func (t *Tendermint) handleSigningMessage(message *Message, own bool) {
+	switch message.MessageType {
+	case NonceMessage:
+		nonce := message.Nonce
+		if nonce == nil || !t.acceptHeight(message, nonce.Height) || !t.acceptAttempt(nonce.Attempt) {
+			return
+		}
+		if !isValidator(t.validators, nonce.Validator) {
+			log.Println("Nonce from unknown validator")
+			return
+		}
+		if _, exists := t.nonces[nonce.Attempt][nonce.Validator]; exists {
+			return
+		}
+		if !own && !nonce.VerifySignature() {
+			logging.Errorln("Nonce contains invalid signature")
+			return
+		}
+
+		if _, exists := t.nonces[nonce.Attempt]; !exists {
+			t.nonces[nonce.Attempt] = map[keys.PublicKeyBytes]*Nonce{}
+		}
+		t.nonces[nonce.Attempt][nonce.Validator] = nonce
+
+		if pending, exists := t.pendingFinalizes[nonce.Attempt]; exists {
+			delete(t.pendingFinalizes, nonce.Attempt)
+			t.acceptFinalize(pending)
+		}
+	case FinalizeMessage:
+		finalize := message.Finalize
+		if finalize == nil || !t.acceptHeight(message, finalize.Height) || !t.acceptAttempt(finalize.Attempt) {
+			return
+		}
+		if _, exists := t.finalizes[finalize.Attempt]; exists {
+			return
+		}
+		if _, exists := t.pendingFinalizes[finalize.Attempt]; exists {
+			return
+		}
+		if finalize.Aggregator != t.aggregator(finalize.Attempt) {
+			log.Printf("Finalize from unexpected aggregator; Height: %d; Attempt: %d", finalize.Height, finalize.Attempt)
+			return
+		}
+		if !t.validSigners(finalize) {
+			logging.Errorln("Finalize contains invalid signers")
+			return
+		}
+		if !own && !finalize.VerifySignature() {
+			logging.Errorln("Finalize contains invalid signature")
+			return
+		}
+
+		t.acceptFinalize(finalize)
+	case PartialMessage:
+		partial := message.Partial
+		if partial == nil || !t.acceptHeight(message, partial.Height) || !t.acceptAttempt(partial.Attempt) {
+			return
+		}
+		if !isValidator(t.validators, partial.Validator) {
+			log.Println("Partial signature from unknown validator")
+			return
+		}
+		if _, exists := t.partials[partial.Attempt][partial.Validator]; exists {
+			return
+		}
+		if !own && !partial.VerifySignature() {
+			logging.Errorln("Partial signature message contains invalid signature")
+			return
+		}
+
+		if _, exists := t.partials[partial.Attempt]; !exists {
+			t.partials[partial.Attempt] = map[keys.PublicKeyBytes]*Partial{}
+		}
+		t.partials[partial.Attempt][partial.Validator] = partial
+	}
+
+	t.evaluateWitness()
+}
+
+func (t *Tendermint) acceptAttempt(attempt uint32) bool {
+	return attempt < t.attempt+maxAttemptsAhead
+}
+
+// validSigners checks that Finalize lists a quorum of distinct validators in sorted order
+func (t *Tendermint) validSigners(finalize *Finalize) bool {
+	if len(finalize.Signers) < Quorum(len(t.validators)) || len(finalize.Signers) != len(finalize.Nonces) {
+		return false
+	}
+	for i, publicKey := range finalize.Signers {
+		if !isValidator(t.validators, publicKey) {
+			return false
+		}
+		if i > 0 && bytes.Compare(finalize.Signers[i-1][:], publicKey[:]) >= 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// acceptFinalize keeps Finalize only if its nonces are the ones its signers sent, otherwise the aggregator
+// could pick nonces that cancel each other out. Finalize that arrived before some of the nonces waits for them
+func (t *Tendermint) acceptFinalize(finalize *Finalize) {
+	for i, publicKey := range finalize.Signers {
+		nonce, exists := t.nonces[finalize.Attempt][publicKey]
+		if !exists {
+			t.pendingFinalizes[finalize.Attempt] = finalize
+			return
+		}
+		if nonce.BlockHash != finalize.BlockHash || nonce.Nonce != finalize.Nonces[i] {
+			logging.Errorf("Finalize contains a nonce validator %x did not send; Height: %d; Attempt: %d", publicKey, finalize.Height, finalize.Attempt)
+			return
+		}
+	}
+
+	t.finalizes[finalize.Attempt] = finalize
+}
+
+// evaluateWitness moves aggregation of the witness forward. Validators that haven't decided yet
+// still commit the block once its witness is aggregated, signers sign only decided blocks
+func (t *Tendermint) evaluateWitness() {
+	if t.decided != nil {
+		t.tryFinalize()
+		t.trySignPartial()
+	}
+
+	for attempt, finalize := range t.finalizes {
+		if block := t.tryAggregate(attempt, finalize); block != nil {
+			t.commit(block, attempt)
+			return
+		}
+	}
+}
+
+func (t *Tendermint) tryFinalize() {
+	attempt := t.attempt
+	if _, exists := t.finalizes[attempt]; exists || t.aggregator(attempt) != t.publicKey {
+		return
+	}
+
+	hash := t.decided.GetHash()
+	signers := []keys.PublicKeyBytes{}
+	for publicKey, nonce := range t.nonces[attempt] {
+		if nonce.BlockHash == hash {
+			signers = append(signers, publicKey)
+		}
+	}
+	if len(signers) < Quorum(len(t.validators)) {
+		return
+	}
+
+	signers = keys.SortPublicKeys(signers)
+	nonces := make([]ms.NonceBytes, len(signers))
+	for i, publicKey := range signers {
+		nonces[i] = t.nonces[attempt][publicKey].Nonce
+	}
+
+	finalize := &Finalize{
+		Height:     t.height,
+		Attempt:    attempt,
+		BlockHash:  hash,
+		Aggregator: t.publicKey,
+		Signers:    signers,
+		Nonces:     nonces,
+	}
+	finalize.Signature = t.backend.Sign(finalize.GetSignatureMessage())
+	t.finalizes[attempt] = finalize
+
+	log.Printf("Finalizing block with hash %s; Height: %d; Attempt: %d; Signers: %d", t.decided.GetHashString(), t.height, attempt, len(signers))
+	t.send(&Message{MessageType: FinalizeMessage, Finalize: finalize})
+}
+
+// trySignPartial signs every attempt this validator is a signer of, a late Finalize of an earlier
+// attempt is still signed since its nonce was never used
+func (t *Tendermint) trySignPartial() {
+	hash := t.decided.GetHash()
+	for attempt, finalize := range t.finalizes {
+		if t.partialSent[attempt] || finalize.BlockHash != hash || !isValidator(finalize.Signers, t.publicKey) {
+			continue
+		}
+		t.partialSent[attempt] = true
+
+		signature, err := t.backend.SignPartial(t.session(attempt), t.decided, finalize.Signers, finalize.Nonces)
+		if err != nil {
+			logging.Errorln("Error signing witness:", err)
+			continue
+		}
+
+		partial := &Partial{
+			Height:    t.height,
+			Attempt:   attempt,
+			BlockHash: hash,
+			Validator: t.publicKey,
+			Partial:   signature,
+		}
+		partial.Signature = t.backend.Sign(partial.GetSignatureMessage())
+		t.send(&Message{MessageType: PartialMessage, Partial: partial})
+	}
+}
+
+// tryAggregate returns the block with the witness once all signers of the attempt sent valid partial signatures
+func (t *Tendermint) tryAggregate(attempt uint32, finalize *Finalize) *blk.Block {
+	block, known := t.blocks[finalize.BlockHash]
+	if !known || t.aggregated[attempt] {
+		return nil
+	}
+
+	partials := make([]ms.PartialSignatureBytes, len(finalize.Signers))
+	for i, publicKey := range finalize.Signers {
+		partial, exists := t.partials[attempt][publicKey]
+		if !exists || partial.BlockHash != finalize.BlockHash {
+			return nil
+		}
+		partials[i] = partial.Partial
+	}
+	t.aggregated[attempt] = true
+
+	committed := &blk.Block{
+		Header: block.Header,
+		Body:   block.Body,
+	}
+	err := t.blockSigner.AggregateWitness(committed, t.validators, finalize.Signers, finalize.Nonces, partials)
+	if err != nil {
+		logging.Errorf("Witness of attempt %d was not aggregated: %v", attempt, err)
+		return nil
+	}
+
+	return committed
+}
@@ -0,0 +1,53 @@
+// Package logging adds the error level to the standard logger: lines of the node are logged with log or Infof,
+// errors are logged with Errorf and Errorln, so a level keeps or drops lines by the call that logs them
+package logging
+
+import (
+	"fmt"
+	"io"
+	"log"
+	"os"
+)
+
+type Level int
+
+const (
+	Debug Level = iota
+	Info
+	Error
+	Off
+)
+
+var errorLogger = log.New(os.Stderr, "ERROR ", log.LstdFlags|log.Lmsgprefix)
+
+// Setup writes lines of level and above to w, debug adds file and line of the call
+func Setup(level Level, w io.Writer) {
+	flags := log.LstdFlags
+	if level == Debug {
+		flags |= log.Lmicroseconds | log.Lshortfile
+	}
+	log.SetFlags(flags)
+	errorLogger.SetFlags(flags | log.Lmsgprefix)
+
+	log.SetOutput(w)
+	errorLogger.SetOutput(w)
+	if level >= Error {
+		log.SetOutput(io.Discard)
+	}
+	if level >= Off {
+		errorLogger.SetOutput(io.Discard)
+	}
+}
+
+// Infof logs a line of the info level with the standard logger
+func Infof(format string, v ...interface{}) {
+	_ = log.Output(2, fmt.Sprintf(format, v...))
+}
+
+func Errorf(format string, v ...interface{}) {
+	_ = errorLogger.Output(2, fmt.Sprintf(format, v...))
+}
+
+func Errorln(v ...interface{}) {
+	_ = errorLogger.Output(2, fmt.Sprintln(v...))
+}
@@ -0,0 +1,85 @@
+package rejection
+
+import (
+	"errors"
+	"fmt"
+)
+
+// Code identifies why a transaction or a block was rejected, codes are stable so API clients can rely on them
+type Code string
+
+const (
+	// Internal is the code of errors that are not rejections, e.g. failed I/O
+	Internal Code = "internal"
+
+	// Transaction rejections
+	MalformedTransaction Code = "malformed_transaction"
+	Unsigned             Code = "unsigned"
+	PrivateKeySent       Code = "private_key_sent"
+	MissingField         Code = "missing_field"
+	UnknownVoting        Code = "unknown_voting"
+	VotingExpired        Code = "voting_expired"
+	InvalidAnswer        Code = "invalid_answer"
+	NotWhitelisted       Code = "not_whitelisted"
+	WrongRole            Code = "wrong_role"
+	UnknownAccount       Code = "unknown_account"
+	AccountExists        Code = "account_exists"
+	GroupExists          Code = "group_exists"
+	BadSignature         Code = "bad_signature"
+	DuplicateVote        Code = "duplicate_vote"
+	DuplicateKeyImage    Code = "duplicate_key_image"
+	InvalidEvidence      Code = "invalid_evidence"
+	AlreadySlashed       Code = "already_slashed"
+
+	// Admission rejections, the transaction was not verified
+	RateLimited      Code = "rate_limited"
+	InsufficientWork Code = "insufficient_work"
+	MemPoolRejected  Code = "mem_pool_rejected"
+
+	// Block rejections, a block is also rejected with the code of its first invalid transaction
+	WrongPreviousBlock Code = "wrong_previous_block"
+	BadMerkleRoot      Code = "bad_merkle_root"
+	LimitsExceeded     Code = "limits_exceeded"
+	BadWitness         Code = "bad_witness"
+	GenesisOnly        Code = "genesis_only"
+)
+
+// Error is a rejection with its code and a message describing the particular case
+type Error struct {
+	Code    Code   `json:"code"`
+	Message string `json:"message"`
+}
+
+func New(code Code, format string, args ...interface{}) *Error {
+	return &Error{Code: code, Message: fmt.Sprintf(format, args...)}
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("%s: %s", e.Code, e.Message)
+}
+
+// Is matches rejections by code, so errors.Is(err, &Error{Code: code}) holds whatever the message is
+func (e *Error) Is(target error) bool {
+	other, ok := target.(*Error)
+	return ok && other.Code == e.Code
+}
+
+// CodeOf returns the code of the rejection err wraps, Internal if it wraps none and empty code for nil
+func CodeOf(err error) Code {
+	if err == nil {
+		return ""
+	}
+	return FromError(err).Code
+}
+
+// FromError returns the rejection err wraps, errors that are not rejections get the Internal code
+func FromError(err error) *Error {
+	if err == nil {
+		return nil
+	}
+	rejection := &Error{}
+	if !errors.As(err, &rejection) {
+		return &Error{Code: Internal, Message: err.Error()}
+	}
+	return rejection
+}
@@ -0,0 +1,38 @@
+package rejection
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+)
+
+func TestCodeOf(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want Code
+	}{
+		{name: "No error", err: nil, want: ""},
+		{name: "Rejection", err: New(UnknownVoting, "voting %d does not exist", 1), want: UnknownVoting},
+		{name: "Wrapped rejection", err: fmt.Errorf("transaction: %w", New(BadSignature, "invalid")), want: BadSignature},
+		{name: "Other error", err: errors.New("disk is full"), want: Internal},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := CodeOf(tt.err); got != tt.want {
+				t.Errorf("CodeOf() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestError_Is(t *testing.T) {
+	err := fmt.Errorf("block: %w", New(NotWhitelisted, "voter %x is not in the whitelist", [2]byte{1, 2}))
+
+	if !errors.Is(err, &Error{Code: NotWhitelisted}) {
+		t.Errorf("errors.Is() = false for the same code")
+	}
+	if errors.Is(err, &Error{Code: WrongRole}) {
+		t.Errorf("errors.Is() = true for another code")
+	}
+}
@@ -0,0 +1,124 @@
+package hd_keys
+
+import (
+	"crypto/hmac"
+	"crypto/sha512"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"github.com/Digital-Voting-Team/Digital-Voting/pkg/signature/curve"
+	"github.com/Digital-Voting-Team/Digital-Voting/pkg/signature/keys"
+	"strconv"
+	"strings"
+)
+
+// HardenedOffset is added to indexes of hardened children, written as 5' or 5h in paths
+const HardenedOffset uint32 = 1 << 31
+
+// masterKey separates master keys of this chain from BIP32 keys derived from the same seed
+var masterKey = []byte("Digital-Voting seed")
+
+var (
+	ErrNotHardened = errors.New("only hardened derivation is supported")
+	ErrInvalidPath = errors.New("invalid derivation path")
+)
+
+// ExtendedKey is a node of the derivation tree, its seed makes a keys.KeyPair with keys.FromRawSeed.
+// Derivation is hardened only, a KeyPair is derived from its seed by hashing, so children
+// cannot be derived from a public key
+type ExtendedKey struct {
+	seed      [32]byte
+	chainCode [32]byte
+	depth     uint8
+	index     uint32
+}
+
+// NewMaster derives the root of the tree from a raw seed, e.g. one decoded from a mnemonic
+func NewMaster(rawSeed [32]byte) *ExtendedKey {
+	return newExtendedKey(hmacSHA512(masterKey, rawSeed[:]), 0, 0)
+}
+
+func newExtendedKey(digest []byte, depth uint8, index uint32) *ExtendedKey {
+	key := &ExtendedKey{depth: depth, index: index}
+	copy(key.seed[:], digest[:32])
+	copy(key.chainCode[:], digest[32:])
+	return key
+}
+
+// Child derives the hardened child with index, index has to include HardenedOffset
+func (k *ExtendedKey) Child(index uint32) (*ExtendedKey, error) {
+	if index < HardenedOffset {
+		return nil, ErrNotHardened
+	}
+	if k.depth == 255 {
+		return nil, fmt.Errorf("%w: depth over 255", ErrInvalidPath)
+	}
+
+	data := make([]byte, 0, 1+32+4)
+	data = append(data, 0)
+	data = append(data, k.seed[:]...)
+	data = binary.BigEndian.AppendUint32(data, index)
+	return newExtendedKey(hmacSHA512(k.chainCode[:], data), k.depth+1, index), nil
+}
+
+// Derive follows path from k, e.g. m/1'/42' is the key of the 42nd voter of the 1st election
+func (k *ExtendedKey) Derive(path string) (*ExtendedKey, error) {
+	indexes, err := ParsePath(path)
+	if err != nil {
+		return nil, err
+	}
+	key := k
+	for _, index := range indexes {
+		key, err = key.Child(index)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return key, nil
+}
+
+// RawSeed is the seed of the key pair of the node
+func (k *ExtendedKey) RawSeed() [32]byte {
+	return k.seed
+}
+
+func (k *ExtendedKey) Depth() uint8 {
+	return k.depth
+}
+
+func (k *ExtendedKey) Index() uint32 {
+	return k.index
+}
+
+// KeyPair creates the key pair of the node
+func (k *ExtendedKey) KeyPair(curve curve.ICurve) (*keys.KeyPair, error) {
+	return keys.FromRawSeed(k.seed, curve)
+}
+
+// ParsePath parses paths like m/44'/0h/7', every index has to be hardened
+func ParsePath(path string) ([]uint32, error) {
+	segments := strings.Split(strings.TrimSpace(path), "/")
+	if segments[0] != "m" {
+		return nil, fmt.Errorf("%w: %q must start with m", ErrInvalidPath, path)
+	}
+
+	indexes := make([]uint32, 0, len(segments)-1)
+	for _, segment := range segments[1:] {
+		trimmed := strings.TrimRight(segment, "'h")
+		if len(segment)-len(trimmed) != 1 {
+			return nil, fmt.Errorf("%w: %q", ErrNotHardened, segment)
+		}
+		index, err := strconv.ParseUint(trimmed, 10, 31)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %q", ErrInvalidPath, segment)
+		}
+		indexes = append(indexes, uint32(index)+HardenedOffset)
+	}
+	return indexes, nil
+}
+
+func hmacSHA512(key []byte, data []byte) []byte {
+	mac := hmac.New(sha512.New, key)
+	mac.Write(data)
+	return mac.Sum(nil)
+}
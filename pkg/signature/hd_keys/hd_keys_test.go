@@ -0,0 +1,111 @@
+package hd_keys
+
+import (
+	"errors"
+	"github.com/Digital-Voting-Team/Digital-Voting/pkg/signature/curve"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParsePath(t *testing.T) {
+	tests := []struct {
+		name    string
+		path    string
+		want    []uint32
+		wantErr error
+	}{
+		{name: "Master", path: "m", want: []uint32{}},
+		{name: "Hardened indexes", path: "m/1'/42h", want: []uint32{HardenedOffset + 1, HardenedOffset + 42}},
+		{name: "Not hardened", path: "m/1'/42", wantErr: ErrNotHardened},
+		{name: "No master", path: "1'/42'", wantErr: ErrInvalidPath},
+		{name: "Index over range", path: "m/2147483648'", wantErr: ErrInvalidPath},
+		{name: "Empty segment", path: "m//1'", wantErr: ErrNotHardened},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParsePath(tt.path)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("ParsePath() error = %v, want %v", err, tt.wantErr)
+			}
+			if err == nil && !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ParsePath() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestExtendedKey_Derive(t *testing.T) {
+	master := NewMaster([32]byte{1, 2, 3})
+
+	first, err := master.Derive("m/1'/42'")
+	if err != nil {
+		t.Fatalf("Derive() error = %v", err)
+	}
+	again, _ := NewMaster([32]byte{1, 2, 3}).Derive("m/1'/42'")
+	other, _ := master.Derive("m/1'/43'")
+	stepwise, _ := master.Child(HardenedOffset + 1)
+	stepwise, _ = stepwise.Child(HardenedOffset + 42)
+
+	if first.RawSeed() != again.RawSeed() || first.RawSeed() != stepwise.RawSeed() {
+		t.Errorf("derivation of the same path is not reproducible")
+	}
+	if first.RawSeed() == other.RawSeed() || first.RawSeed() == master.RawSeed() {
+		t.Errorf("different paths derive the same key")
+	}
+	if first.Depth() != 2 || first.Index() != HardenedOffset+42 {
+		t.Errorf("Depth(), Index() = %d, %d, want 2, %d", first.Depth(), first.Index(), HardenedOffset+42)
+	}
+	if _, err = master.Child(1); !errors.Is(err, ErrNotHardened) {
+		t.Errorf("Child() error = %v, want %v", err, ErrNotHardened)
+	}
+
+	keyPair, err := first.KeyPair(curve.NewCurve25519())
+	if err != nil || keyPair.PublicToBytes() == [33]byte{} {
+		t.Errorf("KeyPair() = %v, %v", keyPair, err)
+	}
+}
+
+func TestMnemonic(t *testing.T) {
+	rawSeed := [32]byte{9, 8, 7, 6, 5}
+	mnemonic, err := NewMnemonic(rawSeed)
+	if err != nil {
+		t.Fatalf("NewMnemonic() error = %v", err)
+	}
+	words := strings.Fields(mnemonic)
+	if len(words) != 24 {
+		t.Fatalf("NewMnemonic() has %d words, want 24", len(words))
+	}
+	// swapping two different words keeps all words known but breaks the checksum
+	swapped := append([]string{}, words...)
+	for i := 1; i < len(swapped); i++ {
+		if swapped[i] != swapped[0] {
+			swapped[0], swapped[i] = swapped[i], swapped[0]
+			break
+		}
+	}
+
+	tests := []struct {
+		name     string
+		mnemonic string
+		want     [32]byte
+		wantErr  error
+	}{
+		{name: "Round trip", mnemonic: mnemonic, want: rawSeed},
+		{name: "Extra whitespace and case", mnemonic: "  " + strings.ToUpper(strings.Join(words, "\n ")), want: rawSeed},
+		{name: "Wrong checksum", mnemonic: strings.Join(swapped, " "), wantErr: ErrInvalidMnemonic},
+		{name: "Too short", mnemonic: strings.Join(words[:12], " "), wantErr: ErrInvalidMnemonic},
+		{name: "Unknown word", mnemonic: "voting " + strings.Join(words[1:], " "), wantErr: ErrInvalidMnemonic},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := SeedFromMnemonic(tt.mnemonic)
+			if err != tt.wantErr {
+				t.Fatalf("SeedFromMnemonic() error = %v, want %v", err, tt.wantErr)
+			}
+			if err == nil && got != tt.want {
+				t.Errorf("SeedFromMnemonic() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,26 @@
+package hd_keys
+
+import (
+	"errors"
+	"github.com/tyler-smith/go-bip39"
+	"strings"
+)
+
+// ErrInvalidMnemonic is returned for phrases with unknown words, a wrong length or a wrong checksum
+var ErrInvalidMnemonic = errors.New("invalid mnemonic phrase")
+
+// NewMnemonic encodes a raw seed as 24 words of the BIP39 english list, the last word carries a checksum
+func NewMnemonic(rawSeed [32]byte) (string, error) {
+	return bip39.NewMnemonic(rawSeed[:])
+}
+
+// SeedFromMnemonic decodes the raw seed NewMnemonic encoded, words may be separated by any whitespace
+func SeedFromMnemonic(mnemonic string) ([32]byte, error) {
+	rawSeed := [32]byte{}
+	entropy, err := bip39.EntropyFromMnemonic(strings.Join(strings.Fields(strings.ToLower(mnemonic)), " "))
+	if err != nil || len(entropy) != len(rawSeed) {
+		return rawSeed, ErrInvalidMnemonic
+	}
+	copy(rawSeed[:], entropy)
+	return rawSeed, nil
+}
@@ -9,6 +9,7 @@ import (
 	"io"
 	"log"
 	"math/big"
+	"sort"
 )
 
 type PrivateKeyBytes [32]byte
@@ -176,3 +177,13 @@ func (kp *KeyPair) GetKeyImage() *curve2.Point {
 
 	return keyImage
 }
+
+// SortPublicKeys returns a copy of public keys in byte order, the order signers of an aggregate signature are listed in
+func SortPublicKeys(publicKeys []PublicKeyBytes) []PublicKeyBytes {
+	sorted := make([]PublicKeyBytes, len(publicKeys))
+	copy(sorted, publicKeys)
+	sort.Slice(sorted, func(i, j int) bool {
+		return bytes.Compare(sorted[i][:], sorted[j][:]) < 0
+	})
+	return sorted
+}
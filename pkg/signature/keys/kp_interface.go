@@ -41,3 +41,8 @@ func Random(curve curve.ICurve) (*KeyPair, error) {
 func FromRawSeed(rawSeed [32]byte, curve curve.ICurve) (*KeyPair, error) {
 	return newKeyPairFromRawSeed(rawSeed, curve)
 }
+
+// FromSeed creates keys from a strkey encoded seed, the one Seed returns
+func FromSeed(seed string, curve curve.ICurve) (*KeyPair, error) {
+	return newKeyPair(seed, curve)
+}
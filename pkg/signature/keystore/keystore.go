@@ -0,0 +1,324 @@
+package keystore
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/Digital-Voting-Team/Digital-Voting/pkg/signature/curve"
+	"github.com/Digital-Voting-Team/Digital-Voting/pkg/signature/keys"
+	"github.com/Digital-Voting-Team/Digital-Voting/pkg/signature/strkey"
+	"golang.org/x/crypto/scrypt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// Version of the format of key files
+const Version = 1
+
+// Default scrypt parameters take about a second on a laptop
+const (
+	DefaultScryptN = 1 << 18
+	DefaultScryptR = 8
+	DefaultScryptP = 1
+)
+
+const (
+	fileExtension = ".json"
+	saltSize      = 32
+	keySize       = 32
+	// maxScryptMemory bounds the 128·N·r bytes scrypt allocates and maxScryptP the number of times it
+	// runs, so a crafted key file cannot exhaust memory or CPU on Load
+	maxScryptMemory = 1 << 30
+	maxScryptP      = 16
+)
+
+var (
+	ErrNotFound          = errors.New("key not found")
+	ErrExists            = errors.New("key already exists")
+	ErrInvalidName       = errors.New("key name must have from 1 to 64 letters, digits, '-' or '_'")
+	ErrWrongPassphrase   = errors.New("wrong passphrase or corrupted key file")
+	ErrEmptyPassphrase   = errors.New("passphrase must not be empty")
+	ErrNoSeed            = errors.New("key pair has no seed")
+	ErrUnsupportedFormat = errors.New("unsupported key file format")
+)
+
+var namePattern = regexp.MustCompile(`^[A-Za-z0-9_-]{1,64}$`)
+
+// Keystore keeps seeds of key pairs encrypted with a passphrase, one file per named key
+type Keystore struct {
+	dir     string
+	ScryptN int
+	ScryptR int
+	ScryptP int
+}
+
+// Entry is a key of the keystore as listed without its passphrase
+type Entry struct {
+	Name      string
+	PublicKey keys.PublicKeyBytes
+}
+
+type keyFile struct {
+	Version   int        `json:"version"`
+	Name      string     `json:"name"`
+	PublicKey string     `json:"public_key"`
+	Crypto    cryptoData `json:"crypto"`
+}
+
+type cryptoData struct {
+	KDF        string `json:"kdf"`
+	N          int    `json:"n"`
+	R          int    `json:"r"`
+	P          int    `json:"p"`
+	Salt       string `json:"salt"`
+	Cipher     string `json:"cipher"`
+	Nonce      string `json:"nonce"`
+	Ciphertext string `json:"ciphertext"`
+}
+
+// Open creates dir if it does not exist and returns the keystore kept in it
+func Open(dir string) (*Keystore, error) {
+	err := os.MkdirAll(dir, 0o700)
+	if err != nil {
+		return nil, err
+	}
+	return &Keystore{
+		dir:     dir,
+		ScryptN: DefaultScryptN,
+		ScryptR: DefaultScryptR,
+		ScryptP: DefaultScryptP,
+	}, nil
+}
+
+func (ks *Keystore) path(name string) (string, error) {
+	if !namePattern.MatchString(name) {
+		return "", ErrInvalidName
+	}
+	return filepath.Join(ks.dir, name+fileExtension), nil
+}
+
+// Exists reports whether a key with name is in the keystore
+func (ks *Keystore) Exists(name string) bool {
+	path, err := ks.path(name)
+	if err != nil {
+		return false
+	}
+	_, err = os.Stat(path)
+	return err == nil
+}
+
+// Save encrypts the seed of keyPair with passphrase and stores it as name, existing keys are not overwritten
+func (ks *Keystore) Save(name string, keyPair *keys.KeyPair, passphrase string) error {
+	path, err := ks.path(name)
+	if err != nil {
+		return err
+	}
+	if passphrase == "" {
+		return ErrEmptyPassphrase
+	}
+	if keyPair.Seed() == "" {
+		return ErrNoSeed
+	}
+	rawSeed, err := strkey.Decode(strkey.VersionByteSeed, keyPair.Seed())
+	if err != nil {
+		return err
+	}
+
+	salt := make([]byte, saltSize)
+	_, err = io.ReadFull(rand.Reader, salt)
+	if err != nil {
+		return err
+	}
+	aead, err := ks.newAEAD(passphrase, salt, ks.ScryptN, ks.ScryptR, ks.ScryptP)
+	if err != nil {
+		return err
+	}
+	nonce := make([]byte, aead.NonceSize())
+	_, err = io.ReadFull(rand.Reader, nonce)
+	if err != nil {
+		return err
+	}
+	publicKey := keyPair.PublicToBytes()
+
+	data, err := json.MarshalIndent(keyFile{
+		Version:   Version,
+		Name:      name,
+		PublicKey: hex.EncodeToString(publicKey[:]),
+		Crypto: cryptoData{
+			KDF:        "scrypt",
+			N:          ks.ScryptN,
+			R:          ks.ScryptR,
+			P:          ks.ScryptP,
+			Salt:       hex.EncodeToString(salt),
+			Cipher:     "aes-256-gcm",
+			Nonce:      hex.EncodeToString(nonce),
+			Ciphertext: hex.EncodeToString(aead.Seal(nil, nonce, rawSeed, publicKey[:])),
+		},
+	}, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	// O_EXCL keeps a key from being replaced by a concurrent Save
+	file, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o600)
+	if os.IsExist(err) {
+		return fmt.Errorf("%s: %w", name, ErrExists)
+	}
+	if err != nil {
+		return err
+	}
+	_, err = file.Write(data)
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		_ = os.Remove(path)
+	}
+	return err
+}
+
+// Load decrypts the key stored as name
+func (ks *Keystore) Load(name string, passphrase string, curve curve.ICurve) (*keys.KeyPair, error) {
+	stored, err := ks.read(name)
+	if err != nil {
+		return nil, err
+	}
+	if stored.Crypto.KDF != "scrypt" || stored.Crypto.Cipher != "aes-256-gcm" || !validScryptParams(stored.Crypto.N, stored.Crypto.R, stored.Crypto.P) {
+		return nil, ErrUnsupportedFormat
+	}
+
+	publicKey, err := hex.DecodeString(stored.PublicKey)
+	if err != nil {
+		return nil, ErrWrongPassphrase
+	}
+	salt, err := hex.DecodeString(stored.Crypto.Salt)
+	if err != nil {
+		return nil, ErrWrongPassphrase
+	}
+	nonce, err := hex.DecodeString(stored.Crypto.Nonce)
+	if err != nil {
+		return nil, ErrWrongPassphrase
+	}
+	ciphertext, err := hex.DecodeString(stored.Crypto.Ciphertext)
+	if err != nil {
+		return nil, ErrWrongPassphrase
+	}
+
+	aead, err := ks.newAEAD(passphrase, salt, stored.Crypto.N, stored.Crypto.R, stored.Crypto.P)
+	if err != nil {
+		return nil, err
+	}
+	if len(nonce) != aead.NonceSize() {
+		return nil, ErrWrongPassphrase
+	}
+	rawSeed, err := aead.Open(nil, nonce, ciphertext, publicKey)
+	if err != nil || len(rawSeed) != 32 {
+		return nil, ErrWrongPassphrase
+	}
+
+	seed := [32]byte{}
+	copy(seed[:], rawSeed)
+	keyPair, err := keys.FromRawSeed(seed, curve)
+	if err != nil {
+		return nil, err
+	}
+	derived := keyPair.PublicToBytes()
+	if hex.EncodeToString(derived[:]) != strings.ToLower(stored.PublicKey) {
+		return nil, ErrWrongPassphrase
+	}
+	return keyPair, nil
+}
+
+// List returns keys of the keystore ordered by name
+func (ks *Keystore) List() ([]Entry, error) {
+	files, err := os.ReadDir(ks.dir)
+	if err != nil {
+		return nil, err
+	}
+
+	entries := []Entry{}
+	for _, file := range files {
+		name := strings.TrimSuffix(file.Name(), fileExtension)
+		if file.IsDir() || name == file.Name() || !namePattern.MatchString(name) {
+			continue
+		}
+		stored, err := ks.read(name)
+		if err != nil {
+			return nil, err
+		}
+		entry := Entry{Name: name}
+		publicKey, err := hex.DecodeString(stored.PublicKey)
+		if err != nil || len(publicKey) != len(entry.PublicKey) {
+			return nil, fmt.Errorf("%s: invalid public key", name)
+		}
+		copy(entry.PublicKey[:], publicKey)
+		entries = append(entries, entry)
+	}
+
+	sort.Slice(entries, func(i, j int) bool {
+		return entries[i].Name < entries[j].Name
+	})
+	return entries, nil
+}
+
+// Delete removes the key stored as name
+func (ks *Keystore) Delete(name string) error {
+	path, err := ks.path(name)
+	if err != nil {
+		return err
+	}
+	err = os.Remove(path)
+	if os.IsNotExist(err) {
+		return fmt.Errorf("%s: %w", name, ErrNotFound)
+	}
+	return err
+}
+
+// validScryptParams checks parameters against the limits, r is divided rather than multiplied so it cannot overflow
+func validScryptParams(n, r, p int) bool {
+	return n > 1 && r > 0 && p > 0 && p <= maxScryptP && r <= maxScryptMemory/128/n
+}
+
+func (ks *Keystore) read(name string) (keyFile, error) {
+	path, err := ks.path(name)
+	if err != nil {
+		return keyFile{}, err
+	}
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return keyFile{}, fmt.Errorf("%s: %w", name, ErrNotFound)
+	}
+	if err != nil {
+		return keyFile{}, err
+	}
+
+	stored := keyFile{}
+	err = json.Unmarshal(data, &stored)
+	if err != nil {
+		return keyFile{}, fmt.Errorf("%s: %w", name, err)
+	}
+	if stored.Version != Version {
+		return keyFile{}, fmt.Errorf("%s: %w", name, ErrUnsupportedFormat)
+	}
+	return stored, nil
+}
+
+func (ks *Keystore) newAEAD(passphrase string, salt []byte, n, r, p int) (cipher.AEAD, error) {
+	key, err := scrypt.Key([]byte(passphrase), salt, n, r, p, keySize)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrUnsupportedFormat, err)
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
@@ -0,0 +1,157 @@
+package keystore
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"github.com/Digital-Voting-Team/Digital-Voting/pkg/signature/curve"
+	"github.com/Digital-Voting-Team/Digital-Voting/pkg/signature/keys"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func newTestKeystore(t *testing.T) *Keystore {
+	ks, err := Open(t.TempDir())
+	if err != nil {
+		t.Fatalf("Open() error = %v", err)
+	}
+	// cheap parameters keep tests fast
+	ks.ScryptN = 1 << 10
+	return ks
+}
+
+func TestKeystore_SaveLoad(t *testing.T) {
+	ks := newTestKeystore(t)
+	keyPair, _ := keys.Random(curve.NewCurve25519())
+	if err := ks.Save("validator", keyPair, "secret"); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+
+	tests := []struct {
+		name       string
+		keyName    string
+		passphrase string
+		wantErr    error
+	}{
+		{name: "Correct passphrase", keyName: "validator", passphrase: "secret"},
+		{name: "Wrong passphrase", keyName: "validator", passphrase: "guess", wantErr: ErrWrongPassphrase},
+		{name: "Missing key", keyName: "other", passphrase: "secret", wantErr: ErrNotFound},
+		{name: "Path in name", keyName: "../validator", passphrase: "secret", wantErr: ErrInvalidName},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			loaded, err := ks.Load(tt.keyName, tt.passphrase, curve.NewCurve25519())
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Load() error = %v, want %v", err, tt.wantErr)
+			}
+			if err == nil && !loaded.Equal(keyPair) {
+				t.Errorf("Load() = %v, want %v", loaded.Seed(), keyPair.Seed())
+			}
+		})
+	}
+}
+
+func TestKeystore_Save(t *testing.T) {
+	ks := newTestKeystore(t)
+	keyPair, _ := keys.Random(curve.NewCurve25519())
+	_ = ks.Save("wallet", keyPair, "secret")
+
+	tests := []struct {
+		name       string
+		keyName    string
+		keyPair    *keys.KeyPair
+		passphrase string
+		wantErr    error
+	}{
+		{name: "Existing key is kept", keyName: "wallet", keyPair: keyPair, passphrase: "secret", wantErr: ErrExists},
+		{name: "Empty passphrase", keyName: "new", keyPair: keyPair, passphrase: "", wantErr: ErrEmptyPassphrase},
+		{name: "Key pair without seed", keyName: "new", keyPair: keys.FromPrivateKey(keys.PrivateKeyBytes{1}, curve.NewCurve25519()), passphrase: "secret", wantErr: ErrNoSeed},
+		{name: "Invalid name", keyName: "", keyPair: keyPair, passphrase: "secret", wantErr: ErrInvalidName},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := ks.Save(tt.keyName, tt.keyPair, tt.passphrase); !errors.Is(err, tt.wantErr) {
+				t.Errorf("Save() error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestKeystore_Tampered(t *testing.T) {
+	ks := newTestKeystore(t)
+	keyPair, _ := keys.Random(curve.NewCurve25519())
+	other, _ := keys.Random(curve.NewCurve25519())
+	_ = ks.Save("wallet", keyPair, "secret")
+
+	path := filepath.Join(ks.dir, "wallet.json")
+	data, _ := os.ReadFile(path)
+	original, replaced := keyPair.PublicToBytes(), other.PublicToBytes()
+	data = []byte(strings.Replace(string(data), hex.EncodeToString(original[:]), hex.EncodeToString(replaced[:]), 1))
+	_ = os.WriteFile(path, data, 0o600)
+
+	if _, err := ks.Load("wallet", "secret", curve.NewCurve25519()); !errors.Is(err, ErrWrongPassphrase) {
+		t.Errorf("Load() error = %v, want %v", err, ErrWrongPassphrase)
+	}
+}
+
+func TestKeystore_ScryptLimits(t *testing.T) {
+	tests := []struct {
+		name    string
+		n, r, p int
+		wantErr error
+	}{
+		{name: "Within limits", n: 1 << 10, r: 8, p: 1, wantErr: nil},
+		{name: "Too much memory through N", n: 1 << 30, r: 8, p: 1, wantErr: ErrUnsupportedFormat},
+		{name: "Too much memory through r", n: 1 << 10, r: 1 << 20, p: 1, wantErr: ErrUnsupportedFormat},
+		{name: "Too many runs", n: 1 << 10, r: 8, p: 1 << 20, wantErr: ErrUnsupportedFormat},
+		{name: "Zero r", n: 1 << 10, r: 0, p: 1, wantErr: ErrUnsupportedFormat},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ks := newTestKeystore(t)
+			keyPair, _ := keys.Random(curve.NewCurve25519())
+			_ = ks.Save("wallet", keyPair, "secret")
+
+			// parameters are replaced in the saved file, scrypt must not even start with the crafted ones
+			path := filepath.Join(ks.dir, "wallet.json")
+			data, _ := os.ReadFile(path)
+			stored := keyFile{}
+			_ = json.Unmarshal(data, &stored)
+			stored.Crypto.N, stored.Crypto.R, stored.Crypto.P = tt.n, tt.r, tt.p
+			data, _ = json.Marshal(stored)
+			_ = os.WriteFile(path, data, 0o600)
+
+			if _, err := ks.Load("wallet", "secret", curve.NewCurve25519()); !errors.Is(err, tt.wantErr) {
+				t.Errorf("Load() error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestKeystore_ListDelete(t *testing.T) {
+	ks := newTestKeystore(t)
+	first, _ := keys.Random(curve.NewCurve25519())
+	second, _ := keys.Random(curve.NewCurve25519())
+	_ = ks.Save("second", second, "secret")
+	_ = ks.Save("first", first, "other secret")
+
+	entries, err := ks.List()
+	if err != nil {
+		t.Fatalf("List() error = %v", err)
+	}
+	if len(entries) != 2 || entries[0].Name != "first" || entries[0].PublicKey != first.PublicToBytes() {
+		t.Errorf("List() = %v, want first and second ordered by name", entries)
+	}
+
+	if err = ks.Delete("first"); err != nil {
+		t.Fatalf("Delete() error = %v", err)
+	}
+	if ks.Exists("first") || !ks.Exists("second") {
+		t.Errorf("Delete() removed a wrong key")
+	}
+	if err = ks.Delete("first"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Delete() error = %v, want %v", err, ErrNotFound)
+	}
+}
@@ -0,0 +1,334 @@
+package signatures
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	crv "github.com/Digital-Voting-Team/Digital-Voting/pkg/signature/curve"
+	"github.com/Digital-Voting-Team/Digital-Voting/pkg/signature/keys"
+	"github.com/Digital-Voting-Team/Digital-Voting/pkg/signature/signatures/utils"
+	"math/big"
+)
+
+// MuSig is a two-round Schnorr multi-signature, n signers produce one signature
+// verifiable against their aggregated public key.
+// https://eprint.iacr.org/2020/1261.pdf
+type MuSig struct {
+	GenPoint *crv.Point
+	Curve    *crv.MontgomeryCurve
+}
+
+func NewMuSig() *MuSig {
+	curve := crv.NewCurve25519()
+	return &MuSig{
+		GenPoint: curve.G(),
+		Curve:    curve,
+	}
+}
+
+// ErrPointAtInfinity rejects a session whose aggregate key or nonce is the point at infinity
+var ErrPointAtInfinity = errors.New("aggregate point is at infinity")
+
+// NonceBytes holds two compressed nonce points of a signer
+type NonceBytes [66]byte
+
+// PartialSignatureBytes is the share of a single signer
+type PartialSignatureBytes [32]byte
+
+// AggregateSignatureBytes holds compressed R and s of the aggregate signature
+type AggregateSignatureBytes [65]byte
+
+// SecretNonce must be used for a single partial signature only, reusing it reveals the private key
+type SecretNonce struct {
+	R1 *big.Int
+	R2 *big.Int
+}
+
+type PublicNonce struct {
+	R1 *crv.Point
+	R2 *crv.Point
+}
+
+type AggregateSignature struct {
+	R *crv.Point
+	S *big.Int
+}
+
+func (pn *PublicNonce) NonceToBytes() NonceBytes {
+	result := NonceBytes{}
+	r1 := pn.R1.PointToBytes()
+	r2 := pn.R2.PointToBytes()
+	copy(result[:33], r1[:])
+	copy(result[33:], r2[:])
+	return result
+}
+
+func (as *AggregateSignature) SignatureToBytes() AggregateSignatureBytes {
+	result := AggregateSignatureBytes{}
+	r := as.R.PointToBytes()
+	copy(result[:33], r[:])
+	as.S.FillBytes(result[33:])
+	return result
+}
+
+func PartialToBytes(partial *big.Int) PartialSignatureBytes {
+	result := PartialSignatureBytes{}
+	partial.FillBytes(result[:])
+	return result
+}
+
+func (ms *MuSig) BytesToNonce(data NonceBytes) (*PublicNonce, error) {
+	r1, err := ms.bytesToPoint(data[:33])
+	if err != nil {
+		return nil, err
+	}
+	r2, err := ms.bytesToPoint(data[33:])
+	if err != nil {
+		return nil, err
+	}
+	return &PublicNonce{R1: r1, R2: r2}, nil
+}
+
+func (ms *MuSig) BytesToSignature(data AggregateSignatureBytes) (*AggregateSignature, error) {
+	r, err := ms.bytesToPoint(data[:33])
+	if err != nil {
+		return nil, err
+	}
+	return &AggregateSignature{R: r, S: new(big.Int).SetBytes(data[33:])}, nil
+}
+
+func (ms *MuSig) bytesToPoint(data []byte) (*crv.Point, error) {
+	compressed := crv.PointCompressed{}
+	copy(compressed[:], data)
+	point := crv.BytesToPoint(compressed, ms.Curve)
+	if point.Y == nil || !ms.Curve.IsOnCurve(point) {
+		return nil, errors.New("the point is not on the curve")
+	}
+	return point, nil
+}
+
+// GenerateNonce creates the first round message of a signer
+func (ms *MuSig) GenerateNonce() (*SecretNonce, *PublicNonce, error) {
+	r1, err := rand.Int(rand.Reader, ms.Curve.N)
+	if err != nil {
+		return nil, nil, err
+	}
+	r2, err := rand.Int(rand.Reader, ms.Curve.N)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return &SecretNonce{R1: r1, R2: r2}, &PublicNonce{
+		R1: ms.GenPoint.Mul(utils.Clone(r1)),
+		R2: ms.GenPoint.Mul(utils.Clone(r2)),
+	}, nil
+}
+
+// AggregateKey computes the aggregated public key and the coefficient of every key,
+// coefficients prevent a signer from cancelling keys of the others
+func (ms *MuSig) AggregateKey(publicKeys []*crv.Point) (*crv.Point, []*big.Int) {
+	encodedKeys := make([][]byte, len(publicKeys))
+	for i, publicKey := range publicKeys {
+		compressed := publicKey.PointToBytes()
+		encodedKeys[i] = compressed[:]
+	}
+	keysHash := ms.hashToScalar("KeyAgg list", encodedKeys...).Bytes()
+
+	aggregateKey := ms.Curve.INF()
+	coefficients := make([]*big.Int, len(publicKeys))
+	for i, publicKey := range publicKeys {
+		coefficients[i] = ms.hashToScalar("KeyAgg coefficient", keysHash, encodedKeys[i])
+		aggregateKey = ms.add(aggregateKey, publicKey.Mul(utils.Clone(coefficients[i])))
+	}
+
+	return aggregateKey, coefficients
+}
+
+// Session is a signing session of a fixed message by a fixed ordered set of signers
+type Session struct {
+	ms           *MuSig
+	PublicKeys   []*crv.Point
+	Nonces       []*PublicNonce
+	Coefficients []*big.Int
+	AggregateKey *crv.Point
+	R            *crv.Point
+	b            *big.Int
+	c            *big.Int
+}
+
+func (ms *MuSig) NewSessionBytes(message string, publicKeys []keys.PublicKeyBytes, nonces []NonceBytes) (*Session, error) {
+	if len(publicKeys) == 0 || len(publicKeys) != len(nonces) {
+		return nil, fmt.Errorf("got %d public keys and %d nonces", len(publicKeys), len(nonces))
+	}
+
+	points := make([]*crv.Point, len(publicKeys))
+	publicNonces := make([]*PublicNonce, len(nonces))
+	for i := range publicKeys {
+		point, err := ms.bytesToPoint(publicKeys[i][:])
+		if err != nil {
+			return nil, err
+		}
+		points[i] = point
+
+		publicNonces[i], err = ms.BytesToNonce(nonces[i])
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return ms.NewSession(message, points, publicNonces)
+}
+
+// NewSession fails if nonces or keys of the signers cancel each other out, since nonces come from
+// other signers the aggregate points may be at infinity which has no encoding
+func (ms *MuSig) NewSession(message string, publicKeys []*crv.Point, nonces []*PublicNonce) (*Session, error) {
+	aggregateKey, coefficients := ms.AggregateKey(publicKeys)
+	if aggregateKey.IsAtInfinity() {
+		return nil, ErrPointAtInfinity
+	}
+
+	r1, r2 := ms.Curve.INF(), ms.Curve.INF()
+	for _, nonce := range nonces {
+		r1 = ms.add(r1, nonce.R1)
+		r2 = ms.add(r2, nonce.R2)
+	}
+	if r1.IsAtInfinity() || r2.IsAtInfinity() {
+		return nil, ErrPointAtInfinity
+	}
+
+	aggregateKeyBytes := aggregateKey.PointToBytes()
+	r1Bytes, r2Bytes := r1.PointToBytes(), r2.PointToBytes()
+	b := ms.hashToScalar("MuSig nonce", aggregateKeyBytes[:], r1Bytes[:], r2Bytes[:], []byte(message))
+	r := ms.add(r1, ms.mul(b, r2))
+	if r.IsAtInfinity() {
+		return nil, ErrPointAtInfinity
+	}
+
+	return &Session{
+		ms:           ms,
+		PublicKeys:   publicKeys,
+		Nonces:       nonces,
+		Coefficients: coefficients,
+		AggregateKey: aggregateKey,
+		R:            r,
+		b:            b,
+		c:            ms.challenge(aggregateKey, r, message),
+	}, nil
+}
+
+// Sign produces the partial signature of the signer with the given index
+func (s *Session) Sign(index int, privateKey *big.Int, secretNonce *SecretNonce) *big.Int {
+	n := s.ms.Curve.N
+	partial := new(big.Int).Mul(s.b, secretNonce.R2)
+	partial.Add(partial, secretNonce.R1)
+	partial.Add(partial, new(big.Int).Mul(new(big.Int).Mul(s.c, s.Coefficients[index]), privateKey))
+	return partial.Mod(partial, n)
+}
+
+// VerifyPartial checks s_i * G == R1_i + b * R2_i + c * a_i * P_i
+func (s *Session) VerifyPartial(index int, partial PartialSignatureBytes) bool {
+	if index < 0 || index >= len(s.PublicKeys) {
+		return false
+	}
+	partialInt := new(big.Int).SetBytes(partial[:])
+	if partialInt.Cmp(s.ms.Curve.N) >= 0 {
+		return false
+	}
+
+	left := s.ms.mul(partialInt, s.ms.GenPoint)
+	right := s.ms.add(s.Nonces[index].R1, s.ms.mul(s.b, s.Nonces[index].R2))
+	right = s.ms.add(right, s.ms.mul(new(big.Int).Mul(s.c, s.Coefficients[index]), s.PublicKeys[index]))
+
+	return pointsEqual(left, right)
+}
+
+// Aggregate sums partial signatures of all signers of the session
+func (s *Session) Aggregate(partials []PartialSignatureBytes) *AggregateSignature {
+	sum := new(big.Int)
+	for _, partial := range partials {
+		sum.Add(sum, new(big.Int).SetBytes(partial[:]))
+	}
+	return &AggregateSignature{R: s.R, S: sum.Mod(sum, s.ms.Curve.N)}
+}
+
+func (ms *MuSig) VerifyBytes(message string, publicKeys []keys.PublicKeyBytes, signature AggregateSignatureBytes) bool {
+	if len(publicKeys) == 0 {
+		return false
+	}
+
+	points := make([]*crv.Point, len(publicKeys))
+	for i, publicKey := range publicKeys {
+		point, err := ms.bytesToPoint(publicKey[:])
+		if err != nil {
+			return false
+		}
+		points[i] = point
+	}
+
+	sig, err := ms.BytesToSignature(signature)
+	if err != nil {
+		return false
+	}
+
+	aggregateKey, _ := ms.AggregateKey(points)
+	if aggregateKey.IsAtInfinity() {
+		return false
+	}
+	return ms.Verify(message, aggregateKey, sig)
+}
+
+// Verify checks s * G == R + c * X, X being the aggregated key
+func (ms *MuSig) Verify(message string, aggregateKey *crv.Point, signature *AggregateSignature) bool {
+	if !utils.CheckInterval(signature.S, utils.GetInt(1), new(big.Int).Sub(ms.Curve.N, utils.GetInt(1))) {
+		return false
+	}
+
+	c := ms.challenge(aggregateKey, signature.R, message)
+	left := ms.mul(signature.S, ms.GenPoint)
+	right := ms.add(signature.R, ms.mul(c, aggregateKey))
+
+	return pointsEqual(left, right)
+}
+
+func (ms *MuSig) challenge(aggregateKey, r *crv.Point, message string) *big.Int {
+	aggregateKeyBytes, rBytes := aggregateKey.PointToBytes(), r.PointToBytes()
+	return ms.hashToScalar("MuSig challenge", rBytes[:], aggregateKeyBytes[:], []byte(message))
+}
+
+func (ms *MuSig) hashToScalar(tag string, parts ...[]byte) *big.Int {
+	h := sha256.New()
+	h.Write([]byte(tag))
+	for _, part := range parts {
+		h.Write(part)
+	}
+	return new(big.Int).Mod(new(big.Int).SetBytes(h.Sum(nil)), ms.Curve.N)
+}
+
+// add handles the cases curve addition does not: the point at infinity, doubling and opposite points
+func (ms *MuSig) add(p, q *crv.Point) *crv.Point {
+	if p.IsAtInfinity() {
+		return q
+	}
+	if q.IsAtInfinity() {
+		return p
+	}
+	if p.X.Cmp(q.X) == 0 {
+		if p.Y.Cmp(q.Y) == 0 {
+			return p.Mul(utils.GetInt(2))
+		}
+		return ms.Curve.INF()
+	}
+	return p.Add(q)
+}
+
+func (ms *MuSig) mul(scalar *big.Int, p *crv.Point) *crv.Point {
+	return p.Mul(new(big.Int).Mod(scalar, ms.Curve.N))
+}
+
+func pointsEqual(p, q *crv.Point) bool {
+	if p.IsAtInfinity() || q.IsAtInfinity() {
+		return p.IsAtInfinity() && q.IsAtInfinity()
+	}
+	return p.Eq(q)
+}
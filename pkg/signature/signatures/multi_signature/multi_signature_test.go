@@ -0,0 +1,145 @@
+package signatures
+
+import (
+	"github.com/Digital-Voting-Team/Digital-Voting/pkg/signature/keys"
+	"log"
+	"testing"
+)
+
+func signMessage(ms *MuSig, message string, keyPairs []*keys.KeyPair) ([]keys.PublicKeyBytes, *Session, []PartialSignatureBytes) {
+	publicKeys := make([]keys.PublicKeyBytes, len(keyPairs))
+	secretNonces := make([]*SecretNonce, len(keyPairs))
+	publicNonces := make([]NonceBytes, len(keyPairs))
+	for i, keyPair := range keyPairs {
+		publicKeys[i] = keyPair.PublicToBytes()
+		secretNonce, publicNonce, err := ms.GenerateNonce()
+		if err != nil {
+			log.Panicln(err)
+		}
+		secretNonces[i] = secretNonce
+		publicNonces[i] = publicNonce.NonceToBytes()
+	}
+
+	session, err := ms.NewSessionBytes(message, publicKeys, publicNonces)
+	if err != nil {
+		log.Panicln(err)
+	}
+
+	partials := make([]PartialSignatureBytes, len(keyPairs))
+	for i, keyPair := range keyPairs {
+		partials[i] = PartialToBytes(session.Sign(i, keyPair.GetPrivateKey(), secretNonces[i]))
+	}
+
+	return publicKeys, session, partials
+}
+
+func TestVerifyBytes(t *testing.T) {
+	ms := NewMuSig()
+	keyPairs := make([]*keys.KeyPair, 3)
+	for i := range keyPairs {
+		keyPair, err := keys.Random(ms.Curve)
+		if err != nil {
+			log.Panicln(err)
+		}
+		keyPairs[i] = keyPair
+	}
+
+	message := "String ...."
+	publicKeys, session, partials := signMessage(ms, message, keyPairs)
+	signature := session.Aggregate(partials).SignatureToBytes()
+
+	for i, partial := range partials {
+		if !session.VerifyPartial(i, partial) {
+			t.Errorf("partial signature %d must be valid", i)
+		}
+	}
+	if session.VerifyPartial(0, partials[1]) {
+		t.Errorf("partial signature of another signer must be invalid")
+	}
+
+	tests := []struct {
+		name       string
+		message    string
+		publicKeys []keys.PublicKeyBytes
+		want       bool
+	}{
+		{
+			name:       "Should verify (all signers, correct message)",
+			message:    message,
+			publicKeys: publicKeys,
+			want:       true,
+		},
+		{
+			name:       "Should not verify (all signers, wrong message)",
+			message:    message + "1",
+			publicKeys: publicKeys,
+			want:       false,
+		},
+		{
+			name:       "Should not verify (missing signer)",
+			message:    message,
+			publicKeys: publicKeys[:2],
+			want:       false,
+		},
+		{
+			name:       "Should not verify (different order of signers)",
+			message:    message,
+			publicKeys: []keys.PublicKeyBytes{publicKeys[1], publicKeys[0], publicKeys[2]},
+			want:       false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ms.VerifyBytes(tt.message, tt.publicKeys, signature); got != tt.want {
+				t.Errorf("VerifyBytes() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNewSessionBytes_Infinity(t *testing.T) {
+	ms := NewMuSig()
+	publicKeys := make([]keys.PublicKeyBytes, 2)
+	for i := range publicKeys {
+		keyPair, err := keys.Random(ms.Curve)
+		if err != nil {
+			log.Panicln(err)
+		}
+		publicKeys[i] = keyPair.PublicToBytes()
+	}
+	_, nonce, err := ms.GenerateNonce()
+	if err != nil {
+		log.Panicln(err)
+	}
+	_, other, err := ms.GenerateNonce()
+	if err != nil {
+		log.Panicln(err)
+	}
+
+	tests := []struct {
+		name   string
+		nonces []NonceBytes
+	}{
+		{
+			name: "Cancelling nonces",
+			nonces: []NonceBytes{
+				nonce.NonceToBytes(),
+				(&PublicNonce{R1: nonce.R1.Neg(), R2: nonce.R2.Neg()}).NonceToBytes(),
+			},
+		},
+		{
+			name: "Cancelling first nonces",
+			nonces: []NonceBytes{
+				nonce.NonceToBytes(),
+				(&PublicNonce{R1: nonce.R1.Neg(), R2: other.R2}).NonceToBytes(),
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := ms.NewSessionBytes("message", publicKeys, tt.nonces); err != ErrPointAtInfinity {
+				t.Errorf("NewSessionBytes() error = %v, want %v", err, ErrPointAtInfinity)
+			}
+		})
+	}
+}
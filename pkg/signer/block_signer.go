@@ -1,29 +1,143 @@
 package signer
 
 import (
+	"fmt"
 	blk "github.com/Digital-Voting-Team/Digital-Voting/pkg/blockchain/block"
 	"github.com/Digital-Voting-Team/Digital-Voting/pkg/signature/keys"
+	ms "github.com/Digital-Voting-Team/Digital-Voting/pkg/signature/signatures/multi_signature"
 	ss "github.com/Digital-Voting-Team/Digital-Voting/pkg/signature/signatures/single_signature"
+	"sync"
+	"time"
 )
 
+// NonceLifetime bounds how long a nonce waits for the signing request of its session
+const NonceLifetime = time.Minute
+
+type pendingNonce struct {
+	secret  *ms.SecretNonce
+	public  ms.NonceBytes
+	created time.Time
+}
+
+// BlockSigner takes part in aggregate signing of block witnesses,
+// it keeps secret nonces of open sessions until they are used
 type BlockSigner struct {
-	BlkSigner *ss.ECDSA
+	BlkSigner   *ss.ECDSA
+	MultiSigner *ms.MuSig
+
+	nonces map[string]pendingNonce
+	mutex  sync.Mutex
 }
 
 func NewBlockSigner() *BlockSigner {
-	return &BlockSigner{BlkSigner: ss.NewECDSA()}
+	return &BlockSigner{
+		BlkSigner:   ss.NewECDSA(),
+		MultiSigner: ms.NewMuSig(),
+		nonces:      map[string]pendingNonce{},
+	}
+}
+
+// CreateNonce generates the nonce of this signer for the session, the first round of signing
+func (bs *BlockSigner) CreateNonce(session string) (ms.NonceBytes, error) {
+	secret, public, err := bs.MultiSigner.GenerateNonce()
+	if err != nil {
+		return ms.NonceBytes{}, err
+	}
+
+	bs.mutex.Lock()
+	defer bs.mutex.Unlock()
+	for id, nonce := range bs.nonces {
+		if time.Since(nonce.created) > NonceLifetime {
+			delete(bs.nonces, id)
+		}
+	}
+	bs.nonces[session] = pendingNonce{secret: secret, public: public.NonceToBytes(), created: time.Now()}
+
+	return bs.nonces[session].public, nil
+}
+
+// SignPartial produces the share of this signer, the second round of signing.
+// The nonce of the session is removed first so it is never used for two signatures
+func (bs *BlockSigner) SignPartial(
+	keyPair *keys.KeyPair,
+	session string,
+	block *blk.Block,
+	signers []keys.PublicKeyBytes,
+	nonces []ms.NonceBytes,
+) (ms.PartialSignatureBytes, error) {
+	bs.mutex.Lock()
+	nonce, exists := bs.nonces[session]
+	delete(bs.nonces, session)
+	bs.mutex.Unlock()
+	if !exists {
+		return ms.PartialSignatureBytes{}, fmt.Errorf("no nonce for session %s", session)
+	}
+
+	index := -1
+	for i, signer := range signers {
+		if signer == keyPair.PublicToBytes() {
+			index = i
+			break
+		}
+	}
+	if index == -1 || index >= len(nonces) || nonces[index] != nonce.public {
+		return ms.PartialSignatureBytes{}, fmt.Errorf("signing request does not contain nonce of the signer")
+	}
+
+	signingSession, err := bs.MultiSigner.NewSessionBytes(block.GetHashString(), signers, nonces)
+	if err != nil {
+		return ms.PartialSignatureBytes{}, err
+	}
+
+	return ms.PartialToBytes(signingSession.Sign(index, keyPair.GetPrivateKey(), nonce.secret)), nil
 }
 
-func (bs *BlockSigner) SignBlock(keyPair *keys.KeyPair, block *blk.Block) (keys.PublicKeyBytes, ss.SingleSignatureBytes) {
-	privateKey := keyPair.GetPrivateKey()
-	publicKey := keyPair.GetPublicKey()
-	messageToSign := block.GetHashString()
+// AggregateWitness checks partial signatures of the signers and sets the aggregate as the block witness
+func (bs *BlockSigner) AggregateWitness(
+	block *blk.Block,
+	validators []keys.PublicKeyBytes,
+	signers []keys.PublicKeyBytes,
+	nonces []ms.NonceBytes,
+	partials []ms.PartialSignatureBytes,
+) error {
+	if len(partials) != len(signers) {
+		return fmt.Errorf("got %d partial signatures for %d signers", len(partials), len(signers))
+	}
+
+	signingSession, err := bs.MultiSigner.NewSessionBytes(block.GetHashString(), signers, nonces)
+	if err != nil {
+		return err
+	}
 
-	edwardsSignature := bs.BlkSigner.SignEdDSA(messageToSign, privateKey, publicKey)
-	signature := bs.BlkSigner.EdwardsToSingleSignature(edwardsSignature)
-	return keys.PublicKeyBytes(publicKey.PointToBytes()), signature.EdwardsSignatureToBytes()
+	for i, partial := range partials {
+		if !signingSession.VerifyPartial(i, partial) {
+			return fmt.Errorf("invalid partial signature of signer %d", i)
+		}
+	}
+
+	witness, err := blk.NewWitness(validators, signers, signingSession.Aggregate(partials).SignatureToBytes())
+	if err != nil {
+		return err
+	}
+	block.SetWitness(witness)
+
+	return nil
 }
 
-func (bs *BlockSigner) SignAndUpdateBlock(keyPair *keys.KeyPair, block *blk.Block) {
-	block.Sign(bs.SignBlock(keyPair, block))
+// SignAndUpdateBlock sets the witness signed by this signer alone
+func (bs *BlockSigner) SignAndUpdateBlock(keyPair *keys.KeyPair, block *blk.Block, validators []keys.PublicKeyBytes) error {
+	session := "single/" + block.GetHashString()
+	nonce, err := bs.CreateNonce(session)
+	if err != nil {
+		return err
+	}
+
+	signers := []keys.PublicKeyBytes{keyPair.PublicToBytes()}
+	nonces := []ms.NonceBytes{nonce}
+	partial, err := bs.SignPartial(keyPair, session, block, signers, nonces)
+	if err != nil {
+		return err
+	}
+
+	return bs.AggregateWitness(block, validators, signers, nonces, []ms.PartialSignatureBytes{partial})
 }
@@ -0,0 +1,120 @@
+package admission
+
+import (
+	tx "github.com/Digital-Voting-Team/Digital-Voting/pkg/blockchain/transaction"
+	ts "github.com/Digital-Voting-Team/Digital-Voting/pkg/blockchain/transaction/transaction_specific"
+	"github.com/Digital-Voting-Team/Digital-Voting/pkg/rejection"
+	"net"
+)
+
+var (
+	ErrConnectionRateLimited = rejection.New(rejection.RateLimited, "connection exceeds transaction rate limit")
+	ErrKeyRateLimited        = rejection.New(rejection.RateLimited, "public key exceeds transaction rate limit")
+	ErrInsufficientWork      = rejection.New(rejection.InsufficientWork, "anonymous vote has insufficient proof of work")
+)
+
+// Config of admission control, a zero rate disables the corresponding limit
+type Config struct {
+	KeyRate         float64
+	KeyBurst        float64
+	ConnectionRate  float64
+	ConnectionBurst float64
+
+	// AnonymousVoteDifficulty is the number of leading zero bits required from the work hash
+	// of anonymous votes since they have no sender key to rate limit, zero disables the check
+	AnonymousVoteDifficulty uint8
+}
+
+func DefaultConfig() Config {
+	return Config{
+		KeyRate:                 1,
+		KeyBurst:                10,
+		ConnectionRate:          5,
+		ConnectionBurst:         20,
+		AnonymousVoteDifficulty: 16,
+	}
+}
+
+// Controller decides whether a transaction is worth verifying before it reaches MemPool,
+// a nil Controller admits everything
+type Controller struct {
+	config            Config
+	keyLimiter        *RateLimiter
+	connectionLimiter *RateLimiter
+
+	Metrics *Metrics
+}
+
+func NewController(config Config) *Controller {
+	controller := &Controller{
+		config:  config,
+		Metrics: &Metrics{},
+	}
+	if config.KeyRate > 0 {
+		controller.keyLimiter = NewRateLimiter(config.KeyRate, config.KeyBurst)
+	}
+	if config.ConnectionRate > 0 {
+		controller.connectionLimiter = NewRateLimiter(config.ConnectionRate, config.ConnectionBurst)
+	}
+	return controller
+}
+
+// AdmitConnection limits transactions submitted from one remote address, the port is ignored
+func (c *Controller) AdmitConnection(remoteAddress string) error {
+	if c == nil || c.connectionLimiter == nil {
+		return nil
+	}
+	host, _, err := net.SplitHostPort(remoteAddress)
+	if err != nil {
+		host = remoteAddress
+	}
+	if !c.connectionLimiter.Allow(host) {
+		c.Metrics.Reject(ConnectionRateLimited)
+		return ErrConnectionRateLimited
+	}
+	return nil
+}
+
+// AdmitTransaction checks proof of work of anonymous votes, it is stateless and meant
+// to be called before the signature is verified
+func (c *Controller) AdmitTransaction(transaction tx.ITransaction) error {
+	if c == nil {
+		return nil
+	}
+	if exact, ok := transaction.(*ts.TxVoteAnonymous); ok && !exact.CheckProofOfWork(c.config.AnonymousVoteDifficulty) {
+		c.Metrics.Reject(InsufficientWork)
+		return ErrInsufficientWork
+	}
+	return nil
+}
+
+// ChargeSender limits transactions of one public key, it has to be called only after the signature
+// is verified, otherwise anyone could drain the bucket of a key they do not own
+func (c *Controller) ChargeSender(transaction tx.ITransaction) error {
+	if c == nil || c.keyLimiter == nil {
+		return nil
+	}
+	exact, ok := transaction.(*tx.Transaction)
+	if !ok {
+		return nil
+	}
+	if !c.keyLimiter.Allow(string(exact.PublicKey[:])) {
+		c.Metrics.Reject(KeyRateLimited)
+		return ErrKeyRateLimited
+	}
+	return nil
+}
+
+// Accept counts a transaction added to MemPool
+func (c *Controller) Accept() {
+	if c != nil {
+		c.Metrics.Accept()
+	}
+}
+
+// Reject counts a transaction rejected after admission
+func (c *Controller) Reject(reason Reason) {
+	if c != nil {
+		c.Metrics.Reject(reason)
+	}
+}
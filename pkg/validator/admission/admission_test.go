@@ -0,0 +1,98 @@
+package admission
+
+import (
+	tx "github.com/Digital-Voting-Team/Digital-Voting/pkg/blockchain/transaction"
+	ts "github.com/Digital-Voting-Team/Digital-Voting/pkg/blockchain/transaction/transaction_specific"
+	"github.com/Digital-Voting-Team/Digital-Voting/pkg/models/account"
+	"github.com/Digital-Voting-Team/Digital-Voting/pkg/signature/keys"
+	"testing"
+)
+
+func TestRateLimiter(t *testing.T) {
+	limiter := NewRateLimiter(1, 3)
+
+	for i := 0; i < 3; i++ {
+		if !limiter.Allow("first") {
+			t.Fatalf("request %d within burst was limited", i)
+		}
+	}
+	if limiter.Allow("first") {
+		t.Errorf("request over burst was allowed")
+	}
+	if !limiter.Allow("second") {
+		t.Errorf("another key was limited")
+	}
+}
+
+func TestController_AdmitConnection(t *testing.T) {
+	controller := NewController(Config{ConnectionRate: 1, ConnectionBurst: 1})
+
+	if err := controller.AdmitConnection("127.0.0.1:5000"); err != nil {
+		t.Fatalf("first connection was not admitted: %v", err)
+	}
+	if err := controller.AdmitConnection("127.0.0.1:5001"); err != ErrConnectionRateLimited {
+		t.Errorf("AdmitConnection() from another port error = %v, want %v", err, ErrConnectionRateLimited)
+	}
+	if got := controller.Metrics.Snapshot().Rejected[ConnectionRateLimited.String()]; got != 1 {
+		t.Errorf("rejected connections = %d, want 1", got)
+	}
+}
+
+func TestController_AdmitTransaction(t *testing.T) {
+	controller := NewController(Config{KeyRate: 1, KeyBurst: 1, AnonymousVoteDifficulty: 8})
+
+	solved := ts.NewTxVoteAnonymous([32]byte{1}, 1)
+	solved.SolveProofOfWork(8)
+	unsolved := ts.NewTxVoteAnonymous([32]byte{2}, 1)
+	for unsolved.CheckProofOfWork(8) {
+		unsolved.PowNonce++
+	}
+	transaction := tx.NewTransaction(tx.AccountCreation, ts.NewTxAccCreation(account.User, keys.PublicKeyBytes{1}))
+	transaction.PublicKey = keys.PublicKeyBytes{1}
+
+	tests := []struct {
+		name        string
+		transaction tx.ITransaction
+		want        error
+	}{
+		{name: "Anonymous vote with proof of work", transaction: solved, want: nil},
+		{name: "Anonymous vote without proof of work", transaction: unsolved, want: ErrInsufficientWork},
+		{name: "Key is not charged", transaction: transaction, want: nil},
+		{name: "Key is not charged again", transaction: transaction, want: nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := controller.AdmitTransaction(tt.transaction); err != tt.want {
+				t.Errorf("AdmitTransaction() error = %v, want %v", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestController_ChargeSender(t *testing.T) {
+	controller := NewController(Config{KeyRate: 1, KeyBurst: 1})
+
+	newTransaction := func(sender byte) *tx.Transaction {
+		transaction := tx.NewTransaction(tx.AccountCreation, ts.NewTxAccCreation(account.User, keys.PublicKeyBytes{1}))
+		transaction.PublicKey = keys.PublicKeyBytes{sender}
+		return transaction
+	}
+
+	tests := []struct {
+		name        string
+		transaction tx.ITransaction
+		want        error
+	}{
+		{name: "First transaction of key", transaction: newTransaction(1), want: nil},
+		{name: "Key over rate limit", transaction: newTransaction(1), want: ErrKeyRateLimited},
+		{name: "Another key", transaction: newTransaction(2), want: nil},
+		{name: "Anonymous vote has no key", transaction: ts.NewTxVoteAnonymous([32]byte{1}, 1), want: nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := controller.ChargeSender(tt.transaction); err != tt.want {
+				t.Errorf("ChargeSender() error = %v, want %v", err, tt.want)
+			}
+		})
+	}
+}
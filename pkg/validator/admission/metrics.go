@@ -0,0 +1,66 @@
+package admission
+
+import "sync/atomic"
+
+// Reason is why a transaction was not added to MemPool
+type Reason int
+
+const (
+	ConnectionRateLimited Reason = iota
+	KeyRateLimited
+	InsufficientWork
+	InvalidTransaction
+	MemPoolRejected
+	reasonsCount
+)
+
+func (r Reason) String() string {
+	switch r {
+	case ConnectionRateLimited:
+		return "connection_rate_limited"
+	case KeyRateLimited:
+		return "key_rate_limited"
+	case InsufficientWork:
+		return "insufficient_work"
+	case InvalidTransaction:
+		return "invalid_transaction"
+	case MemPoolRejected:
+		return "mem_pool_rejected"
+	default:
+		return "unknown"
+	}
+}
+
+// Metrics counts admitted and rejected transactions, it is safe for concurrent use
+type Metrics struct {
+	accepted uint64
+	rejected [reasonsCount]uint64
+}
+
+// MetricsSnapshot is a copy of the counters at one moment
+type MetricsSnapshot struct {
+	Accepted uint64            `json:"accepted"`
+	Rejected map[string]uint64 `json:"rejected"`
+}
+
+func (m *Metrics) Accept() {
+	atomic.AddUint64(&m.accepted, 1)
+}
+
+func (m *Metrics) Reject(reason Reason) {
+	if reason < 0 || reason >= reasonsCount {
+		return
+	}
+	atomic.AddUint64(&m.rejected[reason], 1)
+}
+
+func (m *Metrics) Snapshot() MetricsSnapshot {
+	snapshot := MetricsSnapshot{
+		Accepted: atomic.LoadUint64(&m.accepted),
+		Rejected: map[string]uint64{},
+	}
+	for reason := Reason(0); reason < reasonsCount; reason++ {
+		snapshot.Rejected[reason.String()] = atomic.LoadUint64(&m.rejected[reason])
+	}
+	return snapshot
+}
@@ -0,0 +1,65 @@
+package admission
+
+import (
+	"math"
+	"sync"
+	"time"
+)
+
+// maxTrackedKeys bounds the number of keys rate limits are kept for
+const maxTrackedKeys = 1024
+
+type tokenBucket struct {
+	tokens float64
+	last   time.Time
+}
+
+// RateLimiter keeps a token bucket per key, e.g. a peer address or a public key
+type RateLimiter struct {
+	mutex   sync.Mutex
+	rate    float64
+	burst   float64
+	buckets map[string]*tokenBucket
+}
+
+// NewRateLimiter allows rate requests per second per key and burst requests at once after the key was idle
+func NewRateLimiter(rate, burst float64) *RateLimiter {
+	return &RateLimiter{
+		rate:    rate,
+		burst:   burst,
+		buckets: map[string]*tokenBucket{},
+	}
+}
+
+// Allow takes a token from the bucket of the key, false means the key exceeded its rate
+func (rl *RateLimiter) Allow(key string) bool {
+	rl.mutex.Lock()
+	defer rl.mutex.Unlock()
+	now := time.Now()
+
+	bucket, exists := rl.buckets[key]
+	if !exists {
+		if len(rl.buckets) >= maxTrackedKeys {
+			rl.forgetIdle(now)
+		}
+		bucket = &tokenBucket{tokens: rl.burst, last: now}
+		rl.buckets[key] = bucket
+	}
+
+	bucket.tokens = math.Min(rl.burst, bucket.tokens+now.Sub(bucket.last).Seconds()*rl.rate)
+	bucket.last = now
+	if bucket.tokens < 1 {
+		return false
+	}
+	bucket.tokens--
+	return true
+}
+
+// forgetIdle drops buckets that are full again, they behave exactly like new ones
+func (rl *RateLimiter) forgetIdle(now time.Time) {
+	for key, bucket := range rl.buckets {
+		if bucket.tokens+now.Sub(bucket.last).Seconds()*rl.rate >= rl.burst {
+			delete(rl.buckets, key)
+		}
+	}
+}
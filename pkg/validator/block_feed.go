@@ -0,0 +1,68 @@
+package validator
+
+import (
+	blk "github.com/Digital-Voting-Team/Digital-Voting/pkg/blockchain/block"
+	"sync"
+)
+
+// blockFeedBuffer is the number of committed blocks a subscriber may lag behind before it is dropped
+const blockFeedBuffer = 16
+
+// CommittedBlock is a block added to the chain with its height
+type CommittedBlock struct {
+	Height uint64
+	Block  *blk.Block
+}
+
+// BlockFeed notifies subscribers of blocks committed to the chain
+type BlockFeed struct {
+	mutex       sync.Mutex
+	subscribers map[chan CommittedBlock]struct{}
+}
+
+func NewBlockFeed() *BlockFeed {
+	return &BlockFeed{subscribers: map[chan CommittedBlock]struct{}{}}
+}
+
+// Subscribe returns channel receiving committed blocks and a function ending the subscription,
+// the channel is closed when the subscription ends or the subscriber falls behind, nil feed never sends
+func (bf *BlockFeed) Subscribe() (<-chan CommittedBlock, func()) {
+	if bf == nil {
+		return nil, func() {}
+	}
+	blocks := make(chan CommittedBlock, blockFeedBuffer)
+
+	bf.mutex.Lock()
+	bf.subscribers[blocks] = struct{}{}
+	bf.mutex.Unlock()
+
+	return blocks, func() {
+		bf.mutex.Lock()
+		defer bf.mutex.Unlock()
+		bf.remove(blocks)
+	}
+}
+
+// Publish sends block to all subscribers, it is a no-op on nil feed
+func (bf *BlockFeed) Publish(block CommittedBlock) {
+	if bf == nil {
+		return
+	}
+
+	bf.mutex.Lock()
+	defer bf.mutex.Unlock()
+	for blocks := range bf.subscribers {
+		select {
+		case blocks <- block:
+		default:
+			bf.remove(blocks)
+		}
+	}
+}
+
+func (bf *BlockFeed) remove(blocks chan CommittedBlock) {
+	if _, exists := bf.subscribers[blocks]; exists {
+		delete(bf.subscribers, blocks)
+		close(blocks)
+	}
+}
@@ -4,24 +4,14 @@ import (
 	blk "github.com/Digital-Voting-Team/Digital-Voting/pkg/blockchain/block"
 	tx "github.com/Digital-Voting-Team/Digital-Voting/pkg/blockchain/transaction"
 	"github.com/Digital-Voting-Team/Digital-Voting/pkg/signature/keys"
-	"github.com/Digital-Voting-Team/Digital-Voting/pkg/validator/repository/indexed_votings"
 )
 
-type Communication struct {
-	NetworkToValidator chan *blk.Block
-	ValidatorToNetwork chan *blk.Block
-	BlockResponse      chan ResponseMessage
-
-	BlockApproval    chan *blk.Block
-	ApprovalResponse chan bool
-
-	BlockDenial chan *blk.Block
-
-	Transaction chan tx.ITransaction
-	TxResponse  chan bool
-
-	ValidatorKeys chan []keys.PublicKeyBytes
-
-	Votings   chan []indexed_votings.VotingDTO
-	PublicKey chan keys.PublicKeyBytes
+// Network delivers blocks and transactions of this validator to the other validators
+type Network interface {
+	// SubmitBlock hands a block created by this validator to consensus and returns once it is decided
+	SubmitBlock(block *blk.Block)
+	// ForwardTransactions passes pending transactions to the proposer of the current height
+	ForwardTransactions(proposer keys.PublicKeyBytes, transactions []tx.ITransaction) error
+	// Proposer returns the proposer of the height and round consensus is in
+	Proposer() (keys.PublicKeyBytes, error)
 }
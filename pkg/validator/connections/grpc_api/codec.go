@@ -0,0 +1,28 @@
+package grpc_api
+
+import (
+	"encoding/json"
+	"google.golang.org/grpc/encoding"
+)
+
+// CodecName is the content subtype of the service, messages are JSON encoded
+// so they are the same DTOs the rest api returns
+const CodecName = "json"
+
+type jsonCodec struct{}
+
+func (jsonCodec) Marshal(v interface{}) ([]byte, error) {
+	return json.Marshal(v)
+}
+
+func (jsonCodec) Unmarshal(data []byte, v interface{}) error {
+	return json.Unmarshal(data, v)
+}
+
+func (jsonCodec) Name() string {
+	return CodecName
+}
+
+func init() {
+	encoding.RegisterCodec(jsonCodec{})
+}
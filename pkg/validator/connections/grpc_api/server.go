@@ -0,0 +1,229 @@
+package grpc_api
+
+import (
+	"context"
+	"errors"
+	tx "github.com/Digital-Voting-Team/Digital-Voting/pkg/blockchain/transaction"
+	"github.com/Digital-Voting-Team/Digital-Voting/pkg/blockchain/transaction/transaction_json"
+	"github.com/Digital-Voting-Team/Digital-Voting/pkg/logging"
+	"github.com/Digital-Voting-Team/Digital-Voting/pkg/rejection"
+	"github.com/Digital-Voting-Team/Digital-Voting/pkg/validator"
+	"github.com/Digital-Voting-Team/Digital-Voting/pkg/validator/admission"
+	"github.com/Digital-Voting-Team/Digital-Voting/pkg/validator/query"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+	"log"
+	"net"
+)
+
+// RejectionCodeTrailer is the trailer carrying the rejection code of a transaction that was not accepted
+const RejectionCodeTrailer = "rejection-code"
+
+// ErrStreamLagging ends a block stream of a client too slow to receive committed blocks
+var ErrStreamLagging = status.Error(codes.ResourceExhausted, "client fell behind committed blocks")
+
+// Submitter passes transactions to the validator, NetworkNode implements it to gossip accepted ones
+type Submitter interface {
+	SubmitTransaction(transaction tx.ITransaction) error
+}
+
+// Server implements ValidatorServer over the validator and its repositories
+type Server struct {
+	hostname string
+	server   *grpc.Server
+	// stopping ends block streams on Stop
+	stopping chan struct{}
+
+	Submitter Submitter
+	Query     *query.Service
+	Admission *admission.Controller
+	BlockFeed *validator.BlockFeed
+}
+
+func NewServer(hostname string, submitter Submitter, service *query.Service, blockFeed *validator.BlockFeed) *Server {
+	s := &Server{
+		hostname:  hostname,
+		server:    grpc.NewServer(),
+		stopping:  make(chan struct{}),
+		Submitter: submitter,
+		Query:     service,
+		Admission: admission.NewController(admission.DefaultConfig()),
+		BlockFeed: blockFeed,
+	}
+	RegisterValidatorServer(s.server, s)
+
+	return s
+}
+
+// Start serves the service on hostname, it returns nil once Stop was called
+func (s *Server) Start() error {
+	listener, err := net.Listen("tcp", s.hostname)
+	if err != nil {
+		return err
+	}
+	return s.Serve(listener)
+}
+
+// Serve serves the service on listener, it returns nil once Stop was called
+func (s *Server) Serve(listener net.Listener) error {
+	err := s.server.Serve(listener)
+	if err == grpc.ErrServerStopped {
+		return nil
+	}
+	return err
+}
+
+// Stop waits for unary calls in progress to finish, ctx bounds the wait after which open calls are cancelled,
+// block streams are ended right away since they do not finish on their own
+func (s *Server) Stop(ctx context.Context) {
+	close(s.stopping)
+
+	stopped := make(chan struct{})
+	go func() {
+		s.server.GracefulStop()
+		close(stopped)
+	}()
+
+	select {
+	case <-stopped:
+	case <-ctx.Done():
+		s.server.Stop()
+	}
+}
+
+// toStatus maps errors of the validator and queries to gRPC status errors
+func toStatus(err error) error {
+	var rejectionError *rejection.Error
+	switch {
+	case errors.Is(err, query.ErrNotFound):
+		return status.Error(codes.NotFound, err.Error())
+	case errors.Is(err, query.ErrInvalidArgument):
+		return status.Error(codes.InvalidArgument, err.Error())
+	case errors.As(err, &rejectionError):
+		if rejectionError.Code == rejection.RateLimited {
+			return status.Error(codes.ResourceExhausted, err.Error())
+		}
+		return status.Error(codes.FailedPrecondition, err.Error())
+	default:
+		return status.Error(codes.Internal, err.Error())
+	}
+}
+
+func (s *Server) SubmitTransaction(ctx context.Context, request *SubmitTransactionRequest) (*SubmitTransactionResponse, error) {
+	remoteAddress := ""
+	if p, ok := peer.FromContext(ctx); ok {
+		remoteAddress = p.Addr.String()
+	}
+
+	err := s.Admission.AdmitConnection(remoteAddress)
+	if err != nil {
+		logging.Errorf("Transaction from %s not admitted: %v", remoteAddress, err)
+		return nil, s.reject(ctx, err)
+	}
+
+	transaction, err := (&transaction_json.JSONTransaction{}).UnmarshallJSON(request.Transaction)
+	if err != nil {
+		return nil, s.reject(ctx, err)
+	}
+
+	log.Printf("Received new transaction with hash: %s", transaction.GetHashString())
+	err = s.Submitter.SubmitTransaction(transaction)
+	if err != nil {
+		logging.Errorf("Transaction with hash %s rejected: %v", transaction.GetHashString(), err)
+		return nil, s.reject(ctx, err)
+	}
+
+	hash := transaction.GetHash()
+	return &SubmitTransactionResponse{Hash: query.EncodeHex(hash[:])}, nil
+}
+
+// reject sets the rejection code trailer so clients can tell the reason without parsing the message
+func (s *Server) reject(ctx context.Context, err error) error {
+	var rejectionError *rejection.Error
+	if errors.As(err, &rejectionError) {
+		trailerErr := grpc.SetTrailer(ctx, metadata.Pairs(RejectionCodeTrailer, string(rejectionError.Code)))
+		if trailerErr != nil {
+			logging.Errorln("Error setting trailer:", trailerErr)
+		}
+	}
+	return toStatus(err)
+}
+
+func (s *Server) PrepareTransaction(ctx context.Context, request *PrepareTransactionRequest) (*query.PreparedTransactionDTO, error) {
+	prepared, err := s.Query.Prepare(request.Transaction)
+	if err != nil {
+		return nil, s.reject(ctx, err)
+	}
+	return &prepared, nil
+}
+
+func (s *Server) GetVotings(ctx context.Context, request *GetVotingsRequest) (*GetVotingsResponse, error) {
+	page, err := s.Query.Votings(request.Status, request.Offset, request.Limit)
+	if err != nil {
+		return nil, toStatus(err)
+	}
+	return &GetVotingsResponse{Votings: page.Items.([]query.VotingDTO), Total: page.Total}, nil
+}
+
+func (s *Server) GetVoting(ctx context.Context, request *GetVotingRequest) (*query.VotingDTO, error) {
+	voting, err := s.Query.Voting(request.Hash)
+	if err != nil {
+		return nil, toStatus(err)
+	}
+	return &voting, nil
+}
+
+func (s *Server) GetResults(ctx context.Context, request *GetVotingRequest) (*query.ResultsDTO, error) {
+	results, err := s.Query.Results(request.Hash)
+	if err != nil {
+		return nil, toStatus(err)
+	}
+	return &results, nil
+}
+
+func (s *Server) GetBlock(ctx context.Context, request *GetBlockRequest) (*query.BlockDTO, error) {
+	var block query.BlockDTO
+	var err error
+	if request.Hash != "" {
+		block, err = s.Query.BlockByHash(request.Hash)
+	} else {
+		block, err = s.Query.BlockByHeight(request.Height)
+	}
+	if err != nil {
+		return nil, toStatus(err)
+	}
+	return &block, nil
+}
+
+func (s *Server) GetProof(ctx context.Context, request *GetProofRequest) (*query.ProofDTO, error) {
+	proof, err := s.Query.Proof(request.Transaction)
+	if err != nil {
+		return nil, toStatus(err)
+	}
+	return &proof, nil
+}
+
+func (s *Server) StreamBlocks(request *StreamBlocksRequest, stream ValidatorStreamBlocksServer) error {
+	blocks, cancel := s.BlockFeed.Subscribe()
+	defer cancel()
+
+	for {
+		select {
+		case <-stream.Context().Done():
+			return nil
+		case <-s.stopping:
+			return status.Error(codes.Unavailable, "server is stopping")
+		case committed, ok := <-blocks:
+			if !ok {
+				return ErrStreamLagging
+			}
+			block := query.NewBlockDTO(committed.Height, committed.Block)
+			if err := stream.Send(&block); err != nil {
+				return err
+			}
+		}
+	}
+}
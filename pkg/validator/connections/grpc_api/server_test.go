@@ -0,0 +1,202 @@
+package grpc_api
+
+import (
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"github.com/Digital-Voting-Team/Digital-Voting/pkg/blockchain"
+	blk "github.com/Digital-Voting-Team/Digital-Voting/pkg/blockchain/block"
+	tx "github.com/Digital-Voting-Team/Digital-Voting/pkg/blockchain/transaction"
+	ts "github.com/Digital-Voting-Team/Digital-Voting/pkg/blockchain/transaction/transaction_specific"
+	"github.com/Digital-Voting-Team/Digital-Voting/pkg/models/account"
+	"github.com/Digital-Voting-Team/Digital-Voting/pkg/rejection"
+	"github.com/Digital-Voting-Team/Digital-Voting/pkg/signature/curve"
+	"github.com/Digital-Voting-Team/Digital-Voting/pkg/signature/keys"
+	"github.com/Digital-Voting-Team/Digital-Voting/pkg/signer"
+	"github.com/Digital-Voting-Team/Digital-Voting/pkg/validator"
+	"github.com/Digital-Voting-Team/Digital-Voting/pkg/validator/query"
+	"github.com/Digital-Voting-Team/Digital-Voting/pkg/validator/repository"
+	"github.com/Digital-Voting-Team/Digital-Voting/pkg/validator/repository/account_manager"
+	"github.com/Digital-Voting-Team/Digital-Voting/pkg/validator/repository/indexed_votings"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+	"net"
+	"strings"
+	"testing"
+	"time"
+)
+
+// testSubmitter accepts account creation for key 1 only
+type testSubmitter struct{}
+
+func (testSubmitter) SubmitTransaction(transaction tx.ITransaction) error {
+	body, ok := transaction.(*tx.Transaction).TxBody.(*ts.TxAccountCreation)
+	if !ok || body.NewPublicKey != (keys.PublicKeyBytes{1}) {
+		return rejection.New(rejection.AccountExists, "account exists")
+	}
+	return nil
+}
+
+func newTestClient(t *testing.T, server *Server) *ValidatorClient {
+	listener := bufconn.Listen(1 << 20)
+	go func() {
+		_ = server.Serve(listener)
+	}()
+	t.Cleanup(func() {
+		server.Stop(context.Background())
+	})
+
+	conn, err := grpc.Dial("bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return listener.DialContext(ctx)
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		_ = conn.Close()
+	})
+	return NewValidatorClient(conn)
+}
+
+func TestServer(t *testing.T) {
+	user := keys.PublicKeyBytes{1}
+	accountCreation := tx.NewTransaction(tx.AccountCreation, ts.NewTxAccCreation(account.User, user))
+	genesis := blk.NewGenesisBlock(blk.DefaultChainParams(), nil)
+	block := blk.NewBlock([]tx.ITransaction{accountCreation}, genesis.GetHash())
+	bc := &blockchain.Blockchain{Blocks: []*blk.Block{genesis, block}}
+
+	indexedData := repository.NewIndexedData()
+	indexedData.AccountManager.AddPubKey(user, account_manager.User)
+	voting := indexed_votings.VotingDTO{
+		Hash:           [32]byte{1},
+		ExpirationDate: uint32(time.Now().Add(time.Hour).Unix()),
+		Answers:        [][256]byte{{'y', 'e', 's'}, {'n', 'o'}},
+		Whitelist:      [][33]byte{user},
+	}
+	indexedData.VotingManager.AddNewVoting(voting)
+	indexedData.VotingManager.AddVote(voting.Hash, user, 0)
+
+	feed := validator.NewBlockFeed()
+	client := newTestClient(t, NewServer("bufnet", testSubmitter{}, query.NewService(bc, indexedData, validator.NewMemPool()), feed))
+	ctx := context.Background()
+
+	clientKeys, _ := keys.Random(curve.NewCurve25519())
+	transactionSigner := signer.NewTransactionSigner()
+
+	t.Run("Submit transaction", func(t *testing.T) {
+		signed := tx.NewTransaction(tx.AccountCreation, ts.NewTxAccCreation(account.User, user))
+		transactionSigner.SignTransaction(clientKeys, signed)
+		marshalled, _ := json.Marshal(signed)
+		response, err := client.SubmitTransaction(ctx, &SubmitTransactionRequest{Transaction: marshalled})
+		if err != nil {
+			t.Fatalf("SubmitTransaction() error = %v", err)
+		}
+		hash := signed.GetHash()
+		if response.Hash != hex.EncodeToString(hash[:]) {
+			t.Errorf("hash = %s, want %x", response.Hash, hash)
+		}
+	})
+
+	t.Run("Rejected transaction", func(t *testing.T) {
+		rejected := tx.NewTransaction(tx.AccountCreation, ts.NewTxAccCreation(account.User, keys.PublicKeyBytes{2}))
+		transactionSigner.SignTransaction(clientKeys, rejected)
+		marshalled, _ := json.Marshal(rejected)
+		var trailer metadata.MD
+		_, err := client.SubmitTransaction(ctx, &SubmitTransactionRequest{Transaction: marshalled}, grpc.Trailer(&trailer))
+		if status.Code(err) != codes.FailedPrecondition {
+			t.Errorf("code = %v, want %v", status.Code(err), codes.FailedPrecondition)
+		}
+		if got := trailer.Get(RejectionCodeTrailer); len(got) != 1 || got[0] != string(rejection.AccountExists) {
+			t.Errorf("trailer = %v, want %s", got, rejection.AccountExists)
+		}
+	})
+
+	t.Run("Prepare anonymous vote", func(t *testing.T) {
+		marshalled, _ := json.Marshal(ts.NewTxVoteAnonymous(voting.Hash, 1))
+		prepared, err := client.PrepareTransaction(ctx, &PrepareTransactionRequest{Transaction: marshalled})
+		if err != nil {
+			t.Fatalf("PrepareTransaction() error = %v", err)
+		}
+		if len(prepared.Ring) != 1 || prepared.Ring[0] != hex.EncodeToString(user[:]) || prepared.SignatureMessage == "" {
+			t.Errorf("PrepareTransaction() = %+v, want ring of the user", prepared)
+		}
+	})
+
+	t.Run("Prepare with private key", func(t *testing.T) {
+		var trailer metadata.MD
+		_, err := client.PrepareTransaction(ctx, &PrepareTransactionRequest{
+			Transaction: []byte(`{"tx_type": 0, "tx_body": {}, "private_key": [1]}`),
+		}, grpc.Trailer(&trailer))
+		if got := trailer.Get(RejectionCodeTrailer); err == nil || len(got) != 1 || got[0] != string(rejection.PrivateKeySent) {
+			t.Errorf("trailer = %v, want %s", got, rejection.PrivateKeySent)
+		}
+	})
+
+	t.Run("Votings and results", func(t *testing.T) {
+		votings, err := client.GetVotings(ctx, &GetVotingsRequest{Status: query.StatusActive})
+		if err != nil || votings.Total != 1 {
+			t.Fatalf("GetVotings() = %v, %v, want one voting", votings, err)
+		}
+		results, err := client.GetResults(ctx, &GetVotingRequest{Hash: votings.Votings[0].Hash})
+		if err != nil || len(results.Results) != 2 || results.Results[0] != 1 {
+			t.Errorf("GetResults() = %v, %v, want [1 0]", results, err)
+		}
+	})
+
+	t.Run("Unknown voting", func(t *testing.T) {
+		_, err := client.GetVoting(ctx, &GetVotingRequest{Hash: strings.Repeat("00", 32)})
+		if status.Code(err) != codes.NotFound {
+			t.Errorf("code = %v, want %v", status.Code(err), codes.NotFound)
+		}
+	})
+
+	t.Run("Block and proof", func(t *testing.T) {
+		got, err := client.GetBlock(ctx, &GetBlockRequest{Height: 1})
+		blockHash := block.GetHash()
+		if err != nil || got.Hash != hex.EncodeToString(blockHash[:]) {
+			t.Fatalf("GetBlock() = %v, %v, want block at height 1", got, err)
+		}
+		transactionHash := accountCreation.GetHash()
+		proof, err := client.GetProof(ctx, &GetProofRequest{Transaction: hex.EncodeToString(transactionHash[:])})
+		if err != nil || proof.BlockHash != got.Hash {
+			t.Errorf("GetProof() = %v, %v, want proof in block %s", proof, err, got.Hash)
+		}
+	})
+
+	t.Run("Stream blocks", func(t *testing.T) {
+		streamCtx, cancel := context.WithTimeout(ctx, 5*time.Second)
+		defer cancel()
+		stream, err := client.StreamBlocks(streamCtx, &StreamBlocksRequest{})
+		if err != nil {
+			t.Fatalf("StreamBlocks() error = %v", err)
+		}
+
+		// the subscription is made once the server receives the request, publish until it is delivered
+		received := make(chan *query.BlockDTO, 1)
+		go func() {
+			got, err := stream.Recv()
+			if err == nil {
+				received <- got
+			}
+			close(received)
+		}()
+		for {
+			feed.Publish(validator.CommittedBlock{Height: 1, Block: block})
+			select {
+			case got, ok := <-received:
+				if !ok || got.Height != 1 {
+					t.Errorf("Recv() = %v, want block at height 1", got)
+				}
+				return
+			case <-time.After(10 * time.Millisecond):
+			}
+		}
+	})
+}
@@ -0,0 +1,286 @@
+package grpc_api
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/Digital-Voting-Team/Digital-Voting/pkg/validator/query"
+	"google.golang.org/grpc"
+)
+
+// ServiceName is the full name of the validator service, methods are called as /ServiceName/Method
+const ServiceName = "digitalvoting.Validator"
+
+type SubmitTransactionRequest struct {
+	// Transaction is encoded the same way the websocket /transaction endpoint expects it
+	Transaction json.RawMessage `json:"transaction"`
+}
+
+type SubmitTransactionResponse struct {
+	Hash string `json:"hash"`
+}
+
+type PrepareTransactionRequest struct {
+	// Transaction is unsigned, zero nonce is replaced with a random one
+	Transaction json.RawMessage `json:"transaction"`
+}
+
+type GetVotingsRequest struct {
+	// Status is active, finished or empty for votings of all statuses
+	Status string `json:"status,omitempty"`
+	Offset int    `json:"offset,omitempty"`
+	Limit  int    `json:"limit,omitempty"`
+}
+
+type GetVotingsResponse struct {
+	Votings []query.VotingDTO `json:"votings"`
+	Total   int               `json:"total"`
+}
+
+type GetVotingRequest struct {
+	Hash string `json:"hash"`
+}
+
+type GetBlockRequest struct {
+	// Hash selects the block when it is set, Height otherwise
+	Hash   string `json:"hash,omitempty"`
+	Height uint64 `json:"height,omitempty"`
+}
+
+type GetProofRequest struct {
+	Transaction string `json:"transaction"`
+}
+
+type StreamBlocksRequest struct{}
+
+// ValidatorServer is the server API of the validator service
+type ValidatorServer interface {
+	SubmitTransaction(ctx context.Context, request *SubmitTransactionRequest) (*SubmitTransactionResponse, error)
+	// PrepareTransaction returns the payload the client signs, private keys never reach the validator
+	PrepareTransaction(ctx context.Context, request *PrepareTransactionRequest) (*query.PreparedTransactionDTO, error)
+	GetVotings(ctx context.Context, request *GetVotingsRequest) (*GetVotingsResponse, error)
+	GetVoting(ctx context.Context, request *GetVotingRequest) (*query.VotingDTO, error)
+	GetResults(ctx context.Context, request *GetVotingRequest) (*query.ResultsDTO, error)
+	GetBlock(ctx context.Context, request *GetBlockRequest) (*query.BlockDTO, error)
+	GetProof(ctx context.Context, request *GetProofRequest) (*query.ProofDTO, error)
+	// StreamBlocks sends blocks as they are committed until the client cancels the call
+	StreamBlocks(request *StreamBlocksRequest, stream ValidatorStreamBlocksServer) error
+}
+
+// ValidatorStreamBlocksServer sends committed blocks to a StreamBlocks client
+type ValidatorStreamBlocksServer interface {
+	Send(block *query.BlockDTO) error
+	grpc.ServerStream
+}
+
+type streamBlocksServer struct {
+	grpc.ServerStream
+}
+
+func (s *streamBlocksServer) Send(block *query.BlockDTO) error {
+	return s.ServerStream.SendMsg(block)
+}
+
+func _Validator_SubmitTransaction_Handler(server interface{}, ctx context.Context, decode func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	request := &SubmitTransactionRequest{}
+	if err := decode(request); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return server.(ValidatorServer).SubmitTransaction(ctx, request)
+	}
+	info := &grpc.UnaryServerInfo{Server: server, FullMethod: "/" + ServiceName + "/SubmitTransaction"}
+	return interceptor(ctx, request, info, func(ctx context.Context, request interface{}) (interface{}, error) {
+		return server.(ValidatorServer).SubmitTransaction(ctx, request.(*SubmitTransactionRequest))
+	})
+}
+
+func _Validator_PrepareTransaction_Handler(server interface{}, ctx context.Context, decode func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	request := &PrepareTransactionRequest{}
+	if err := decode(request); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return server.(ValidatorServer).PrepareTransaction(ctx, request)
+	}
+	info := &grpc.UnaryServerInfo{Server: server, FullMethod: "/" + ServiceName + "/PrepareTransaction"}
+	return interceptor(ctx, request, info, func(ctx context.Context, request interface{}) (interface{}, error) {
+		return server.(ValidatorServer).PrepareTransaction(ctx, request.(*PrepareTransactionRequest))
+	})
+}
+
+func _Validator_GetVotings_Handler(server interface{}, ctx context.Context, decode func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	request := &GetVotingsRequest{}
+	if err := decode(request); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return server.(ValidatorServer).GetVotings(ctx, request)
+	}
+	info := &grpc.UnaryServerInfo{Server: server, FullMethod: "/" + ServiceName + "/GetVotings"}
+	return interceptor(ctx, request, info, func(ctx context.Context, request interface{}) (interface{}, error) {
+		return server.(ValidatorServer).GetVotings(ctx, request.(*GetVotingsRequest))
+	})
+}
+
+func _Validator_GetVoting_Handler(server interface{}, ctx context.Context, decode func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	request := &GetVotingRequest{}
+	if err := decode(request); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return server.(ValidatorServer).GetVoting(ctx, request)
+	}
+	info := &grpc.UnaryServerInfo{Server: server, FullMethod: "/" + ServiceName + "/GetVoting"}
+	return interceptor(ctx, request, info, func(ctx context.Context, request interface{}) (interface{}, error) {
+		return server.(ValidatorServer).GetVoting(ctx, request.(*GetVotingRequest))
+	})
+}
+
+func _Validator_GetResults_Handler(server interface{}, ctx context.Context, decode func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	request := &GetVotingRequest{}
+	if err := decode(request); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return server.(ValidatorServer).GetResults(ctx, request)
+	}
+	info := &grpc.UnaryServerInfo{Server: server, FullMethod: "/" + ServiceName + "/GetResults"}
+	return interceptor(ctx, request, info, func(ctx context.Context, request interface{}) (interface{}, error) {
+		return server.(ValidatorServer).GetResults(ctx, request.(*GetVotingRequest))
+	})
+}
+
+func _Validator_GetBlock_Handler(server interface{}, ctx context.Context, decode func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	request := &GetBlockRequest{}
+	if err := decode(request); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return server.(ValidatorServer).GetBlock(ctx, request)
+	}
+	info := &grpc.UnaryServerInfo{Server: server, FullMethod: "/" + ServiceName + "/GetBlock"}
+	return interceptor(ctx, request, info, func(ctx context.Context, request interface{}) (interface{}, error) {
+		return server.(ValidatorServer).GetBlock(ctx, request.(*GetBlockRequest))
+	})
+}
+
+func _Validator_GetProof_Handler(server interface{}, ctx context.Context, decode func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	request := &GetProofRequest{}
+	if err := decode(request); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return server.(ValidatorServer).GetProof(ctx, request)
+	}
+	info := &grpc.UnaryServerInfo{Server: server, FullMethod: "/" + ServiceName + "/GetProof"}
+	return interceptor(ctx, request, info, func(ctx context.Context, request interface{}) (interface{}, error) {
+		return server.(ValidatorServer).GetProof(ctx, request.(*GetProofRequest))
+	})
+}
+
+func _Validator_StreamBlocks_Handler(server interface{}, stream grpc.ServerStream) error {
+	request := &StreamBlocksRequest{}
+	if err := stream.RecvMsg(request); err != nil {
+		return err
+	}
+	return server.(ValidatorServer).StreamBlocks(request, &streamBlocksServer{stream})
+}
+
+var ValidatorServiceDesc = grpc.ServiceDesc{
+	ServiceName: ServiceName,
+	HandlerType: (*ValidatorServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{MethodName: "SubmitTransaction", Handler: _Validator_SubmitTransaction_Handler},
+		{MethodName: "PrepareTransaction", Handler: _Validator_PrepareTransaction_Handler},
+		{MethodName: "GetVotings", Handler: _Validator_GetVotings_Handler},
+		{MethodName: "GetVoting", Handler: _Validator_GetVoting_Handler},
+		{MethodName: "GetResults", Handler: _Validator_GetResults_Handler},
+		{MethodName: "GetBlock", Handler: _Validator_GetBlock_Handler},
+		{MethodName: "GetProof", Handler: _Validator_GetProof_Handler},
+	},
+	Streams: []grpc.StreamDesc{
+		{StreamName: "StreamBlocks", Handler: _Validator_StreamBlocks_Handler, ServerStreams: true},
+	},
+}
+
+func RegisterValidatorServer(registrar grpc.ServiceRegistrar, server ValidatorServer) {
+	registrar.RegisterService(&ValidatorServiceDesc, server)
+}
+
+// ValidatorClient calls the validator service, every call uses the JSON codec
+type ValidatorClient struct {
+	conn grpc.ClientConnInterface
+}
+
+func NewValidatorClient(conn grpc.ClientConnInterface) *ValidatorClient {
+	return &ValidatorClient{conn: conn}
+}
+
+func (c *ValidatorClient) invoke(ctx context.Context, method string, request interface{}, response interface{}, opts []grpc.CallOption) error {
+	opts = append([]grpc.CallOption{grpc.CallContentSubtype(CodecName)}, opts...)
+	return c.conn.Invoke(ctx, "/"+ServiceName+"/"+method, request, response, opts...)
+}
+
+func (c *ValidatorClient) SubmitTransaction(ctx context.Context, request *SubmitTransactionRequest, opts ...grpc.CallOption) (*SubmitTransactionResponse, error) {
+	response := &SubmitTransactionResponse{}
+	return response, c.invoke(ctx, "SubmitTransaction", request, response, opts)
+}
+
+func (c *ValidatorClient) PrepareTransaction(ctx context.Context, request *PrepareTransactionRequest, opts ...grpc.CallOption) (*query.PreparedTransactionDTO, error) {
+	response := &query.PreparedTransactionDTO{}
+	return response, c.invoke(ctx, "PrepareTransaction", request, response, opts)
+}
+
+func (c *ValidatorClient) GetVotings(ctx context.Context, request *GetVotingsRequest, opts ...grpc.CallOption) (*GetVotingsResponse, error) {
+	response := &GetVotingsResponse{}
+	return response, c.invoke(ctx, "GetVotings", request, response, opts)
+}
+
+func (c *ValidatorClient) GetVoting(ctx context.Context, request *GetVotingRequest, opts ...grpc.CallOption) (*query.VotingDTO, error) {
+	response := &query.VotingDTO{}
+	return response, c.invoke(ctx, "GetVoting", request, response, opts)
+}
+
+func (c *ValidatorClient) GetResults(ctx context.Context, request *GetVotingRequest, opts ...grpc.CallOption) (*query.ResultsDTO, error) {
+	response := &query.ResultsDTO{}
+	return response, c.invoke(ctx, "GetResults", request, response, opts)
+}
+
+func (c *ValidatorClient) GetBlock(ctx context.Context, request *GetBlockRequest, opts ...grpc.CallOption) (*query.BlockDTO, error) {
+	response := &query.BlockDTO{}
+	return response, c.invoke(ctx, "GetBlock", request, response, opts)
+}
+
+func (c *ValidatorClient) GetProof(ctx context.Context, request *GetProofRequest, opts ...grpc.CallOption) (*query.ProofDTO, error) {
+	response := &query.ProofDTO{}
+	return response, c.invoke(ctx, "GetProof", request, response, opts)
+}
+
+// BlockStream receives blocks sent by StreamBlocks
+type BlockStream struct {
+	stream grpc.ClientStream
+}
+
+// Recv returns the next committed block, io.EOF once the server ends the stream
+func (s *BlockStream) Recv() (*query.BlockDTO, error) {
+	block := &query.BlockDTO{}
+	if err := s.stream.RecvMsg(block); err != nil {
+		return nil, err
+	}
+	return block, nil
+}
+
+func (c *ValidatorClient) StreamBlocks(ctx context.Context, request *StreamBlocksRequest, opts ...grpc.CallOption) (*BlockStream, error) {
+	opts = append([]grpc.CallOption{grpc.CallContentSubtype(CodecName)}, opts...)
+	stream, err := c.conn.NewStream(ctx, &ValidatorServiceDesc.Streams[0], "/"+ServiceName+"/StreamBlocks", opts...)
+	if err != nil {
+		return nil, err
+	}
+	if err = stream.SendMsg(request); err != nil {
+		return nil, err
+	}
+	if err = stream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return &BlockStream{stream: stream}, nil
+}
@@ -0,0 +1,321 @@
+package rest_api
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"github.com/Digital-Voting-Team/Digital-Voting/pkg/blockchain"
+	tx "github.com/Digital-Voting-Team/Digital-Voting/pkg/blockchain/transaction"
+	"github.com/Digital-Voting-Team/Digital-Voting/pkg/blockchain/transaction/transaction_json"
+	"github.com/Digital-Voting-Team/Digital-Voting/pkg/logging"
+	"github.com/Digital-Voting-Team/Digital-Voting/pkg/rejection"
+	"github.com/Digital-Voting-Team/Digital-Voting/pkg/validator"
+	"github.com/Digital-Voting-Team/Digital-Voting/pkg/validator/admission"
+	"github.com/Digital-Voting-Team/Digital-Voting/pkg/validator/query"
+	"github.com/Digital-Voting-Team/Digital-Voting/pkg/validator/repository"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// Codes of errors returned by the API
+const (
+	CodeNotFound         = "not_found"
+	CodeInvalidArgument  = "invalid_argument"
+	CodeMethodNotAllowed = "method_not_allowed"
+	CodeInternal         = "internal"
+	CodeNotImplemented   = "not_implemented"
+)
+
+type apiError struct {
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
+
+// MaxBodySize limits request bodies, only transactions are posted
+const MaxBodySize = 1 << 20
+
+// Submitter passes transactions to the validator, NetworkNode implements it to gossip accepted ones
+type Submitter interface {
+	SubmitTransaction(transaction tx.ITransaction) error
+}
+
+// RestApi serves JSON queries over the chain, indexed data and MemPool,
+// prepares transactions for signing on the client and accepts signed ones
+type RestApi struct {
+	hostname string
+	server   *http.Server
+
+	Query *query.Service
+
+	// Submitter receives posted transactions, submission is not served without it
+	Submitter Submitter
+	Admission *admission.Controller
+}
+
+func NewRestApi(hostname string, bc *blockchain.Blockchain, indexedData *repository.IndexedData, memPool *validator.MemPool) *RestApi {
+	ra := &RestApi{
+		hostname: hostname,
+		Query:    query.NewService(bc, indexedData, memPool),
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/blocks", getOnly(ra.HandleBlocks))
+	mux.HandleFunc("/blocks/", getOnly(ra.HandleBlock))
+	mux.HandleFunc("/transactions", postOnly(ra.HandleSubmitTransaction))
+	mux.HandleFunc("/transactions/", getOnly(ra.HandleTransaction))
+	mux.HandleFunc("/transactions/prepare", postOnly(ra.HandlePrepareTransaction))
+	mux.HandleFunc("/votings", getOnly(ra.HandleVotings))
+	mux.HandleFunc("/votings/", getOnly(ra.HandleVoting))
+	mux.HandleFunc("/groups", getOnly(ra.HandleGroups))
+	mux.HandleFunc("/groups/", getOnly(ra.HandleGroup))
+	mux.HandleFunc("/accounts/", getOnly(ra.HandleAccount))
+	mux.HandleFunc("/mempool", getOnly(ra.HandleMemPool))
+	ra.server = &http.Server{Addr: hostname, Handler: mux}
+
+	return ra
+}
+
+// Handler returns the handler serving all endpoints of the API
+func (ra *RestApi) Handler() http.Handler {
+	return ra.server.Handler
+}
+
+// Start starts rest api, it returns nil once Stop was called
+func (ra *RestApi) Start() error {
+	err := ra.server.ListenAndServe()
+	if err == http.ErrServerClosed {
+		return nil
+	}
+	return err
+}
+
+// Stop stops accepting connections, ctx bounds the time requests in progress have to finish
+func (ra *RestApi) Stop(ctx context.Context) error {
+	return ra.server.Shutdown(ctx)
+}
+
+// getOnly rejects requests with methods other than GET, queries do not change anything
+func getOnly(handler http.HandlerFunc) http.HandlerFunc {
+	return onlyMethod(http.MethodGet, handler)
+}
+
+func postOnly(handler http.HandlerFunc) http.HandlerFunc {
+	return onlyMethod(http.MethodPost, handler)
+}
+
+func onlyMethod(method string, handler http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != method {
+			writeError(w, http.StatusMethodNotAllowed, CodeMethodNotAllowed, "only "+method+" is supported")
+			return
+		}
+		handler(w, r)
+	}
+}
+
+// writeResult writes the result of a query or the error it failed with
+func writeResult(w http.ResponseWriter, result interface{}, err error) {
+	switch {
+	case err == nil:
+		writeJSON(w, http.StatusOK, result)
+	case errors.Is(err, query.ErrNotFound):
+		writeError(w, http.StatusNotFound, CodeNotFound, err.Error())
+	case errors.Is(err, query.ErrInvalidArgument):
+		writeError(w, http.StatusBadRequest, CodeInvalidArgument, err.Error())
+	case errors.As(err, new(*rejection.Error)):
+		rejected := rejection.FromError(err)
+		status := http.StatusBadRequest
+		if rejected.Code == rejection.RateLimited {
+			status = http.StatusTooManyRequests
+		}
+		writeError(w, status, string(rejected.Code), rejected.Message)
+	default:
+		logging.Errorln("Error answering query:", err)
+		writeError(w, http.StatusInternalServerError, CodeInternal, err.Error())
+	}
+}
+
+func writeJSON(w http.ResponseWriter, status int, value interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	err := json.NewEncoder(w).Encode(value)
+	if err != nil {
+		logging.Errorln("Error writing response:", err)
+	}
+}
+
+func writeError(w http.ResponseWriter, status int, code string, message string) {
+	writeJSON(w, status, struct {
+		Error apiError `json:"error"`
+	}{apiError{Code: code, Message: message}})
+}
+
+// pathParameter returns the part of the path after prefix, e.g. hash of /votings/{hash}
+func pathParameter(r *http.Request, prefix string) string {
+	return strings.Trim(strings.TrimPrefix(r.URL.Path, prefix), "/")
+}
+
+// parsePage reads offset and limit query parameters, they are validated by the query service
+func parsePage(w http.ResponseWriter, r *http.Request) (offset int, limit int, ok bool) {
+	var err error
+	if value := r.URL.Query().Get("offset"); value != "" {
+		offset, err = strconv.Atoi(value)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, CodeInvalidArgument, "offset must be an integer")
+			return 0, 0, false
+		}
+	}
+	if value := r.URL.Query().Get("limit"); value != "" {
+		limit, err = strconv.Atoi(value)
+		if err != nil || limit == 0 {
+			writeError(w, http.StatusBadRequest, CodeInvalidArgument, "limit must be a positive integer")
+			return 0, 0, false
+		}
+	}
+	return offset, limit, true
+}
+
+// HandleBlocks lists blocks from genesis on
+func (ra *RestApi) HandleBlocks(w http.ResponseWriter, r *http.Request) {
+	offset, limit, ok := parsePage(w, r)
+	if !ok {
+		return
+	}
+	page, err := ra.Query.Blocks(offset, limit)
+	writeResult(w, page, err)
+}
+
+// HandleBlock returns a block by its height or hash, /blocks/{height} or /blocks/{hash}
+func (ra *RestApi) HandleBlock(w http.ResponseWriter, r *http.Request) {
+	parameter := pathParameter(r, "/blocks/")
+
+	height, err := strconv.ParseUint(parameter, 10, 64)
+	if err == nil {
+		block, err := ra.Query.BlockByHeight(height)
+		writeResult(w, block, err)
+		return
+	}
+
+	block, err := ra.Query.BlockByHash(parameter)
+	writeResult(w, block, err)
+}
+
+// HandleTransaction returns a transaction included in the chain, /transactions/{hash},
+// /transactions/{hash}/proof returns the merkle path proving it is included in its block
+func (ra *RestApi) HandleTransaction(w http.ResponseWriter, r *http.Request) {
+	parameter := pathParameter(r, "/transactions/")
+	if strings.HasSuffix(parameter, "/proof") {
+		proof, err := ra.Query.Proof(strings.TrimSuffix(parameter, "/proof"))
+		writeResult(w, proof, err)
+		return
+	}
+
+	transaction, err := ra.Query.Transaction(parameter)
+	writeResult(w, transaction, err)
+}
+
+// HandleSubmitTransaction passes a signed transaction posted in the body to the validator
+// and answers with its hash once it is accepted to MemPool
+func (ra *RestApi) HandleSubmitTransaction(w http.ResponseWriter, r *http.Request) {
+	if ra.Submitter == nil {
+		writeError(w, http.StatusNotImplemented, CodeNotImplemented, "transactions are not accepted by this node")
+		return
+	}
+	err := ra.Admission.AdmitConnection(r.RemoteAddr)
+	if err != nil {
+		logging.Errorf("Transaction from %s not admitted: %v", r.RemoteAddr, err)
+		writeResult(w, nil, err)
+		return
+	}
+	body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, MaxBodySize))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, CodeInvalidArgument, err.Error())
+		return
+	}
+
+	transaction, err := (&transaction_json.JSONTransaction{}).UnmarshallJSON(body)
+	if err != nil {
+		writeResult(w, nil, err)
+		return
+	}
+	logging.Infof("Received new transaction with hash: %s", transaction.GetHashString())
+	err = ra.Submitter.SubmitTransaction(transaction)
+	if err != nil {
+		logging.Errorf("Transaction with hash %s rejected: %v", transaction.GetHashString(), err)
+		writeResult(w, nil, err)
+		return
+	}
+
+	hash := transaction.GetHash()
+	writeResult(w, SubmittedDTO{Hash: query.EncodeHex(hash[:])}, nil)
+}
+
+// SubmittedDTO answers an accepted transaction
+type SubmittedDTO struct {
+	Hash string `json:"hash"`
+}
+
+// HandlePrepareTransaction answers an unsigned transaction posted in the body with the payload the client signs,
+// the signed transaction is posted to /transactions
+func (ra *RestApi) HandlePrepareTransaction(w http.ResponseWriter, r *http.Request) {
+	body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, MaxBodySize))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, CodeInvalidArgument, err.Error())
+		return
+	}
+	prepared, err := ra.Query.Prepare(body)
+	writeResult(w, prepared, err)
+}
+
+// HandleVotings lists votings ordered by expiration date, ?status=active or ?status=finished filters them
+func (ra *RestApi) HandleVotings(w http.ResponseWriter, r *http.Request) {
+	offset, limit, ok := parsePage(w, r)
+	if !ok {
+		return
+	}
+	page, err := ra.Query.Votings(r.URL.Query().Get("status"), offset, limit)
+	writeResult(w, page, err)
+}
+
+// HandleVoting returns a voting with its whitelist and results, /votings/{hash},
+// /votings/{hash}/results returns the results only
+func (ra *RestApi) HandleVoting(w http.ResponseWriter, r *http.Request) {
+	parameter := pathParameter(r, "/votings/")
+	if strings.HasSuffix(parameter, "/results") {
+		results, err := ra.Query.Results(strings.TrimSuffix(parameter, "/results"))
+		writeResult(w, results, err)
+		return
+	}
+
+	voting, err := ra.Query.Voting(parameter)
+	writeResult(w, voting, err)
+}
+
+// HandleGroups lists groups ordered by identifier
+func (ra *RestApi) HandleGroups(w http.ResponseWriter, r *http.Request) {
+	offset, limit, ok := parsePage(w, r)
+	if !ok {
+		return
+	}
+	page, err := ra.Query.Groups(offset, limit)
+	writeResult(w, page, err)
+}
+
+// HandleGroup returns a group with its members, /groups/{identifier}
+func (ra *RestApi) HandleGroup(w http.ResponseWriter, r *http.Request) {
+	group, err := ra.Query.Group(pathParameter(r, "/groups/"))
+	writeResult(w, group, err)
+}
+
+// HandleAccount returns roles of a public key, /accounts/{public key}
+func (ra *RestApi) HandleAccount(w http.ResponseWriter, r *http.Request) {
+	account, err := ra.Query.Account(pathParameter(r, "/accounts/"))
+	writeResult(w, account, err)
+}
+
+// HandleMemPool returns the number of pending transactions
+func (ra *RestApi) HandleMemPool(w http.ResponseWriter, r *http.Request) {
+	writeResult(w, ra.Query.MemPoolStatus(), nil)
+}
@@ -0,0 +1,89 @@
+package rest_api
+
+import (
+	"encoding/hex"
+	"github.com/Digital-Voting-Team/Digital-Voting/pkg/blockchain"
+	blk "github.com/Digital-Voting-Team/Digital-Voting/pkg/blockchain/block"
+	tx "github.com/Digital-Voting-Team/Digital-Voting/pkg/blockchain/transaction"
+	ts "github.com/Digital-Voting-Team/Digital-Voting/pkg/blockchain/transaction/transaction_specific"
+	"github.com/Digital-Voting-Team/Digital-Voting/pkg/models/account"
+	"github.com/Digital-Voting-Team/Digital-Voting/pkg/rejection"
+	"github.com/Digital-Voting-Team/Digital-Voting/pkg/signature/keys"
+	"github.com/Digital-Voting-Team/Digital-Voting/pkg/validator"
+	"github.com/Digital-Voting-Team/Digital-Voting/pkg/validator/repository"
+	"github.com/Digital-Voting-Team/Digital-Voting/pkg/validator/repository/account_manager"
+	"github.com/Digital-Voting-Team/Digital-Voting/pkg/validator/repository/indexed_votings"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRestApi(t *testing.T) {
+	user := keys.PublicKeyBytes{1}
+	accountCreation := tx.NewTransaction(tx.AccountCreation, ts.NewTxAccCreation(account.User, user))
+	genesis := blk.NewGenesisBlock(blk.DefaultChainParams(), nil)
+	block := blk.NewBlock([]tx.ITransaction{accountCreation}, genesis.GetHash())
+	bc := &blockchain.Blockchain{Blocks: []*blk.Block{genesis, block}}
+
+	indexedData := repository.NewIndexedData()
+	indexedData.AccountManager.AddPubKey(user, account_manager.User)
+	active := indexed_votings.VotingDTO{
+		Hash:           [32]byte{1},
+		ExpirationDate: uint32(time.Now().Add(time.Hour).Unix()),
+		Answers:        [][256]byte{{'y', 'e', 's'}, {'n', 'o'}},
+		Whitelist:      [][33]byte{user},
+	}
+	finished := indexed_votings.VotingDTO{Hash: [32]byte{2}, ExpirationDate: 1, Answers: [][256]byte{{'y', 'e', 's'}}}
+	indexedData.VotingManager.AddNewVoting(active)
+	indexedData.VotingManager.AddNewVoting(finished)
+	indexedData.VotingManager.AddVote(active.Hash, user, 1)
+
+	api := NewRestApi("localhost:0", bc, indexedData, validator.NewMemPool())
+
+	blockHash := block.GetHash()
+	transactionHash := accountCreation.GetHash()
+	tests := []struct {
+		name       string
+		method     string
+		path       string
+		body       string
+		wantStatus int
+		wantBody   string
+	}{
+		{name: "Blocks page", path: "/blocks?offset=1&limit=1", wantStatus: http.StatusOK, wantBody: `"total":2,"offset":1,"limit":1`},
+		{name: "Block by height", path: "/blocks/1", wantStatus: http.StatusOK, wantBody: hex.EncodeToString(blockHash[:])},
+		{name: "Block by hash", path: "/blocks/" + hex.EncodeToString(blockHash[:]), wantStatus: http.StatusOK, wantBody: `"height":1`},
+		{name: "Block over the height", path: "/blocks/2", wantStatus: http.StatusNotFound, wantBody: CodeNotFound},
+		{name: "Invalid limit", path: "/blocks?limit=1000", wantStatus: http.StatusBadRequest, wantBody: CodeInvalidArgument},
+		{name: "Transaction", path: "/transactions/" + hex.EncodeToString(transactionHash[:]), wantStatus: http.StatusOK, wantBody: `"new_public_key":"` + hex.EncodeToString(user[:])},
+		{name: "Transaction proof", path: "/transactions/" + hex.EncodeToString(transactionHash[:]) + "/proof", wantStatus: http.StatusOK, wantBody: `"merkle_root":"` + hex.EncodeToString(block.Header.MerkleRoot[:])},
+		{name: "Active votings", path: "/votings?status=active", wantStatus: http.StatusOK, wantBody: `"total":1`},
+		{name: "Voting", path: "/votings/" + hex.EncodeToString(active.Hash[:]), wantStatus: http.StatusOK, wantBody: `"answers":["yes","no"]`},
+		{name: "Voting results", path: "/votings/" + hex.EncodeToString(active.Hash[:]) + "/results", wantStatus: http.StatusOK, wantBody: `"results":[0,1]`},
+		{name: "Unknown voting", path: "/votings/" + strings.Repeat("00", 32), wantStatus: http.StatusNotFound, wantBody: CodeNotFound},
+		{name: "Account roles", path: "/accounts/" + hex.EncodeToString(user[:]), wantStatus: http.StatusOK, wantBody: `"roles":["user"]`},
+		{name: "MemPool", path: "/mempool", wantStatus: http.StatusOK, wantBody: `"pending":0`},
+		{name: "Prepare transaction", method: http.MethodPost, path: "/transactions/prepare", body: `{"tx_type": 0, "tx_body": {"account_type": 0}}`, wantStatus: http.StatusOK, wantBody: `"signature_message"`},
+		{name: "Prepare with private key", method: http.MethodPost, path: "/transactions/prepare", body: `{"tx_type": 0, "private_key": [1]}`, wantStatus: http.StatusBadRequest, wantBody: string(rejection.PrivateKeySent)},
+		{name: "Not GET", method: http.MethodPost, path: "/mempool", wantStatus: http.StatusMethodNotAllowed, wantBody: CodeMethodNotAllowed},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			method := tt.method
+			if method == "" {
+				method = http.MethodGet
+			}
+			recorder := httptest.NewRecorder()
+			api.Handler().ServeHTTP(recorder, httptest.NewRequest(method, tt.path, strings.NewReader(tt.body)))
+
+			if recorder.Code != tt.wantStatus {
+				t.Errorf("status = %d, want %d", recorder.Code, tt.wantStatus)
+			}
+			if !strings.Contains(recorder.Body.String(), tt.wantBody) {
+				t.Errorf("body = %s, want it to contain %s", recorder.Body.String(), tt.wantBody)
+			}
+		})
+	}
+}
@@ -0,0 +1,635 @@
+package network_node
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/Digital-Voting-Team/Digital-Voting/pkg/logging"
+	"github.com/Digital-Voting-Team/Digital-Voting/pkg/signature/keys"
+	ss "github.com/Digital-Voting-Team/Digital-Voting/pkg/signature/signatures/single_signature"
+	"github.com/gorilla/websocket"
+	"log"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"sort"
+	"sync"
+	"time"
+)
+
+// PeersFile keeps known peers between restarts
+const PeersFile = "peers.json"
+
+// Peers are pinged every PingInterval, a peer that misses MaxPeerFailures pings in a row is not sent messages
+// until a handshake with it succeeds again. Discovery finds addresses of peers only, validators are the ones
+// recorded on chain whoever answers pings
+const (
+	PingInterval    = 10 * time.Second
+	PeerTimeout     = 5 * time.Second
+	MaxPeerFailures = 3
+)
+
+// MaxKnownPeers bounds peers learned through exchange, MaxExchangedPeers bounds peers accepted in one handshake
+// and MaxPeersPerSource bounds candidates a single peer may have in the table until they are verified.
+// A peer that is not live is forgotten after MaxDialFailures failed dials in a row, seeds are kept
+const (
+	MaxKnownPeers     = 256
+	MaxExchangedPeers = 64
+	MaxPeersPerSource = 16
+	MaxDialFailures   = 8
+)
+
+var (
+	ErrHandshakeSignature = errors.New("peer did not prove control of its validator key")
+	ErrSelfConnection     = errors.New("peer is this node")
+	ErrHandshakeTarget    = errors.New("peer dialed an address this node does not announce")
+)
+
+// PeerInfo is a peer as exchanged between nodes and kept in PeersFile
+type PeerInfo struct {
+	Hostname  string              `json:"hostname"`
+	PublicKey keys.PublicKeyBytes `json:"public_key"`
+	LastSeen  time.Time           `json:"last_seen,omitempty"`
+}
+
+// handshakeMessage is sent in three steps: the dialer announces itself with a challenge and the address
+// it dialed, the listener answers with its own challenge and a signature over the dialer's one,
+// then the dialer signs the listener's challenge. Each side also signs its ephemeral key,
+// the secure channel following the handshake is keyed with both.
+// The dialer offers the protocol versions it speaks and the listener answers with the one it picked,
+// both sign them along with their capabilities so they cannot be downgraded on the way
+type handshakeMessage struct {
+	Hostname     string                  `json:"hostname,omitempty"`
+	Target       string                  `json:"target,omitempty"`
+	PublicKey    keys.PublicKeyBytes     `json:"public_key"`
+	Challenge    [32]byte                `json:"challenge"`
+	Ephemeral    [32]byte                `json:"ephemeral"`
+	Versions     []uint16                `json:"versions,omitempty"`
+	Capabilities []string                `json:"capabilities,omitempty"`
+	Signature    ss.SingleSignatureBytes `json:"signature"`
+	Peers        []PeerInfo              `json:"peers,omitempty"`
+}
+
+// handshakeSignatureMessage binds the signature to the challenge of the other side, the announced address,
+// the ephemeral key, protocol versions and capabilities of the signer
+func handshakeSignatureMessage(challenge [32]byte, message handshakeMessage) string {
+	return fmt.Sprint(
+		"handshake",
+		hex.EncodeToString(challenge[:]),
+		message.Hostname,
+		hex.EncodeToString(message.PublicKey[:]),
+		hex.EncodeToString(message.Ephemeral[:]),
+		message.Versions,
+		message.Capabilities,
+	)
+}
+
+func verifyHandshake(challenge [32]byte, message handshakeMessage) bool {
+	return ss.NewECDSA().VerifyEdDSABytes(handshakeSignatureMessage(challenge, message), message.PublicKey, message.Signature)
+}
+
+func newChallenge() ([32]byte, error) {
+	challenge := [32]byte{}
+	_, err := rand.Read(challenge[:])
+	return challenge, err
+}
+
+// peer is a known peer, it is live once a handshake over its hostname succeeded and verified once
+// a handshake ever succeeded. Candidates learned from another peer count against the source until verified
+type peer struct {
+	PeerInfo
+	live     bool
+	verified bool
+	seed     bool
+	source   keys.PublicKeyBytes
+	failures int
+}
+
+// peerTable holds peers known to the node by hostname
+type peerTable struct {
+	mutex   sync.Mutex
+	peers   map[string]*peer
+	sources map[keys.PublicKeyBytes]int
+}
+
+func newPeerTable() *peerTable {
+	return &peerTable{peers: map[string]*peer{}, sources: map[keys.PublicKeyBytes]int{}}
+}
+
+// addCandidate remembers a hostname learned from source, it is verified by the next handshake
+func (pt *peerTable) addCandidate(info PeerInfo, source keys.PublicKeyBytes) {
+	pt.mutex.Lock()
+	defer pt.mutex.Unlock()
+	if pt.sources[source] >= MaxPeersPerSource {
+		return
+	}
+	if pt.add(&peer{PeerInfo: info, source: source}) {
+		pt.sources[source]++
+	}
+}
+
+// addSeed remembers a configured hostname, it is never forgotten
+func (pt *peerTable) addSeed(hostname string) {
+	pt.mutex.Lock()
+	defer pt.mutex.Unlock()
+	if known, exists := pt.peers[hostname]; exists {
+		pt.releaseSource(known)
+		known.seed = true
+		return
+	}
+	pt.add(&peer{PeerInfo: PeerInfo{Hostname: hostname}, seed: true})
+}
+
+// addVerified remembers a peer a handshake succeeded with before, such as a peer from PeersFile
+func (pt *peerTable) addVerified(info PeerInfo) {
+	pt.mutex.Lock()
+	defer pt.mutex.Unlock()
+	pt.add(&peer{PeerInfo: info, verified: true})
+}
+
+func (pt *peerTable) add(known *peer) bool {
+	if _, exists := pt.peers[known.Hostname]; exists || len(pt.peers) >= MaxKnownPeers {
+		return false
+	}
+	pt.peers[known.Hostname] = known
+	return true
+}
+
+// remove forgets a peer along with its place in the candidates of its source
+func (pt *peerTable) remove(hostname string) {
+	known, exists := pt.peers[hostname]
+	if !exists {
+		return
+	}
+	pt.releaseSource(known)
+	delete(pt.peers, hostname)
+}
+
+func (pt *peerTable) releaseSource(known *peer) {
+	if known.verified || known.seed {
+		return
+	}
+	pt.sources[known.source]--
+	if pt.sources[known.source] <= 0 {
+		delete(pt.sources, known.source)
+	}
+}
+
+// markLive records a successful handshake, it returns true if the live set changed
+func (pt *peerTable) markLive(hostname string, publicKey keys.PublicKeyBytes) bool {
+	pt.mutex.Lock()
+	defer pt.mutex.Unlock()
+	known, exists := pt.peers[hostname]
+	if !exists {
+		known = &peer{PeerInfo: PeerInfo{Hostname: hostname}}
+		pt.peers[hostname] = known
+	}
+	pt.releaseSource(known)
+	changed := !known.live || known.PublicKey != publicKey
+	known.PublicKey = publicKey
+	known.LastSeen = time.Now()
+	known.live = true
+	known.verified = true
+	known.failures = 0
+	return changed
+}
+
+// markFailed counts a failed ping or handshake, it returns true if the peer stopped being live.
+// A peer that is not live is forgotten once it fails MaxDialFailures times
+func (pt *peerTable) markFailed(hostname string) bool {
+	pt.mutex.Lock()
+	defer pt.mutex.Unlock()
+	known, exists := pt.peers[hostname]
+	if !exists {
+		return false
+	}
+	known.failures++
+	if known.live && known.failures >= MaxPeerFailures {
+		known.live = false
+		return true
+	}
+	if !known.live && !known.seed && known.failures >= MaxDialFailures {
+		pt.remove(hostname)
+	}
+	return false
+}
+
+// markSeen records an answered ping
+func (pt *peerTable) markSeen(hostname string) {
+	pt.mutex.Lock()
+	defer pt.mutex.Unlock()
+	if known, exists := pt.peers[hostname]; exists {
+		known.LastSeen = time.Now()
+		known.failures = 0
+	}
+}
+
+// snapshot returns copies of live and not live peers ordered by hostname
+func (pt *peerTable) snapshot() (live []PeerInfo, other []PeerInfo) {
+	pt.mutex.Lock()
+	defer pt.mutex.Unlock()
+	for _, known := range pt.peers {
+		if known.live {
+			live = append(live, known.PeerInfo)
+		} else {
+			other = append(other, known.PeerInfo)
+		}
+	}
+	sort.Slice(live, func(i, j int) bool { return live[i].Hostname < live[j].Hostname })
+	sort.Slice(other, func(i, j int) bool { return other[i].Hostname < other[j].Hostname })
+	return live, other
+}
+
+// shouldRetry spaces handshakes with a failing peer exponentially in rounds, up to 32 rounds apart
+func (pt *peerTable) shouldRetry(hostname string, round int) bool {
+	pt.mutex.Lock()
+	defer pt.mutex.Unlock()
+	known, exists := pt.peers[hostname]
+	if !exists || known.failures == 0 {
+		return true
+	}
+	shift := known.failures
+	if shift > 5 {
+		shift = 5
+	}
+	return round%(1<<shift) == 0
+}
+
+// verifiedPeers returns copies of peers a handshake succeeded with ordered by hostname
+func (pt *peerTable) verifiedPeers() []PeerInfo {
+	pt.mutex.Lock()
+	defer pt.mutex.Unlock()
+	verified := []PeerInfo{}
+	for _, known := range pt.peers {
+		if known.verified {
+			verified = append(verified, known.PeerInfo)
+		}
+	}
+	sort.Slice(verified, func(i, j int) bool { return verified[i].Hostname < verified[j].Hostname })
+	return verified
+}
+
+// save keeps only peers a handshake succeeded with, candidates nobody verified are not persisted
+func (pt *peerTable) save(path string) error {
+	marshalled, err := json.MarshalIndent(pt.verifiedPeers(), "", "  ")
+	if err != nil {
+		return err
+	}
+	temporary := path + ".tmp"
+	err = os.WriteFile(temporary, marshalled, 0o600)
+	if err != nil {
+		return err
+	}
+	return os.Rename(temporary, path)
+}
+
+func (pt *peerTable) load(path string) error {
+	marshalled, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	infos := []PeerInfo{}
+	err = json.Unmarshal(marshalled, &infos)
+	if err != nil {
+		return err
+	}
+	for _, info := range infos {
+		if validHostname(info.Hostname) {
+			pt.addVerified(info)
+		}
+	}
+	return nil
+}
+
+func validHostname(hostname string) bool {
+	host, port, err := net.SplitHostPort(hostname)
+	return err == nil && host != "" && port != ""
+}
+
+// HandleWebSocketHandshake authenticates a dialing peer and exchanges known peers with it,
+// the dialer's hostname is verified by a handshake in the other direction before it becomes live
+func (n *NetworkNode) HandleWebSocketHandshake(w http.ResponseWriter, r *http.Request) {
+	conn, err := n.upgrade(w, r)
+	if err != nil {
+		logging.Errorln("WebSocket upgrade failed:", err)
+		return
+	}
+	defer n.release(conn)
+
+	_, _, err = n.acceptHandshake(conn)
+	if err != nil {
+		logging.Errorf("Handshake from %s failed: %v", r.RemoteAddr, err)
+	}
+}
+
+// acceptHandshake runs the listener side of a handshake on conn and returns the secure channel
+// over it with the verified key of the dialer
+func (n *NetworkNode) acceptHandshake(conn *websocket.Conn) (*secureConn, keys.PublicKeyBytes, error) {
+	_ = conn.SetReadDeadline(time.Now().Add(PeerTimeout))
+	defer conn.SetReadDeadline(time.Time{})
+
+	hello := handshakeMessage{}
+	err := conn.ReadJSON(&hello)
+	if err != nil {
+		return nil, keys.PublicKeyBytes{}, err
+	}
+	version, ok := negotiateVersion(hello.Versions)
+	if !ok {
+		closing := websocket.FormatCloseMessage(websocket.ClosePolicyViolation, ErrProtocolVersion.Error())
+		_ = conn.WriteControl(websocket.CloseMessage, closing, time.Now().Add(PeerTimeout))
+		return nil, keys.PublicKeyBytes{}, ErrProtocolVersion
+	}
+	// the listener signs only its own address, otherwise a node relaying handshakes could have
+	// the key of another node vouch for the relay's address
+	if hello.Target != n.hostname {
+		closing := websocket.FormatCloseMessage(websocket.ClosePolicyViolation, ErrHandshakeTarget.Error())
+		_ = conn.WriteControl(websocket.CloseMessage, closing, time.Now().Add(PeerTimeout))
+		return nil, keys.PublicKeyBytes{}, ErrHandshakeTarget
+	}
+	challenge, err := newChallenge()
+	if err != nil {
+		return nil, keys.PublicKeyBytes{}, err
+	}
+	ephemeral, err := newEphemeralKey()
+	if err != nil {
+		return nil, keys.PublicKeyBytes{}, err
+	}
+	err = conn.WriteJSON(n.signedHandshake(hello.Challenge, challenge, n.hostname, ephemeral.public, []uint16{version}))
+	if err != nil {
+		return nil, keys.PublicKeyBytes{}, err
+	}
+
+	answer := handshakeMessage{}
+	err = conn.ReadJSON(&answer)
+	if err != nil {
+		return nil, keys.PublicKeyBytes{}, err
+	}
+	answer.Hostname, answer.PublicKey, answer.Ephemeral = hello.Hostname, hello.PublicKey, hello.Ephemeral
+	answer.Versions, answer.Capabilities = hello.Versions, hello.Capabilities
+	if !verifyHandshake(challenge, answer) {
+		return nil, keys.PublicKeyBytes{}, ErrHandshakeSignature
+	}
+	secure, err := newSecureConn(conn, ephemeral, hello.Ephemeral, hello.Challenge, challenge, false)
+	if err != nil {
+		return nil, keys.PublicKeyBytes{}, err
+	}
+	secure.version, secure.capabilities = version, hello.Capabilities
+
+	n.learnPeers(answer.Peers, hello.PublicKey)
+	if validHostname(hello.Hostname) && hello.Hostname != n.hostname {
+		n.peers.addCandidate(PeerInfo{Hostname: hello.Hostname, PublicKey: hello.PublicKey}, hello.PublicKey)
+	}
+	return secure, hello.PublicKey, nil
+}
+
+// signedHandshake answers challenge of the other side for hostname and sends own challenge with known live peers,
+// versions are the offered ones for the dialer and the picked one for the listener
+func (n *NetworkNode) signedHandshake(theirChallenge [32]byte, ownChallenge [32]byte, hostname string, ephemeral [32]byte, versions []uint16) handshakeMessage {
+	live, _ := n.peers.snapshot()
+	if len(live) > MaxExchangedPeers {
+		live = live[:MaxExchangedPeers]
+	}
+	message := handshakeMessage{
+		Hostname:     hostname,
+		PublicKey:    n.MyPublicKey,
+		Ephemeral:    ephemeral,
+		Versions:     versions,
+		Capabilities: Capabilities,
+	}
+	message.Signature = n.Validator.Sign(handshakeSignatureMessage(theirChallenge, message))
+	message.Hostname, message.Challenge, message.Peers = n.hostname, ownChallenge, live
+	return message
+}
+
+// learnPeers adds peers exchanged by the peer with key source as candidates
+func (n *NetworkNode) learnPeers(peers []PeerInfo, source keys.PublicKeyBytes) {
+	if len(peers) > MaxExchangedPeers {
+		peers = peers[:MaxExchangedPeers]
+	}
+	for _, info := range peers {
+		if validHostname(info.Hostname) && info.Hostname != n.hostname {
+			n.peers.addCandidate(PeerInfo{Hostname: info.Hostname, PublicKey: info.PublicKey}, source)
+		}
+	}
+}
+
+// handshake dials hostname and checks the peer there controls the key it announces
+func (n *NetworkNode) handshake(ctx context.Context, hostname string) (keys.PublicKeyBytes, error) {
+	conn, publicKey, err := n.dialAuthenticated(ctx, hostname, "handshake")
+	if err != nil {
+		return keys.PublicKeyBytes{}, err
+	}
+	_ = conn.Close()
+	return publicKey, nil
+}
+
+// dialAuthenticated runs a handshake over a websocket to path of hostname and returns the secure channel over it
+func (n *NetworkNode) dialAuthenticated(ctx context.Context, hostname string, path string) (*secureConn, keys.PublicKeyBytes, error) {
+	ctx, cancel := context.WithTimeout(ctx, PeerTimeout)
+	defer cancel()
+	u := url.URL{Scheme: "ws", Host: hostname, Path: path}
+	conn, _, err := websocket.DefaultDialer.DialContext(ctx, u.String(), nil)
+	if err != nil {
+		return nil, keys.PublicKeyBytes{}, err
+	}
+	secure, publicKey, err := n.dialerHandshake(ctx, conn, hostname)
+	if err != nil {
+		_ = conn.Close()
+		return nil, keys.PublicKeyBytes{}, err
+	}
+	return secure, publicKey, nil
+}
+
+func (n *NetworkNode) dialerHandshake(ctx context.Context, conn *websocket.Conn, hostname string) (*secureConn, keys.PublicKeyBytes, error) {
+	deadline, _ := ctx.Deadline()
+	_ = conn.SetReadDeadline(deadline)
+	defer conn.SetReadDeadline(time.Time{})
+
+	challenge, err := newChallenge()
+	if err != nil {
+		return nil, keys.PublicKeyBytes{}, err
+	}
+	ephemeral, err := newEphemeralKey()
+	if err != nil {
+		return nil, keys.PublicKeyBytes{}, err
+	}
+	err = conn.WriteJSON(handshakeMessage{
+		Hostname:     n.hostname,
+		Target:       hostname,
+		PublicKey:    n.MyPublicKey,
+		Challenge:    challenge,
+		Ephemeral:    ephemeral.public,
+		Versions:     SupportedVersions,
+		Capabilities: Capabilities,
+	})
+	if err != nil {
+		return nil, keys.PublicKeyBytes{}, err
+	}
+
+	answer := handshakeMessage{}
+	err = conn.ReadJSON(&answer)
+	if err != nil {
+		return nil, keys.PublicKeyBytes{}, err
+	}
+	if answer.PublicKey == n.MyPublicKey {
+		return nil, keys.PublicKeyBytes{}, ErrSelfConnection
+	}
+	answer.Hostname = hostname
+	if !verifyHandshake(challenge, answer) {
+		return nil, keys.PublicKeyBytes{}, ErrHandshakeSignature
+	}
+	if len(answer.Versions) != 1 || !containsVersion(SupportedVersions, answer.Versions[0]) {
+		return nil, keys.PublicKeyBytes{}, ErrProtocolVersion
+	}
+	secure, err := newSecureConn(conn, ephemeral, answer.Ephemeral, challenge, answer.Challenge, true)
+	if err != nil {
+		return nil, keys.PublicKeyBytes{}, err
+	}
+	secure.version, secure.capabilities = answer.Versions[0], answer.Capabilities
+
+	err = conn.WriteJSON(n.signedHandshake(answer.Challenge, [32]byte{}, n.hostname, ephemeral.public, SupportedVersions))
+	if err != nil {
+		return nil, keys.PublicKeyBytes{}, err
+	}
+	n.learnPeers(answer.Peers, answer.PublicKey)
+	return secure, answer.PublicKey, nil
+}
+
+// ping checks a peer answers a ping control message on /ping
+func (n *NetworkNode) ping(ctx context.Context, hostname string) error {
+	ctx, cancel := context.WithTimeout(ctx, PeerTimeout)
+	defer cancel()
+	u := url.URL{Scheme: "ws", Host: hostname, Path: "ping"}
+	conn, _, err := websocket.DefaultDialer.DialContext(ctx, u.String(), nil)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	deadline, _ := ctx.Deadline()
+	errPong := errors.New("pong")
+	conn.SetPongHandler(func(string) error { return errPong })
+	err = conn.WriteControl(websocket.PingMessage, nil, deadline)
+	if err != nil {
+		return err
+	}
+	_ = conn.SetReadDeadline(deadline)
+	_, _, err = conn.ReadMessage()
+	if err == errPong {
+		return nil
+	}
+	return err
+}
+
+// discoverRound pings live peers, handshakes with the rest and refreshes peers from one live peer
+func (n *NetworkNode) discoverRound(ctx context.Context, round int) {
+	live, other := n.peers.snapshot()
+	changed := false
+	var changedMutex sync.Mutex
+	markChanged := func() {
+		changedMutex.Lock()
+		changed = true
+		changedMutex.Unlock()
+	}
+	markFailed := func(hostname string, err error) {
+		if n.peers.markFailed(hostname) {
+			logging.Errorf("Peer %s stopped answering: %v", hostname, err)
+			markChanged()
+		}
+	}
+	wg := sync.WaitGroup{}
+
+	for _, info := range live {
+		wg.Add(1)
+		go func(hostname string) {
+			defer wg.Done()
+			err := n.ping(ctx, hostname)
+			if err == nil {
+				n.peers.markSeen(hostname)
+				return
+			}
+			markFailed(hostname, err)
+		}(info.Hostname)
+	}
+
+	handshakes := []string{}
+	for _, info := range other {
+		if n.peers.shouldRetry(info.Hostname, round) {
+			handshakes = append(handshakes, info.Hostname)
+		}
+	}
+	// exchanging peers with one live peer per round lets the network learn about nodes that joined elsewhere
+	if len(live) > 0 {
+		handshakes = append(handshakes, live[round%len(live)].Hostname)
+	}
+	for _, hostname := range handshakes {
+		wg.Add(1)
+		go func(hostname string) {
+			defer wg.Done()
+			publicKey, err := n.handshake(ctx, hostname)
+			if err != nil {
+				markFailed(hostname, err)
+				return
+			}
+			if n.peers.markLive(hostname, publicKey) {
+				log.Printf("Connected to peer %s", hostname)
+				markChanged()
+			}
+		}(hostname)
+	}
+	wg.Wait()
+
+	if changed {
+		n.applyPeers()
+	}
+}
+
+// applyPeers makes live peers the node list messages are sent to
+func (n *NetworkNode) applyPeers() {
+	live, _ := n.peers.snapshot()
+	n.setNodeList(live)
+
+	if n.PeersFile != "" {
+		err := n.peers.save(n.PeersFile)
+		if err != nil {
+			logging.Errorln("Error saving peers:", err)
+		}
+	}
+}
+
+// discover keeps peers up to date until ctx is done, it starts from SeedPeers and peers saved in PeersFile
+func (n *NetworkNode) discover(ctx context.Context) {
+	if n.PeersFile != "" {
+		err := n.peers.load(n.PeersFile)
+		if err != nil && !os.IsNotExist(err) {
+			logging.Errorln("Error loading peers:", err)
+		}
+	}
+	for _, hostname := range n.SeedPeers {
+		if validHostname(hostname) && hostname != n.hostname {
+			n.peers.addSeed(hostname)
+		}
+	}
+	n.applyPeers()
+
+	ticker := time.NewTicker(PingInterval)
+	defer ticker.Stop()
+	for round := 0; ; round++ {
+		n.discoverRound(ctx, round)
+
+		select {
+		case <-ctx.Done():
+			if n.PeersFile != "" {
+				if err := n.peers.save(n.PeersFile); err != nil {
+					logging.Errorln("Error saving peers:", err)
+				}
+			}
+			return
+		case <-ticker.C:
+		}
+	}
+}
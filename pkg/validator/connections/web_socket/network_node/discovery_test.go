@@ -0,0 +1,241 @@
+package network_node
+
+import (
+	"context"
+	"fmt"
+	"github.com/Digital-Voting-Team/Digital-Voting/pkg/signature/curve"
+	"github.com/Digital-Voting-Team/Digital-Voting/pkg/signature/keys"
+	ss "github.com/Digital-Voting-Team/Digital-Voting/pkg/signature/signatures/single_signature"
+	"github.com/gorilla/websocket"
+	"net"
+	"net/http"
+	"net/url"
+	"path/filepath"
+	"testing"
+)
+
+// keyValidator signs handshakes with keyPair, announced is its claimed key
+type keyValidator struct {
+	testValidator
+	keyPair   *keys.KeyPair
+	announced keys.PublicKeyBytes
+}
+
+func newKeyValidator() *keyValidator {
+	keyPair, _ := keys.Random(curve.NewCurve25519())
+	return &keyValidator{keyPair: keyPair, announced: keyPair.PublicToBytes()}
+}
+
+func (kv *keyValidator) PublicKey() keys.PublicKeyBytes { return kv.announced }
+
+func (kv *keyValidator) Sign(message string) ss.SingleSignatureBytes {
+	ecdsa := ss.NewECDSA()
+	signature := ecdsa.SignEdDSA(message, kv.keyPair.GetPrivateKey(), kv.keyPair.GetPublicKey())
+	return ecdsa.EdwardsToSingleSignature(signature).EdwardsSignatureToBytes()
+}
+
+// startTestNode serves a network node on a free local port until the test ends or stop is called
+func startTestNode(t *testing.T, v Validator) (node *NetworkNode, stop func()) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	node = NewNetworkNode(listener.Addr().String(), v)
+	server := &http.Server{Handler: node.server.Handler}
+	go func() { _ = server.Serve(listener) }()
+	t.Cleanup(func() { _ = server.Close() })
+	return node, func() { _ = server.Close() }
+}
+
+func TestDiscovery(t *testing.T) {
+	ctx := context.Background()
+	validators := []*keyValidator{newKeyValidator(), newKeyValidator(), newKeyValidator()}
+	nodes := make([]*NetworkNode, len(validators))
+	stops := make([]func(), len(validators))
+	for i, v := range validators {
+		nodes[i], stops[i] = startTestNode(t, v)
+	}
+	// the first node is the seed of the others
+	for _, node := range nodes[1:] {
+		node.peers.addSeed(nodes[0].hostname)
+	}
+
+	for round := 0; round < 4; round++ {
+		for _, node := range nodes {
+			node.discoverRound(ctx, round)
+		}
+	}
+
+	t.Run("Every node finds the others", func(t *testing.T) {
+		for i, node := range nodes {
+			live, _ := node.peers.snapshot()
+			if len(live) != len(nodes)-1 {
+				t.Errorf("node %d has live peers %v, want %d", i, live, len(nodes)-1)
+			}
+			for j, other := range nodes {
+				if i != j && node.NodeKeys[validators[j].PublicKey()] != other.hostname {
+					t.Errorf("node %d maps key of node %d to %q, want %q", i, j, node.NodeKeys[validators[j].PublicKey()], other.hostname)
+				}
+			}
+		}
+	})
+
+	t.Run("Node that stops answering is dropped", func(t *testing.T) {
+		stops[2]()
+		for round := 0; round < MaxPeerFailures; round++ {
+			nodes[1].discoverRound(ctx, round)
+		}
+		if _, exists := nodes[1].NodeKeys[validators[2].PublicKey()]; exists || len(nodes[1].NodeList) != 1 {
+			t.Errorf("node list = %v, want the stopped node dropped", nodes[1].NodeList)
+		}
+	})
+}
+
+func TestHandshake(t *testing.T) {
+	ctx := context.Background()
+	honest, _ := startTestNode(t, newKeyValidator())
+	impostor := newKeyValidator()
+	impostor.announced = newKeyValidator().PublicKey()
+	impostorNode, _ := startTestNode(t, impostor)
+	self := newKeyValidator()
+	selfNode, _ := startTestNode(t, self)
+	dialer, _ := startTestNode(t, newKeyValidator())
+
+	tests := []struct {
+		name     string
+		dialer   *NetworkNode
+		hostname string
+		wantKey  keys.PublicKeyBytes
+		wantErr  error
+	}{
+		{name: "Peer proves its key", dialer: dialer, hostname: honest.hostname, wantKey: honest.MyPublicKey},
+		{name: "Peer announces a key it does not control", dialer: dialer, hostname: impostorNode.hostname, wantErr: ErrHandshakeSignature},
+		{name: "Node dials itself", dialer: selfNode, hostname: selfNode.hostname, wantErr: ErrSelfConnection},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			publicKey, err := tt.dialer.handshake(ctx, tt.hostname)
+			if err != tt.wantErr {
+				t.Fatalf("handshake() error = %v, want %v", err, tt.wantErr)
+			}
+			if publicKey != tt.wantKey {
+				t.Errorf("handshake() = %x, want %x", publicKey, tt.wantKey)
+			}
+		})
+	}
+}
+
+func TestHandshake_Target(t *testing.T) {
+	listener, _ := startTestNode(t, newKeyValidator())
+	dialer, _ := startTestNode(t, newKeyValidator())
+
+	// a relay connected to the listener asks it to sign for the relay's address
+	u := url.URL{Scheme: "ws", Host: listener.hostname, Path: "handshake"}
+	conn, _, err := websocket.DefaultDialer.Dial(u.String(), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	ctx, cancel := context.WithTimeout(context.Background(), PeerTimeout)
+	defer cancel()
+	_, publicKey, err := dialer.dialerHandshake(ctx, conn, "10.0.0.1:9000")
+	if err == nil || publicKey != (keys.PublicKeyBytes{}) {
+		t.Errorf("dialerHandshake() = %x, %v, want error", publicKey, err)
+	}
+}
+
+func TestPeerTable_SaveLoad(t *testing.T) {
+	path := filepath.Join(t.TempDir(), PeersFile)
+	table := newPeerTable()
+	table.addCandidate(PeerInfo{Hostname: "localhost:8081"}, keys.PublicKeyBytes{1})
+	table.markLive("localhost:8082", keys.PublicKeyBytes{2})
+	if err := table.save(path); err != nil {
+		t.Fatalf("save() error = %v", err)
+	}
+
+	loaded := newPeerTable()
+	loaded.addCandidate(PeerInfo{Hostname: "invalid"}, keys.PublicKeyBytes{1})
+	if err := loaded.load(path); err != nil {
+		t.Fatalf("load() error = %v", err)
+	}
+	live, other := loaded.snapshot()
+	if len(live) != 0 || len(other) != 2 || other[1].Hostname != "localhost:8082" || other[1].PublicKey != (keys.PublicKeyBytes{2}) {
+		t.Errorf("loaded peers = %v, %v, want only the handshaken peer to be saved and verified again", live, other)
+	}
+}
+
+func TestPeerTable_AddCandidate(t *testing.T) {
+	table := newPeerTable()
+	source, other := keys.PublicKeyBytes{1}, keys.PublicKeyBytes{2}
+	for i := 0; i < MaxPeersPerSource+4; i++ {
+		table.addCandidate(PeerInfo{Hostname: fmt.Sprintf("localhost:%d", 9000+i)}, source)
+	}
+	table.addCandidate(PeerInfo{Hostname: "localhost:8081"}, other)
+
+	_, candidates := table.snapshot()
+	if len(candidates) != MaxPeersPerSource+1 {
+		t.Errorf("table has %d candidates, want %d of the source and 1 of the other peer", len(candidates), MaxPeersPerSource)
+	}
+
+	// a verified candidate no longer counts against its source
+	table.markLive("localhost:9000", keys.PublicKeyBytes{3})
+	table.addCandidate(PeerInfo{Hostname: "localhost:8082"}, source)
+	if _, exists := table.peers["localhost:8082"]; !exists {
+		t.Errorf("candidate of the source was not accepted after another one was verified")
+	}
+}
+
+func TestPeerTable_MarkFailed(t *testing.T) {
+	tests := []struct {
+		name      string
+		add       func(table *peerTable)
+		failures  int
+		wantKnown bool
+	}{
+		{
+			name: "Candidate is kept until MaxDialFailures",
+			add: func(table *peerTable) {
+				table.addCandidate(PeerInfo{Hostname: "localhost:8081"}, keys.PublicKeyBytes{1})
+			},
+			failures:  MaxDialFailures - 1,
+			wantKnown: true,
+		},
+		{
+			name: "Candidate is forgotten after MaxDialFailures",
+			add: func(table *peerTable) {
+				table.addCandidate(PeerInfo{Hostname: "localhost:8081"}, keys.PublicKeyBytes{1})
+			},
+			failures:  MaxDialFailures,
+			wantKnown: false,
+		},
+		{
+			name: "Live peer is forgotten after it stops answering",
+			add: func(table *peerTable) {
+				table.markLive("localhost:8081", keys.PublicKeyBytes{2})
+			},
+			failures:  MaxDialFailures,
+			wantKnown: false,
+		},
+		{
+			name:      "Seed is kept",
+			add:       func(table *peerTable) { table.addSeed("localhost:8081") },
+			failures:  MaxDialFailures * 2,
+			wantKnown: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			table := newPeerTable()
+			tt.add(table)
+			for i := 0; i < tt.failures; i++ {
+				table.markFailed("localhost:8081")
+			}
+			if _, known := table.peers["localhost:8081"]; known != tt.wantKnown {
+				t.Errorf("peer known = %v, want %v", known, tt.wantKnown)
+			}
+			if tt.wantKnown == false && len(table.sources) != 0 {
+				t.Errorf("sources = %v, want forgotten candidates to release their source", table.sources)
+			}
+		})
+	}
+}
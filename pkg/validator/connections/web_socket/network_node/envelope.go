@@ -0,0 +1,97 @@
+package network_node
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/Digital-Voting-Team/Digital-Voting/pkg/signature/keys"
+	ss "github.com/Digital-Voting-Team/Digital-Voting/pkg/signature/signatures/single_signature"
+)
+
+// ProtocolVersion is the newest version of peer messages this node speaks,
+// peers agree on the newest version both of them speak in the handshake
+const ProtocolVersion uint16 = 1
+
+// SupportedVersions are the versions of peer messages this node speaks, a version is dropped from here
+// once no node of the network runs a release speaking only that version
+var SupportedVersions = []uint16{ProtocolVersion}
+
+// Capabilities are the kinds of messages this node serves, peers are sent only the kinds they announced
+var Capabilities = []string{consensusFrame, gossipFrame, forwardFrame}
+
+var (
+	ErrProtocolVersion = errors.New("peer speaks no supported protocol version")
+	ErrUnsupportedKind = errors.New("peer does not serve messages of the kind")
+	ErrEnvelope        = errors.New("message is not signed by the peer or is of unknown version or kind")
+)
+
+// envelope wraps every message between peers. Requests carry an ID, replies carry the ID
+// of their request in ReplyTo and notifications have neither. The sender signs all other fields,
+// so a message is attributed to its validator and not only to the connection it came over
+type envelope struct {
+	Version   uint16                  `json:"version"`
+	ID        uint64                  `json:"id,omitempty"`
+	ReplyTo   uint64                  `json:"reply_to,omitempty"`
+	Kind      string                  `json:"kind,omitempty"`
+	Sender    keys.PublicKeyBytes     `json:"sender"`
+	Payload   json.RawMessage         `json:"payload"`
+	Signature ss.SingleSignatureBytes `json:"signature"`
+}
+
+func (e *envelope) GetSignatureMessage() string {
+	payloadHash := sha256.Sum256(e.Payload)
+	return fmt.Sprint("envelope", e.Version, e.ID, e.ReplyTo, e.Kind, hex.EncodeToString(e.Sender[:]), hex.EncodeToString(payloadHash[:]))
+}
+
+// sign seals the envelope for version with the key of the node
+func (n *NetworkNode) sign(e *envelope, version uint16) {
+	e.Version = version
+	e.Sender = n.MyPublicKey
+	e.Signature = n.Validator.Sign(e.GetSignatureMessage())
+}
+
+// checkEnvelope accepts envelopes of the version negotiated on conn signed by publicKey the peer authenticated with,
+// requests and notifications also have to be of a kind this node serves
+func checkEnvelope(e *envelope, conn *secureConn, publicKey keys.PublicKeyBytes) error {
+	if e.Version != conn.version || e.Sender != publicKey {
+		return ErrEnvelope
+	}
+	if e.ReplyTo == 0 && !supports(Capabilities, e.Kind) {
+		return ErrEnvelope
+	}
+	if !ss.NewECDSA().VerifyEdDSABytes(e.GetSignatureMessage(), e.Sender, e.Signature) {
+		return ErrEnvelope
+	}
+	return nil
+}
+
+// negotiateVersion picks the newest version of offered this node speaks, neither list has to be ordered
+func negotiateVersion(offered []uint16) (uint16, bool) {
+	newest, found := uint16(0), false
+	for _, version := range offered {
+		if containsVersion(SupportedVersions, version) && (!found || version > newest) {
+			newest, found = version, true
+		}
+	}
+	return newest, found
+}
+
+func containsVersion(versions []uint16, version uint16) bool {
+	for _, other := range versions {
+		if other == version {
+			return true
+		}
+	}
+	return false
+}
+
+func supports(capabilities []string, kind string) bool {
+	for _, capability := range capabilities {
+		if capability == kind {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,126 @@
+package network_node
+
+import (
+	"context"
+	"encoding/json"
+	ss "github.com/Digital-Voting-Team/Digital-Voting/pkg/signature/signatures/single_signature"
+	"github.com/gorilla/websocket"
+	"net/url"
+	"testing"
+	"time"
+)
+
+func TestNegotiateVersion(t *testing.T) {
+	supported := SupportedVersions
+	t.Cleanup(func() { SupportedVersions = supported })
+
+	tests := []struct {
+		name      string
+		supported []uint16
+		offered   []uint16
+		want      uint16
+		wantOk    bool
+	}{
+		{name: "Newest common version", supported: []uint16{3, 2}, offered: []uint16{1, 2, 3}, want: 3, wantOk: true},
+		{name: "Offered in descending order", supported: []uint16{3, 2}, offered: []uint16{3, 2, 1}, want: 3, wantOk: true},
+		{name: "Supported in ascending order", supported: []uint16{2, 3}, offered: []uint16{1, 2, 3}, want: 3, wantOk: true},
+		{name: "Older peer", supported: []uint16{3, 2}, offered: []uint16{1, 2}, want: 2, wantOk: true},
+		{name: "Newer peer", supported: []uint16{2, 3}, offered: []uint16{2, 3, 4}, want: 3, wantOk: true},
+		{name: "No common version", supported: []uint16{3, 2}, offered: []uint16{1, 4}, wantOk: false},
+		{name: "Peer offers no versions", supported: []uint16{3, 2}, offered: nil, wantOk: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			SupportedVersions = tt.supported
+			got, ok := negotiateVersion(tt.offered)
+			if got != tt.want || ok != tt.wantOk {
+				t.Errorf("negotiateVersion() = %v, %v, want %v, %v", got, ok, tt.want, tt.wantOk)
+			}
+		})
+	}
+}
+
+func TestCheckEnvelope(t *testing.T) {
+	node := NewNetworkNode("127.0.0.1:0", newKeyValidator())
+	conn := &secureConn{version: ProtocolVersion}
+	signed := func(edit func(e *envelope)) *envelope {
+		e := &envelope{ID: 1, Kind: forwardFrame, Payload: json.RawMessage(`{}`)}
+		node.sign(e, ProtocolVersion)
+		if edit != nil {
+			edit(e)
+		}
+		return e
+	}
+
+	tests := []struct {
+		name     string
+		envelope *envelope
+		wantErr  error
+	}{
+		{name: "Signed request", envelope: signed(nil), wantErr: nil},
+		{name: "Signed reply", envelope: signed(func(e *envelope) { e.ID, e.Kind, e.ReplyTo = 0, "", 1; node.sign(e, ProtocolVersion) }), wantErr: nil},
+		{name: "Unsigned message", envelope: signed(func(e *envelope) { e.Signature = ss.SingleSignatureBytes{} }), wantErr: ErrEnvelope},
+		{name: "Altered payload", envelope: signed(func(e *envelope) { e.Payload = json.RawMessage(`[]`) }), wantErr: ErrEnvelope},
+		{name: "Other version", envelope: signed(func(e *envelope) { node.sign(e, ProtocolVersion+1) }), wantErr: ErrEnvelope},
+		{name: "Unknown kind", envelope: signed(func(e *envelope) { e.Kind = "unknown"; node.sign(e, ProtocolVersion) }), wantErr: ErrEnvelope},
+		{name: "Sender is not the peer", envelope: signed(func(e *envelope) { e.Sender = newKeyValidator().PublicKey() }), wantErr: ErrEnvelope},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := checkEnvelope(tt.envelope, conn, node.MyPublicKey); err != tt.wantErr {
+				t.Errorf("checkEnvelope() error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestHandshake_ProtocolVersion(t *testing.T) {
+	listener, _ := startTestNode(t, newKeyValidator())
+	u := url.URL{Scheme: "ws", Host: listener.hostname, Path: "peer"}
+	conn, _, err := websocket.DefaultDialer.Dial(u.String(), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+
+	err = conn.WriteJSON(handshakeMessage{Hostname: "127.0.0.1:1", PublicKey: newKeyValidator().PublicKey(), Versions: []uint16{ProtocolVersion + 1}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	_ = conn.SetReadDeadline(time.Now().Add(PeerTimeout))
+	_, _, err = conn.ReadMessage()
+	if !websocket.IsCloseError(err, websocket.ClosePolicyViolation) {
+		t.Errorf("ReadMessage() error = %v, want close for policy violation", err)
+	}
+}
+
+func TestPeerConnection_Capabilities(t *testing.T) {
+	capabilities := Capabilities
+	Capabilities = []string{forwardFrame}
+	t.Cleanup(func() { Capabilities = capabilities })
+
+	dialer, _ := startTestNode(t, newKeyValidator())
+	peer, _ := startTestNode(t, newKeyValidator())
+	trust(t, peer, dialer)
+	t.Cleanup(dialer.closePeerConnections)
+	pc, _ := dialer.peerConnection(peer.hostname)
+
+	tests := []struct {
+		name    string
+		kind    string
+		payload json.RawMessage
+		wantErr error
+	}{
+		{name: "Kind the peer serves", kind: forwardFrame, payload: forwardRequest(t), wantErr: nil},
+		{name: "Kind the peer does not serve", kind: consensusFrame, payload: json.RawMessage(`{}`), wantErr: ErrUnsupportedKind},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx, cancel := context.WithTimeout(context.Background(), PeerTimeout)
+			defer cancel()
+			if _, err := pc.request(ctx, tt.kind, tt.payload); err != tt.wantErr {
+				t.Errorf("request() error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,117 @@
+package network_node
+
+import (
+	tx "github.com/Digital-Voting-Team/Digital-Voting/pkg/blockchain/transaction"
+	"github.com/Digital-Voting-Team/Digital-Voting/pkg/blockchain/transaction/transaction_json"
+	"github.com/Digital-Voting-Team/Digital-Voting/pkg/logging"
+	"github.com/Digital-Voting-Team/Digital-Voting/pkg/rejection"
+	"log"
+	"sync"
+)
+
+// SeenCacheSize is the number of recently seen transaction hashes kept to stop gossip from looping
+const SeenCacheSize = 4096
+
+// GossipRate is the number of gossiped transactions per second accepted from one peer,
+// GossipBurst is the number accepted at once after the peer was idle
+const (
+	GossipRate  = 50
+	GossipBurst = 100
+)
+
+// seenCache remembers hashes of the last transactions in arrival order
+type seenCache struct {
+	mutex  sync.Mutex
+	hashes map[[32]byte]struct{}
+	order  [][32]byte
+	size   int
+}
+
+func newSeenCache(size int) *seenCache {
+	return &seenCache{
+		hashes: map[[32]byte]struct{}{},
+		size:   size,
+	}
+}
+
+// add returns false if the hash was already seen, the oldest hash is forgotten once the cache is full
+func (sc *seenCache) add(hash [32]byte) bool {
+	sc.mutex.Lock()
+	defer sc.mutex.Unlock()
+	if _, exists := sc.hashes[hash]; exists {
+		return false
+	}
+
+	if len(sc.order) >= sc.size {
+		delete(sc.hashes, sc.order[0])
+		sc.order = sc.order[1:]
+	}
+	sc.hashes[hash] = struct{}{}
+	sc.order = append(sc.order, hash)
+	return true
+}
+
+// remove forgets a rejected transaction so it can be submitted again once it becomes valid
+func (sc *seenCache) remove(hash [32]byte) {
+	sc.mutex.Lock()
+	defer sc.mutex.Unlock()
+	if _, exists := sc.hashes[hash]; !exists {
+		return
+	}
+
+	delete(sc.hashes, hash)
+	for i, current := range sc.order {
+		if current == hash {
+			sc.order = append(sc.order[:i], sc.order[i+1:]...)
+			break
+		}
+	}
+}
+
+// ErrAlreadySeen rejects a transaction that arrived before, e.g. through gossip of another peer
+var ErrAlreadySeen = rejection.New(rejection.MemPoolRejected, "transaction was already seen")
+
+// SubmitTransaction passes a transaction not seen before to the validator and gossips it once accepted
+func (n *NetworkNode) SubmitTransaction(transaction tx.ITransaction) error {
+	hash := transaction.GetHash()
+	if !n.seen.add(hash) {
+		log.Printf("Transaction with hash %s was already seen", transaction.GetHashString())
+		return ErrAlreadySeen
+	}
+
+	err := n.Validator.AddToMemPool(transaction)
+	if err != nil {
+		n.seen.remove(hash)
+		return err
+	}
+
+	n.GossipTransaction(transaction)
+	return nil
+}
+
+// GossipTransaction sends transaction to all nodes in network
+func (n *NetworkNode) GossipTransaction(transaction tx.ITransaction) {
+	n.Mutex.Lock()
+	nodeList := append([]string{}, n.NodeList...)
+	n.Mutex.Unlock()
+
+	n.notifyAll(nodeList, gossipFrame, transaction)
+}
+
+// handleGossip submits a transaction gossiped by peer unless the peer exceeds its rate
+func (n *NetworkNode) handleGossip(peer string, message []byte) {
+	if !n.gossipLimiter.Allow(peer) {
+		log.Printf("Gossip from %s exceeds rate limit, transaction dropped", peer)
+		return
+	}
+
+	transaction, err := (&transaction_json.JSONTransaction{}).UnmarshallJSON(message)
+	if err != nil {
+		logging.Errorln("Error reading gossiped transaction:", err)
+		return
+	}
+
+	if n.SubmitTransaction(transaction) == nil {
+		log.Printf("Gossiped transaction with hash %s accepted", transaction.GetHashString())
+	}
+}
@@ -0,0 +1,116 @@
+package network_node
+
+import (
+	"errors"
+	tx "github.com/Digital-Voting-Team/Digital-Voting/pkg/blockchain/transaction"
+	ts "github.com/Digital-Voting-Team/Digital-Voting/pkg/blockchain/transaction/transaction_specific"
+	"github.com/Digital-Voting-Team/Digital-Voting/pkg/models/account"
+	"github.com/Digital-Voting-Team/Digital-Voting/pkg/signature/keys"
+	ss "github.com/Digital-Voting-Team/Digital-Voting/pkg/signature/signatures/single_signature"
+	"github.com/Digital-Voting-Team/Digital-Voting/pkg/validator/repository/indexed_votings"
+	"sync"
+	"testing"
+)
+
+func TestSeenCache(t *testing.T) {
+	cache := newSeenCache(2)
+
+	tests := []struct {
+		name   string
+		hash   [32]byte
+		remove bool
+		want   bool
+	}{
+		{name: "New hash", hash: [32]byte{1}, want: true},
+		{name: "Seen hash", hash: [32]byte{1}, want: false},
+		{name: "Second hash", hash: [32]byte{2}, want: true},
+		{name: "Oldest hash is evicted", hash: [32]byte{3}, want: true},
+		{name: "Evicted hash is new again", hash: [32]byte{1}, want: true},
+		{name: "Removed hash is new again", hash: [32]byte{3}, remove: true, want: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.remove {
+				cache.remove(tt.hash)
+			}
+			if got := cache.add(tt.hash); got != tt.want {
+				t.Errorf("add() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+var errRejected = errors.New("rejected")
+
+// testValidator accepts transactions of account creation for key 1 only,
+// its validator set holds the keys it was told to trust
+type testValidator struct {
+	mutex      sync.Mutex
+	accepted   []tx.ITransaction
+	validators map[keys.PublicKeyBytes]struct{}
+	results    []uint64
+}
+
+func (tv *testValidator) PublicKey() keys.PublicKeyBytes { return keys.PublicKeyBytes{1} }
+func (tv *testValidator) IsValidator(publicKey keys.PublicKeyBytes) bool {
+	tv.mutex.Lock()
+	defer tv.mutex.Unlock()
+	_, exists := tv.validators[publicKey]
+	return exists
+}
+func (tv *testValidator) trust(publicKeys ...keys.PublicKeyBytes) {
+	tv.mutex.Lock()
+	defer tv.mutex.Unlock()
+	if tv.validators == nil {
+		tv.validators = map[keys.PublicKeyBytes]struct{}{}
+	}
+	for _, publicKey := range publicKeys {
+		tv.validators[publicKey] = struct{}{}
+	}
+}
+func (tv *testValidator) GetVotingsForPubKey(keys.PublicKeyBytes) []indexed_votings.VotingDTO {
+	return nil
+}
+func (tv *testValidator) GetResults([32]byte) []uint64 {
+	tv.mutex.Lock()
+	defer tv.mutex.Unlock()
+	return tv.results
+}
+func (tv *testValidator) Sign(string) ss.SingleSignatureBytes {
+	return ss.SingleSignatureBytes{}
+}
+
+func (tv *testValidator) AddToMemPool(transaction tx.ITransaction) error {
+	body := transaction.GetTxBody().(*ts.TxAccountCreation)
+	if body.NewPublicKey != (keys.PublicKeyBytes{1}) {
+		return errRejected
+	}
+	tv.mutex.Lock()
+	defer tv.mutex.Unlock()
+	tv.accepted = append(tv.accepted, transaction)
+	return nil
+}
+
+func TestSubmitTransaction(t *testing.T) {
+	node := NewNetworkNode("localhost:0", &testValidator{})
+	accepted := tx.NewTransaction(tx.AccountCreation, ts.NewTxAccCreation(account.User, keys.PublicKeyBytes{1}))
+	rejected := tx.NewTransaction(tx.AccountCreation, ts.NewTxAccCreation(account.User, keys.PublicKeyBytes{2}))
+
+	tests := []struct {
+		name        string
+		transaction tx.ITransaction
+		want        error
+	}{
+		{name: "Accepted transaction", transaction: accepted, want: nil},
+		{name: "Same transaction again", transaction: accepted, want: ErrAlreadySeen},
+		{name: "Rejected transaction", transaction: rejected, want: errRejected},
+		{name: "Rejected transaction is not remembered", transaction: rejected, want: errRejected},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := node.SubmitTransaction(tt.transaction); err != tt.want {
+				t.Errorf("SubmitTransaction() error = %v, want %v", err, tt.want)
+			}
+		})
+	}
+}
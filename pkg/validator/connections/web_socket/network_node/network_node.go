@@ -2,361 +2,365 @@ package network_node
 
 import (
 	"bytes"
+	"context"
+	"encoding/hex"
 	"encoding/json"
+	"errors"
 	"fmt"
 	blk "github.com/Digital-Voting-Team/Digital-Voting/pkg/blockchain/block"
+	tx "github.com/Digital-Voting-Team/Digital-Voting/pkg/blockchain/transaction"
 	"github.com/Digital-Voting-Team/Digital-Voting/pkg/blockchain/transaction/transaction_json"
+	"github.com/Digital-Voting-Team/Digital-Voting/pkg/consensus"
+	"github.com/Digital-Voting-Team/Digital-Voting/pkg/logging"
+	"github.com/Digital-Voting-Team/Digital-Voting/pkg/rejection"
 	"github.com/Digital-Voting-Team/Digital-Voting/pkg/signature/keys"
 	ss "github.com/Digital-Voting-Team/Digital-Voting/pkg/signature/signatures/single_signature"
 	"github.com/Digital-Voting-Team/Digital-Voting/pkg/validator"
+	"github.com/Digital-Voting-Team/Digital-Voting/pkg/validator/admission"
 	"github.com/Digital-Voting-Team/Digital-Voting/pkg/validator/repository/indexed_votings"
 	"github.com/gorilla/websocket"
 	"log"
-	"math"
+	"net"
 	"net/http"
-	"net/url"
-	"strings"
 	"sync"
 	"time"
 )
 
-const Threshold = 0.75
-
-type MsgType uint8
-
+// ResponseTime is the number of seconds peers have to answer a request
 const ResponseTime = 5
 
-const (
-	BlockValidation MsgType = iota
-	BlockApproval
-	BlockDenial
-)
-
-func (mt MsgType) String() string {
-	switch mt {
-	case BlockValidation:
-		return "BlockValidation"
-	case BlockApproval:
-		return "BlockApproval"
-	case BlockDenial:
-		return "BlockDenial"
-	default:
-		return fmt.Sprintf("%d", int(mt))
-	}
-}
-
-type Message struct {
-	MessageType MsgType   `json:"message_type"`
-	Block       blk.Block `json:"block"`
+var ErrNoConsensus = errors.New("consensus engine is not set")
+
+// Validator is the part of the validator network node serves requests with, it is safe for concurrent use
+type Validator interface {
+	PublicKey() keys.PublicKeyBytes
+	// IsValidator reports whether publicKey is a validator recorded on chain
+	IsValidator(publicKey keys.PublicKeyBytes) bool
+	AddToMemPool(transaction tx.ITransaction) error
+	GetVotingsForPubKey(publicKey keys.PublicKeyBytes) []indexed_votings.VotingDTO
+	// GetResults counts votes for every answer of a voting in blocks committed so far
+	GetResults(voting [32]byte) []uint64
+	// Sign signs with the validator key, peers check it in handshakes
+	Sign(message string) ss.SingleSignatureBytes
 }
 
 type NetworkNode struct {
 	upgrader websocket.Upgrader
 
-	Channels validator.Communication
+	// Validator serves requests of other validators and clients
+	Validator Validator
+
+	// Consensus decides blocks and is the only way they are committed, it has to be set before Start
+	Consensus consensus.Engine
 
 	NodeList    []string
+	NodeKeys    map[keys.PublicKeyBytes]string
 	MyPublicKey keys.PublicKeyBytes
 	Mutex       sync.Mutex
 
+	// Chain and BlockFeed serve subscriptions of clients, /subscribe is refused without them
+	Chain     Chain
+	BlockFeed *validator.BlockFeed
+
+	// subscribers counts subscriptions served by remote host, subscriberCount all of them
+	subscribers      map[string]int
+	subscriberCount  int
+	subscribersMutex sync.Mutex
+
+	// Admission is shared with the validator so rejections are counted in one place
+	Admission *admission.Controller
+
+	seen          *seenCache
+	gossipLimiter *admission.RateLimiter
+
 	hostname string
+	server   *http.Server
+	// nodeConnector is the only host allowed to update the node list, it is empty with discovery
+	nodeConnector string
+
+	// SeedPeers are dialed on Start when no node connector is used, PeersFile keeps peers found since
+	SeedPeers     []string
+	PeersFile     string
+	peers         *peerTable
+	stopDiscovery context.CancelFunc
+
+	// connections are websocket connections accepted by the node, they are closed on Stop
+	connections      map[*websocket.Conn]struct{}
+	connectionsMutex sync.Mutex
+
+	// outbound are long-lived connections to peers by hostname, it is nil once the node is stopped
+	outbound      map[string]*peerConnection
+	outboundMutex sync.Mutex
 }
 
-func NewNetworkNode(
-	hostname string,
-	validatorPublicKey keys.PublicKeyBytes,
-	channels validator.Communication,
-) *NetworkNode {
+func NewNetworkNode(hostname string, v Validator) *NetworkNode {
 	nn := &NetworkNode{
-		Channels: channels,
+		Validator: v,
 
-		MyPublicKey: validatorPublicKey,
+		MyPublicKey: v.PublicKey(),
+		NodeKeys:    map[keys.PublicKeyBytes]string{},
 		upgrader:    websocket.Upgrader{},
 
-		hostname: hostname,
+		Admission:     admission.NewController(admission.DefaultConfig()),
+		seen:          newSeenCache(SeenCacheSize),
+		gossipLimiter: admission.NewRateLimiter(GossipRate, GossipBurst),
+
+		hostname:      hostname,
+		peers:         newPeerTable(),
+		stopDiscovery: func() {},
+		connections:   map[*websocket.Conn]struct{}{},
+		subscribers:   map[string]int{},
+		outbound:      map[string]*peerConnection{},
 	}
 
 	// TODO : consider better naming
-	http.HandleFunc("/block", nn.HandleWebSocketNewBlock)
-	http.HandleFunc("/update", nn.HandleWebSocketUpdateNodeList)
-	http.HandleFunc("/ping", nn.HandleWebSocketPing)
-	http.HandleFunc("/transaction", nn.HandleWebSocketNewTransaction)
-	http.HandleFunc("/get_votings", nn.HandleWebSocketGetVotings)
-
-	go func() {
-		for {
-			nn.SendBlockValidation()
-		}
-	}()
+	mux := http.NewServeMux()
+	mux.HandleFunc("/update", nn.HandleWebSocketUpdateNodeList)
+	mux.HandleFunc("/ping", nn.HandleWebSocketPing)
+	mux.HandleFunc("/transaction", nn.HandleWebSocketNewTransaction)
+	mux.HandleFunc("/get_votings", nn.HandleWebSocketGetVotings)
+	mux.HandleFunc("/admission", nn.HandleAdmissionMetrics)
+	mux.HandleFunc("/handshake", nn.HandleWebSocketHandshake)
+	mux.HandleFunc("/peer", nn.HandleWebSocketPeer)
+	mux.HandleFunc("/subscribe", nn.HandleWebSocketSubscribe)
+	nn.server = &http.Server{Addr: hostname, Handler: mux}
 
 	return nn
 }
 
-// Start start network repository
-func (n *NetworkNode) Start(nodeConnectorHostname string) error {
-	err := n.registerInNodeConnector(nodeConnectorHostname)
+// Start registers the node in the node connector and serves requests until Stop is called, ctx bounds the registration.
+// Without a node connector peers are discovered from SeedPeers until Stop
+func (n *NetworkNode) Start(ctx context.Context, nodeConnectorHostname string) error {
+	if n.Consensus == nil {
+		return ErrNoConsensus
+	}
+	if nodeConnectorHostname != "" {
+		n.nodeConnector = nodeConnectorHostname
+		err := n.registerInNodeConnector(ctx, nodeConnectorHostname)
+		if err != nil {
+			return err
+		}
+	} else {
+		discoveryCtx, cancel := context.WithCancel(context.Background())
+		n.Mutex.Lock()
+		n.stopDiscovery = cancel
+		n.Mutex.Unlock()
+		go n.discover(discoveryCtx)
+	}
+
+	n.Consensus.Start()
+	err := n.server.ListenAndServe()
+	if err == http.ErrServerClosed {
+		return nil
+	}
+	return err
+}
+
+// Stop stops consensus, the server and open websocket connections,
+// ctx bounds the time requests in progress have to finish
+func (n *NetworkNode) Stop(ctx context.Context) error {
+	n.Mutex.Lock()
+	n.stopDiscovery()
+	n.Mutex.Unlock()
+	if n.Consensus != nil {
+		n.Consensus.Stop()
+	}
+	n.closePeerConnections()
+
+	err := n.server.Shutdown(ctx)
+
+	n.connectionsMutex.Lock()
+	for conn := range n.connections {
+		_ = conn.Close()
+	}
+	n.connections = map[*websocket.Conn]struct{}{}
+	n.connectionsMutex.Unlock()
+
+	return err
+}
+
+// upgrade accepts a websocket connection and tracks it until release
+func (n *NetworkNode) upgrade(w http.ResponseWriter, r *http.Request) (*websocket.Conn, error) {
+	conn, err := n.upgrader.Upgrade(w, r, nil)
 	if err != nil {
-		return err
+		return nil, err
+	}
+
+	n.connectionsMutex.Lock()
+	n.connections[conn] = struct{}{}
+	n.connectionsMutex.Unlock()
+	return conn, nil
+}
+
+// release closes the connection unless Stop already did
+func (n *NetworkNode) release(conn *websocket.Conn) {
+	n.connectionsMutex.Lock()
+	_, tracked := n.connections[conn]
+	delete(n.connections, conn)
+	n.connectionsMutex.Unlock()
+	if !tracked {
+		return
 	}
 
-	return http.ListenAndServe(n.hostname, nil)
+	err := conn.Close()
+	if err != nil {
+		logging.Errorln("Error closing connection:", err)
+	}
 }
 
-func (n *NetworkNode) registerInNodeConnector(nodeConnectorHostname string) error {
-	s := struct {
-		Hostname     string              `json:"hostname"`
-		ValidatorKey keys.PublicKeyBytes `json:"validator_key"`
-	}{
+func (n *NetworkNode) registerInNodeConnector(ctx context.Context, nodeConnectorHostname string) error {
+	s := nodeEntry{
 		Hostname:     n.hostname,
 		ValidatorKey: n.MyPublicKey,
 	}
+	s.Signature = n.Validator.Sign(nodeEntrySignatureMessage(s.Hostname, s.ValidatorKey))
 
 	marshalled, err := json.Marshal(s)
 	if err != nil {
 		return err
 	}
 	log.Println("Sending request to node connector")
-	_, err = http.Post("http://"+nodeConnectorHostname+"/nodes", "application/json", bytes.NewBuffer(marshalled))
+	request, err := http.NewRequestWithContext(ctx, http.MethodPost, "http://"+nodeConnectorHostname+"/nodes", bytes.NewBuffer(marshalled))
 	if err != nil {
 		return err
 	}
+	request.Header.Set("Content-Type", "application/json")
+	response, err := http.DefaultClient.Do(request)
+	if err != nil {
+		return err
+	}
+	_ = response.Body.Close()
 	log.Println("Successfully registered in node connector")
 	return nil
 }
 
-func (n *NetworkNode) ReadMessages(conn *websocket.Conn) {
-	_, message, err := conn.ReadMessage()
-	if err != nil {
-		log.Println("read in ReadMessages:", err)
-		return
-	}
+// SubmitBlock hands a block created by the validator to consensus
+func (n *NetworkNode) SubmitBlock(block *blk.Block) {
+	n.Consensus.Submit(block)
+}
 
-	messageMap := map[string]interface{}{}
-	err = json.Unmarshal(message, &messageMap)
-	if err != nil {
-		log.Println("json unmarshal here:", err)
+func (n *NetworkNode) HandleWebSocketUpdateNodeList(w http.ResponseWriter, r *http.Request) {
+	if !n.fromNodeConnector(r.RemoteAddr) {
+		logging.Errorf("Node list update from %s rejected, it is not the node connector", r.RemoteAddr)
+		http.Error(w, "node list is updated by the node connector only", http.StatusForbidden)
 		return
 	}
 
-	receivedMessage := &Message{}
-	_ = json.Unmarshal(message, receivedMessage)
-
-	marshalledBlock, _ := json.Marshal(messageMap["block"])
-	receivedBlock, err := blk.UnmarshallBlock(marshalledBlock)
+	conn, err := n.upgrade(w, r)
 	if err != nil {
-		log.Println("block unmarshal:", err)
+		logging.Errorln("WebSocket upgrade failed:", err)
 		return
 	}
+	defer n.release(conn)
 
-	log.Printf("Received block with hash %s; MessageType: %s", receivedBlock.GetHashString(), receivedMessage.MessageType)
-
-	switch receivedMessage.MessageType {
-	case BlockValidation:
-		n.Channels.NetworkToValidator <- receivedBlock
-		responseMessage := <-n.Channels.BlockResponse
-
-		err = conn.WriteJSON(responseMessage)
-		if err != nil {
-			fmt.Println(err)
-		}
-	case BlockApproval:
-		// TODO: consider denial and actions to restore correct state
-		n.Channels.BlockApproval <- receivedBlock
-		result := <-n.Channels.ApprovalResponse
-
-		err = conn.WriteJSON(struct {
-			Approved bool `json:"approved"`
-		}{result})
-		if err != nil {
-			fmt.Println(err)
-		}
-	default:
-		log.Printf("unknown message type %d", receivedMessage.MessageType)
-		return
-	}
+	n.UpdateNodeList(conn)
 }
 
-func (n *NetworkNode) SendBlock(conn *websocket.Conn, message Message) {
-	// Send the JSON message
-	err := conn.WriteJSON(message)
+// fromNodeConnector reports whether remoteAddress is an address of the node connector
+func (n *NetworkNode) fromNodeConnector(remoteAddress string) bool {
+	if n.nodeConnector == "" {
+		return false
+	}
+	remoteHost, _, err := net.SplitHostPort(remoteAddress)
 	if err != nil {
-		log.Println("write:", err)
-		return
+		return false
 	}
-}
-
-// SendBlockValidation send blk to all nodes in network
-func (n *NetworkNode) SendBlockValidation() {
-	message := Message{
-		MessageType: BlockValidation,
-		Block:       *<-n.Channels.ValidatorToNetwork,
+	remoteIP := net.ParseIP(remoteHost)
+	connectorHost, _, err := net.SplitHostPort(n.nodeConnector)
+	if err != nil {
+		connectorHost = n.nodeConnector
 	}
 
-	log.Printf("Sending block with hash: %s", message.Block.GetHashString())
-
-	// TODO: update node list
-	n.Mutex.Lock()
-	for _, indexedData := range n.NodeList {
-		address := strings.Split(indexedData, ":")
-		conn, err := n.Connect(address[0], address[1], "block")
-		if err != nil {
-			log.Println("connect:", err)
-			continue
-		}
-
-		n.SendBlock(conn, message)
-		responseMessage := n.WaitForResponse(conn)
-
-		// If verification is true, publickey exists and ss is correct
-		if responseMessage.VerificationSuccess &&
-			!ss.NewECDSA().VerifyEdDSABytes(message.Block.GetHashString(), responseMessage.PublicKey, responseMessage.Signature) {
-			message.Block.Sign(responseMessage.PublicKey, responseMessage.Signature)
-		}
-
-		err = conn.Close()
-		if err != nil {
-			log.Println("Error closing connection:", err)
-		}
+	addresses, err := net.LookupIP(connectorHost)
+	if err != nil {
+		logging.Errorln("Error resolving node connector:", err)
+		return false
 	}
-
-	// TODO: Consider the case when we didn't update NodeList cause of Mutex lock and added to blockchain
-	desiredNumber := int(math.Floor(float64(len(n.NodeList)+1) * Threshold))
-	gotNumber := len(message.Block.Witness.ValidatorsPublicKeys)
-	decision := gotNumber >= desiredNumber
-	log.Printf("Need %d approvals; Got %d approvals; Decision: %v", desiredNumber, gotNumber, decision)
-	if decision {
-		for _, indexedData := range n.NodeList {
-			address := strings.Split(indexedData, ":")
-			conn, err := n.Connect(address[0], address[1], "block")
-			if err != nil {
-				log.Println("connect:", err)
-				continue
-			}
-
-			message.MessageType = BlockApproval
-			n.SendBlock(conn, message)
-
-			_, responseMessage, err := conn.ReadMessage()
-			if err != nil {
-				log.Println("Error reading approval response:", err)
-			}
-			response := struct {
-				Approved bool `json:"approved"`
-			}{}
-			_ = json.Unmarshal(responseMessage, &response)
-			log.Printf("Block with hash %s; Approval response: %v", message.Block.GetHashString(), response)
-
-			err = conn.Close()
-			if err != nil {
-				log.Println("Error closing connection:", err)
-			}
+	for _, address := range addresses {
+		if address.Equal(remoteIP) {
+			return true
 		}
-
-		n.Channels.BlockApproval <- &message.Block
-		result := <-n.Channels.ApprovalResponse
-		log.Printf("Block with hash %s; Approve result: %v", message.Block.GetHashString(), result)
-	} else {
-		n.Channels.BlockDenial <- &message.Block
 	}
-	n.Mutex.Unlock()
+	return false
 }
 
-func (n *NetworkNode) WaitForResponse(conn *websocket.Conn) validator.ResponseMessage {
-	_ = conn.SetReadDeadline(time.Now().Add(time.Second * ResponseTime))
-	_, message, err := conn.ReadMessage()
-	responseMessage := validator.ResponseMessage{
-		VerificationSuccess: false,
-	}
-	if err == nil {
-		_ = json.Unmarshal(message, &responseMessage)
-	}
-	return responseMessage
+// nodeEntry is a node of the node list kept by the node connector. Nodes sign their own entries
+// with the validator key, so the node connector cannot tie a key to an address of its choice
+type nodeEntry struct {
+	Hostname     string                  `json:"hostname"`
+	ValidatorKey keys.PublicKeyBytes     `json:"validator_key"`
+	Signature    ss.SingleSignatureBytes `json:"signature"`
 }
 
-func (n *NetworkNode) Connect(ip string, port string, endpoint string) (*websocket.Conn, error) {
-	// IP address and port of the WebSocket server
-	//TODO check if ip not empty (consider how it will connect)
-
-	u := url.URL{Scheme: "ws", Host: ip + ":" + port, Path: endpoint}
-	log.Printf("connecting to %s", u.String())
-
-	// Establish a WebSocket connection
-	conn, _, err := websocket.DefaultDialer.Dial(u.String(), nil)
-	if err != nil {
-		return nil, err
-	}
-
-	return conn, nil
+func nodeEntrySignatureMessage(hostname string, publicKey keys.PublicKeyBytes) string {
+	return fmt.Sprint("node", hostname, hex.EncodeToString(publicKey[:]))
 }
 
-func (n *NetworkNode) HandleWebSocketNewBlock(w http.ResponseWriter, r *http.Request) {
-	conn, err := n.upgrader.Upgrade(w, r, nil)
+func (n *NetworkNode) UpdateNodeList(conn *websocket.Conn) {
+	_, message, err := conn.ReadMessage()
 	if err != nil {
-		log.Println("WebSocket upgrade failed:", err)
+		logging.Errorln("read in UpdateNodeList:", err)
 		return
 	}
-	defer func(conn *websocket.Conn) {
-		err = conn.Close()
-		if err != nil {
-			log.Println("Error closing connection:", err)
-		}
-	}(conn)
 
-	n.ReadMessages(conn)
-}
-
-func (n *NetworkNode) HandleWebSocketUpdateNodeList(w http.ResponseWriter, r *http.Request) {
-	conn, err := n.upgrader.Upgrade(w, r, nil)
+	peers, err := n.nodeListPeers(message)
 	if err != nil {
-		log.Println("WebSocket upgrade failed:", err)
+		logging.Errorln("Error reading node list:", err)
 		return
 	}
-	defer func(conn *websocket.Conn) {
-		err = conn.Close()
-		if err != nil {
-			log.Println("Error closing connection:", err)
-		}
-	}(conn)
-
-	n.UpdateNodeList(conn)
+	n.setNodeList(peers)
 }
 
-func (n *NetworkNode) UpdateNodeList(conn *websocket.Conn) {
-	_, message, err := conn.ReadMessage()
+// nodeListPeers returns peers of the node list sent by the node connector, entries not signed by their key are dropped
+func (n *NetworkNode) nodeListPeers(message []byte) ([]PeerInfo, error) {
+	dtoList := struct {
+		NodeList []nodeEntry `json:"node_list"`
+	}{}
+	err := json.Unmarshal(message, &dtoList)
 	if err != nil {
-		log.Println("read in UpdateNodeList:", err)
-		return
+		return nil, err
 	}
 
-	type DTOList struct {
-		NodeList []struct {
-			Hostname     string              `json:"hostname"`
-			ValidatorKey keys.PublicKeyBytes `json:"validator_key"`
-		} `json:"node_list"`
+	peers := []PeerInfo{}
+	ecdsa := ss.NewECDSA()
+	for _, entry := range dtoList.NodeList {
+		if entry.Hostname == n.hostname {
+			continue
+		}
+		if !ecdsa.VerifyEdDSABytes(nodeEntrySignatureMessage(entry.Hostname, entry.ValidatorKey), entry.ValidatorKey, entry.Signature) {
+			logging.Errorf("Node list entry %s dropped, it is not signed by its validator key", entry.Hostname)
+			continue
+		}
+		peers = append(peers, PeerInfo{Hostname: entry.Hostname, PublicKey: entry.ValidatorKey})
 	}
+	return peers, nil
+}
 
-	dtoList := &DTOList{}
-	_ = json.Unmarshal(message, &dtoList)
-	publicKeys := []keys.PublicKeyBytes{}
-	//log.Println("dtoList:", dtoList)
+// setNodeList makes peers the node list. It gives addresses of peers only,
+// whether a peer is a validator is decided by the validator set recorded on chain
+func (n *NetworkNode) setNodeList(peers []PeerInfo) {
 	n.Mutex.Lock()
 	n.NodeList = []string{}
-	for _, indexedData := range dtoList.NodeList {
-		publicKeys = append(publicKeys, indexedData.ValidatorKey)
-		if indexedData.Hostname == n.hostname {
-			continue
-		}
-		n.NodeList = append(n.NodeList, indexedData.Hostname)
+	n.NodeKeys = map[keys.PublicKeyBytes]string{}
+	for _, info := range peers {
+		n.NodeList = append(n.NodeList, info.Hostname)
+		n.NodeKeys[info.PublicKey] = info.Hostname
 	}
+	nodeList := append([]string{}, n.NodeList...)
 	n.Mutex.Unlock()
 
-	n.Channels.ValidatorKeys <- publicKeys
+	n.syncPeerConnections(nodeList)
+}
+
+// isValidator reports whether publicKey belongs to a validator of the set recorded on chain
+func (n *NetworkNode) isValidator(publicKey keys.PublicKeyBytes) bool {
+	return n.Validator.IsValidator(publicKey)
 }
 
 func (n *NetworkNode) HandleWebSocketPing(w http.ResponseWriter, r *http.Request) {
-	conn, err := n.upgrader.Upgrade(w, r, nil)
+	conn, err := n.upgrade(w, r)
 	if err != nil {
-		log.Println("WebSocket upgrade failed:", err)
+		logging.Errorln("WebSocket upgrade failed:", err)
 		return
 	}
 
@@ -366,19 +370,13 @@ func (n *NetworkNode) HandleWebSocketPing(w http.ResponseWriter, r *http.Request
 
 		err = conn.WriteControl(websocket.PongMessage, []byte{}, time.Now().Add(15*time.Second))
 		if err != nil {
-			log.Println("Error sending pong:", err)
+			logging.Errorln("Error sending pong:", err)
 		}
 
 		return nil
 	})
 
-	defer func(conn *websocket.Conn) {
-		err = conn.Close()
-		//println("closing connection")
-		if err != nil {
-			log.Println("Error closing connection:", err)
-		}
-	}(conn)
+	defer n.release(conn)
 
 	_, _, err = conn.ReadMessage()
 	if err != nil {
@@ -388,61 +386,66 @@ func (n *NetworkNode) HandleWebSocketPing(w http.ResponseWriter, r *http.Request
 }
 
 func (n *NetworkNode) HandleWebSocketNewTransaction(w http.ResponseWriter, r *http.Request) {
-	conn, err := n.upgrader.Upgrade(w, r, nil)
+	conn, err := n.upgrade(w, r)
 	if err != nil {
-		log.Println("WebSocket upgrade failed:", err)
+		logging.Errorln("WebSocket upgrade failed:", err)
 		return
 	}
-	defer func(conn *websocket.Conn) {
-		err = conn.Close()
-		if err != nil {
-			log.Println("Error closing connection:", err)
-		}
-	}(conn)
+	defer n.release(conn)
 
-	n.addNewTransaction(conn)
+	n.addNewTransaction(conn, r.RemoteAddr)
 }
 
-func (n *NetworkNode) addNewTransaction(conn *websocket.Conn) {
+func (n *NetworkNode) addNewTransaction(conn *websocket.Conn, remoteAddress string) {
 	_, message, err := conn.ReadMessage()
 	if err != nil {
-		log.Println("read in ReadMessages:", err)
+		logging.Errorln("read in ReadMessages:", err)
 		return
 	}
 
-	newTxJson := &transaction_json.JSONTransaction{}
-	transaction, err := newTxJson.UnmarshallJSON(message)
-	if err != nil {
-		log.Println("Error reading transaction from UserAPI")
-		return
+	if err = n.Admission.AdmitConnection(remoteAddress); err != nil {
+		logging.Errorf("Transaction from %s not admitted: %v", remoteAddress, err)
+	} else {
+		newTxJson := &transaction_json.JSONTransaction{}
+		var transaction tx.ITransaction
+		transaction, err = newTxJson.UnmarshallJSON(message)
+		if err != nil {
+			logging.Errorln("Error reading transaction from UserAPI:", err)
+		} else {
+			log.Printf("Received new transaction with hash: %s", transaction.GetHashString())
+			err = n.SubmitTransaction(transaction)
+			if err != nil {
+				logging.Errorf("Transaction with hash %s rejected: %v", transaction.GetHashString(), err)
+			}
+		}
 	}
 
-	log.Printf("Received new transaction with hash: %s", transaction.GetHashString())
-	n.Channels.Transaction <- transaction
-	success := <-n.Channels.TxResponse
-	log.Printf("Transaction with hash %s; Verification status: %v", transaction.GetHashString(), success)
-
 	err = conn.WriteJSON(struct {
-		Response bool `json:"response"`
-	}{Response: success})
+		Response bool             `json:"response"`
+		Error    *rejection.Error `json:"error,omitempty"`
+	}{Response: err == nil, Error: rejection.FromError(err)})
 	if err != nil {
-		log.Println("Error writing response")
+		logging.Errorln("Error writing response")
 		return
 	}
 }
 
+// HandleAdmissionMetrics reports how many transactions were admitted and why others were rejected
+func (n *NetworkNode) HandleAdmissionMetrics(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	err := json.NewEncoder(w).Encode(n.Admission.Metrics.Snapshot())
+	if err != nil {
+		logging.Errorln("Error writing admission metrics:", err)
+	}
+}
+
 func (n *NetworkNode) HandleWebSocketGetVotings(w http.ResponseWriter, r *http.Request) {
-	conn, err := n.upgrader.Upgrade(w, r, nil)
+	conn, err := n.upgrade(w, r)
 	if err != nil {
-		log.Println("WebSocket upgrade failed:", err)
+		logging.Errorln("WebSocket upgrade failed:", err)
 		return
 	}
-	defer func(conn *websocket.Conn) {
-		err = conn.Close()
-		if err != nil {
-			log.Println("Error closing connection:", err)
-		}
-	}(conn)
+	defer n.release(conn)
 
 	n.getVotings(conn)
 }
@@ -450,7 +453,7 @@ func (n *NetworkNode) HandleWebSocketGetVotings(w http.ResponseWriter, r *http.R
 func (n *NetworkNode) getVotings(conn *websocket.Conn) {
 	_, message, err := conn.ReadMessage()
 	if err != nil {
-		log.Println("read in getVotings:", err)
+		logging.Errorln("read in getVotings:", err)
 		return
 	}
 
@@ -459,18 +462,118 @@ func (n *NetworkNode) getVotings(conn *websocket.Conn) {
 	}{}
 	err = json.Unmarshal(message, &publicKeyStruct)
 	if err != nil {
-		log.Println("Error unmarshalling getVotingsRequest")
+		logging.Errorln("Error unmarshalling getVotingsRequest")
 		return
 	}
 
-	n.Channels.PublicKey <- publicKeyStruct.PublicKey
-	votings := <-n.Channels.Votings
+	votings := n.Validator.GetVotingsForPubKey(publicKeyStruct.PublicKey)
 
 	err = conn.WriteJSON(struct {
 		Votings []indexed_votings.VotingDTO `json:"votings"`
 	}{Votings: votings})
 	if err != nil {
-		log.Println("Error writing response")
+		logging.Errorln("Error writing response")
+		return
+	}
+}
+
+func (n *NetworkNode) handleConsensusMessage(message []byte) {
+	consensusMessage := &consensus.Message{}
+	err := json.Unmarshal(message, consensusMessage)
+	if err != nil {
+		logging.Errorln("consensus message unmarshal:", err)
 		return
 	}
+
+	if n.Consensus == nil {
+		logging.Errorln("Consensus message dropped:", ErrNoConsensus)
+		return
+	}
+	n.Consensus.HandleMessage(consensusMessage)
+}
+
+// Broadcast sends consensus message to all nodes in network
+func (n *NetworkNode) Broadcast(message *consensus.Message) {
+	n.Mutex.Lock()
+	nodeList := append([]string{}, n.NodeList...)
+	n.Mutex.Unlock()
+
+	n.notifyAll(nodeList, consensusFrame, message)
+}
+
+// Proposer returns the proposer of the height and round of consensus
+func (n *NetworkNode) Proposer() (keys.PublicKeyBytes, error) {
+	if n.Consensus == nil {
+		return keys.PublicKeyBytes{}, ErrNoConsensus
+	}
+	return n.Consensus.Proposer()
+}
+
+// ForwardTransactions sends pending transactions to the proposer of current height
+func (n *NetworkNode) ForwardTransactions(proposer keys.PublicKeyBytes, transactions []tx.ITransaction) error {
+	n.Mutex.Lock()
+	hostname, exists := n.NodeKeys[proposer]
+	n.Mutex.Unlock()
+	if !exists {
+		return fmt.Errorf("proposer is not in node list")
+	}
+
+	pc, err := n.peerConnection(hostname)
+	if err != nil {
+		return err
+	}
+	payload, err := json.Marshal(struct {
+		Transactions []tx.ITransaction `json:"transactions"`
+	}{Transactions: transactions})
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second*ResponseTime)
+	defer cancel()
+	reply, err := pc.request(ctx, forwardFrame, payload)
+	if err != nil {
+		return err
+	}
+	if reply.PublicKey != proposer {
+		return fmt.Errorf("peer at %s is not the proposer", hostname)
+	}
+	response := forwardResponse{}
+	err = json.Unmarshal(reply.Payload, &response)
+	if err != nil {
+		return err
+	}
+
+	log.Printf("Forwarded %d transactions to %s; Accepted: %d", len(transactions), hostname, response.Accepted)
+	return nil
+}
+
+// forwardResponse answers forwarded transactions with the number of accepted ones
+type forwardResponse struct {
+	Accepted int `json:"accepted"`
+}
+
+func (n *NetworkNode) handleForwardedTransactions(message []byte) interface{} {
+	forwarded := struct {
+		Transactions []json.RawMessage `json:"transactions"`
+	}{}
+	err := json.Unmarshal(message, &forwarded)
+	if err != nil {
+		logging.Errorln("Error unmarshalling forwarded transactions")
+		return nil
+	}
+
+	accepted := 0
+	for _, marshalledTransaction := range forwarded.Transactions {
+		transaction, err := (&transaction_json.JSONTransaction{}).UnmarshallJSON(marshalledTransaction)
+		if err != nil {
+			logging.Errorln("Error reading forwarded transaction:", err)
+			continue
+		}
+
+		if n.SubmitTransaction(transaction) == nil {
+			accepted++
+		}
+	}
+	return forwardResponse{Accepted: accepted}
 }
@@ -0,0 +1,60 @@
+package network_node
+
+import (
+	"encoding/json"
+	"github.com/Digital-Voting-Team/Digital-Voting/pkg/signature/keys"
+	"reflect"
+	"testing"
+)
+
+func TestNetworkNode_FromNodeConnector(t *testing.T) {
+	tests := []struct {
+		name          string
+		nodeConnector string
+		remoteAddress string
+		want          bool
+	}{
+		{name: "Node connector", nodeConnector: "127.0.0.1:8080", remoteAddress: "127.0.0.1:51234", want: true},
+		{name: "Node connector by name", nodeConnector: "localhost:8080", remoteAddress: "127.0.0.1:51234", want: true},
+		{name: "Other host", nodeConnector: "127.0.0.1:8080", remoteAddress: "10.0.0.7:51234", want: false},
+		{name: "Discovery without node connector", nodeConnector: "", remoteAddress: "127.0.0.1:51234", want: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			node := NewNetworkNode("localhost:0", &testValidator{})
+			node.nodeConnector = tt.nodeConnector
+			if got := node.fromNodeConnector(tt.remoteAddress); got != tt.want {
+				t.Errorf("fromNodeConnector() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNetworkNode_NodeListPeers(t *testing.T) {
+	node := NewNetworkNode("localhost:8081", &testValidator{})
+	signer, other := newKeyValidator(), newKeyValidator()
+	entry := func(hostname string, publicKey keys.PublicKeyBytes, by *keyValidator) nodeEntry {
+		return nodeEntry{Hostname: hostname, ValidatorKey: publicKey, Signature: by.Sign(nodeEntrySignatureMessage(hostname, publicKey))}
+	}
+	signed := entry("localhost:8082", signer.PublicKey(), signer)
+
+	tests := []struct {
+		name    string
+		entries []nodeEntry
+		want    []PeerInfo
+	}{
+		{name: "Entry signed by its key", entries: []nodeEntry{signed}, want: []PeerInfo{{Hostname: "localhost:8082", PublicKey: signer.PublicKey()}}},
+		{name: "Entry of this node", entries: []nodeEntry{entry("localhost:8081", signer.PublicKey(), signer)}, want: []PeerInfo{}},
+		{name: "Entry signed by other key", entries: []nodeEntry{entry("localhost:8083", signer.PublicKey(), other)}, want: []PeerInfo{}},
+		{name: "Entry moved to other address", entries: []nodeEntry{{Hostname: "localhost:8083", ValidatorKey: signed.ValidatorKey, Signature: signed.Signature}}, want: []PeerInfo{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			message, _ := json.Marshal(map[string]interface{}{"node_list": tt.entries})
+			got, err := node.nodeListPeers(message)
+			if err != nil || !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("nodeListPeers() = %v, %v, want %v", got, err, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,417 @@
+package network_node
+
+import (
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"github.com/Digital-Voting-Team/Digital-Voting/pkg/logging"
+	"github.com/Digital-Voting-Team/Digital-Voting/pkg/signature/keys"
+	"log"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// PeerQueueSize bounds envelopes waiting to be written to one peer and requests of one peer served at once,
+// an envelope for a peer whose queue is full is dropped so a slow peer cannot hold back the others
+const PeerQueueSize = 64
+
+// A broken peer connection is dialed again after PeerRetryMin, the delay doubles up to PeerRetryMax
+const (
+	PeerRetryMin = 500 * time.Millisecond
+	PeerRetryMax = 30 * time.Second
+)
+
+// Kinds of envelopes peers exchange, a node announces the kinds it serves as capabilities
+const (
+	consensusFrame = "consensus"
+	gossipFrame    = "gossip"
+	forwardFrame   = "forward"
+)
+
+var (
+	ErrPeerQueueFull    = errors.New("send queue of peer is full")
+	ErrPeerDisconnected = errors.New("peer connection is closed")
+	ErrNodeStopped      = errors.New("network node is stopped")
+)
+
+// peerReply is a reply with the key the peer authenticated with on the connection it came over
+type peerReply struct {
+	Payload   json.RawMessage
+	PublicKey keys.PublicKeyBytes
+	err       error
+}
+
+// peerConnection is a long-lived authenticated connection to one peer, requests over it are matched
+// with replies by ID. Envelopes wait in a bounded queue written by a single goroutine
+// and the connection is dialed again with backoff once it breaks
+type peerConnection struct {
+	node     *NetworkNode
+	hostname string
+	queue    chan envelope
+
+	ctx    context.Context
+	cancel context.CancelFunc
+
+	mutex   sync.Mutex
+	nextID  uint64
+	pending map[uint64]chan peerReply
+	// err is why the peer was last lost, requests fail at once until it is reached again
+	err error
+}
+
+func newPeerConnection(node *NetworkNode, hostname string) *peerConnection {
+	ctx, cancel := context.WithCancel(context.Background())
+	pc := &peerConnection{
+		node:     node,
+		hostname: hostname,
+		queue:    make(chan envelope, PeerQueueSize),
+		ctx:      ctx,
+		cancel:   cancel,
+		pending:  map[uint64]chan peerReply{},
+	}
+	go pc.run()
+	return pc
+}
+
+func (pc *peerConnection) close() {
+	pc.cancel()
+}
+
+// request sends payload and waits for the reply until ctx is done
+func (pc *peerConnection) request(ctx context.Context, kind string, payload json.RawMessage) (peerReply, error) {
+	replies := make(chan peerReply, 1)
+	pc.mutex.Lock()
+	if pc.err != nil {
+		err := pc.err
+		pc.mutex.Unlock()
+		return peerReply{}, err
+	}
+	pc.nextID++
+	id := pc.nextID
+	pc.pending[id] = replies
+	pc.mutex.Unlock()
+
+	err := pc.enqueue(envelope{ID: id, Kind: kind, Payload: payload})
+	if err != nil {
+		pc.forget(id)
+		return peerReply{}, err
+	}
+
+	select {
+	case reply := <-replies:
+		return reply, reply.err
+	case <-ctx.Done():
+		pc.forget(id)
+		return peerReply{}, ctx.Err()
+	}
+}
+
+// notify sends payload without waiting for a reply
+func (pc *peerConnection) notify(kind string, payload json.RawMessage) error {
+	return pc.enqueue(envelope{Kind: kind, Payload: payload})
+}
+
+func (pc *peerConnection) enqueue(message envelope) error {
+	if pc.ctx.Err() != nil {
+		return ErrPeerDisconnected
+	}
+	select {
+	case pc.queue <- message:
+		return nil
+	default:
+		return ErrPeerQueueFull
+	}
+}
+
+func (pc *peerConnection) forget(id uint64) {
+	pc.mutex.Lock()
+	defer pc.mutex.Unlock()
+	delete(pc.pending, id)
+}
+
+func (pc *peerConnection) isPending(id uint64) bool {
+	pc.mutex.Lock()
+	defer pc.mutex.Unlock()
+	_, exists := pc.pending[id]
+	return exists
+}
+
+// deliver hands a reply to the request waiting for it, replies to forgotten requests are dropped
+func (pc *peerConnection) deliver(id uint64, reply peerReply) {
+	pc.mutex.Lock()
+	replies, exists := pc.pending[id]
+	delete(pc.pending, id)
+	pc.mutex.Unlock()
+	if exists {
+		replies <- reply
+	}
+}
+
+// fail ends requests in progress and queued envelopes with err and keeps err until the peer is reached again
+func (pc *peerConnection) fail(err error) {
+	pc.mutex.Lock()
+	pending := pc.pending
+	pc.pending = map[uint64]chan peerReply{}
+	pc.err = err
+	pc.mutex.Unlock()
+
+	for _, replies := range pending {
+		replies <- peerReply{err: err}
+	}
+	for {
+		select {
+		case <-pc.queue:
+		default:
+			return
+		}
+	}
+}
+
+// run keeps the connection open until close
+func (pc *peerConnection) run() {
+	retry := PeerRetryMin
+	for {
+		conn, publicKey, err := pc.node.dialAuthenticated(pc.ctx, pc.hostname, "peer")
+		if err == nil {
+			retry = PeerRetryMin
+			pc.mutex.Lock()
+			pc.err = nil
+			pc.mutex.Unlock()
+			err = pc.serve(conn, publicKey)
+		}
+		if pc.ctx.Err() != nil {
+			pc.fail(ErrPeerDisconnected)
+			return
+		}
+		logging.Errorf("Connection to peer %s lost: %v", pc.hostname, err)
+		pc.fail(err)
+
+		select {
+		case <-pc.ctx.Done():
+			return
+		case <-time.After(retry):
+		}
+		retry *= 2
+		if retry > PeerRetryMax {
+			retry = PeerRetryMax
+		}
+	}
+}
+
+// serve signs queued envelopes, writes them to conn and delivers replies read from it until either side fails
+func (pc *peerConnection) serve(conn *secureConn, publicKey keys.PublicKeyBytes) error {
+	defer conn.Close()
+	readErr := make(chan error, 1)
+	go func() {
+		for {
+			message := envelope{}
+			err := conn.ReadJSON(&message)
+			if err != nil {
+				readErr <- err
+				return
+			}
+			err = checkEnvelope(&message, conn, publicKey)
+			if err != nil {
+				logging.Errorf("Reply of peer %s dropped: %v", pc.hostname, err)
+				continue
+			}
+			if message.ReplyTo != 0 {
+				pc.deliver(message.ReplyTo, peerReply{Payload: message.Payload, PublicKey: publicKey})
+			}
+		}
+	}()
+
+	for {
+		select {
+		case <-pc.ctx.Done():
+			return ErrPeerDisconnected
+		case err := <-readErr:
+			return err
+		case message := <-pc.queue:
+			// requests that timed out while waiting in the queue are not sent
+			if message.ID != 0 && !pc.isPending(message.ID) {
+				continue
+			}
+			if !supports(conn.capabilities, message.Kind) {
+				log.Printf("Message of kind %s to peer %s dropped: %v", message.Kind, pc.hostname, ErrUnsupportedKind)
+				pc.deliver(message.ID, peerReply{err: ErrUnsupportedKind})
+				continue
+			}
+			pc.node.sign(&message, conn.version)
+			_ = conn.SetWriteDeadline(time.Now().Add(PeerTimeout))
+			err := conn.WriteJSON(message)
+			if err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// HandleWebSocketPeer authenticates a dialing peer and serves its envelopes over a secure channel until it is closed,
+// envelopes that are not signed by the peer or are of unknown version or kind are dropped
+func (n *NetworkNode) HandleWebSocketPeer(w http.ResponseWriter, r *http.Request) {
+	conn, err := n.upgrade(w, r)
+	if err != nil {
+		logging.Errorln("WebSocket upgrade failed:", err)
+		return
+	}
+	defer n.release(conn)
+
+	secure, publicKey, err := n.acceptHandshake(conn)
+	if err != nil {
+		logging.Errorf("Handshake from %s failed: %v", r.RemoteAddr, err)
+		return
+	}
+
+	replies := make(chan envelope, PeerQueueSize)
+	done := make(chan struct{})
+	defer close(done)
+	go func() {
+		for {
+			select {
+			case <-done:
+				return
+			case reply := <-replies:
+				_ = secure.SetWriteDeadline(time.Now().Add(PeerTimeout))
+				err := secure.WriteJSON(reply)
+				if err != nil {
+					logging.Errorf("Error writing to peer %s: %v", r.RemoteAddr, err)
+					n.release(conn)
+					return
+				}
+			}
+		}
+	}()
+
+	handlers := make(chan struct{}, PeerQueueSize)
+	for {
+		message := envelope{}
+		err = secure.ReadJSON(&message)
+		if err != nil {
+			if err == ErrSecureChannel {
+				logging.Errorf("Connection to peer %s closed: %v", r.RemoteAddr, err)
+			}
+			return
+		}
+		err = checkEnvelope(&message, secure, publicKey)
+		if err != nil {
+			logging.Errorf("Message of kind %q from peer %s dropped: %v", message.Kind, r.RemoteAddr, err)
+			continue
+		}
+		if message.ReplyTo != 0 {
+			continue
+		}
+
+		handlers <- struct{}{}
+		go func(message envelope) {
+			defer func() { <-handlers }()
+			response := n.handleEnvelope(publicKey, message)
+			if message.ID == 0 {
+				return
+			}
+			payload, err := json.Marshal(response)
+			if err != nil {
+				logging.Errorln("Error marshalling reply:", err)
+				return
+			}
+			reply := envelope{ReplyTo: message.ID, Payload: payload}
+			n.sign(&reply, secure.version)
+			select {
+			case replies <- reply:
+			default:
+				log.Printf("Reply to peer %s dropped, send queue is full", r.RemoteAddr)
+			}
+		}(message)
+	}
+}
+
+// handleEnvelope serves a message of the peer with publicKey and returns the reply to it.
+// Consensus messages are taken only from validators of the current set
+func (n *NetworkNode) handleEnvelope(publicKey keys.PublicKeyBytes, message envelope) interface{} {
+	// gossip of authenticated peers is limited by validator key rather than address
+	peer := hex.EncodeToString(publicKey[:])
+	if message.Kind == consensusFrame && !n.isValidator(publicKey) {
+		logging.Errorf("Message of kind %s from %s dropped, peer is not a validator", message.Kind, peer)
+		return nil
+	}
+
+	switch message.Kind {
+	case consensusFrame:
+		n.handleConsensusMessage(message.Payload)
+	case gossipFrame:
+		n.handleGossip(peer, message.Payload)
+	case forwardFrame:
+		return n.handleForwardedTransactions(message.Payload)
+	default:
+		log.Printf("Unknown message kind %q from peer %s", message.Kind, peer)
+	}
+	return nil
+}
+
+// peerConnection returns the connection to hostname, it is opened on first use
+func (n *NetworkNode) peerConnection(hostname string) (*peerConnection, error) {
+	n.outboundMutex.Lock()
+	defer n.outboundMutex.Unlock()
+	if n.outbound == nil {
+		return nil, ErrNodeStopped
+	}
+	pc, exists := n.outbound[hostname]
+	if !exists {
+		pc = newPeerConnection(n, hostname)
+		n.outbound[hostname] = pc
+	}
+	return pc, nil
+}
+
+// syncPeerConnections opens connections to hostnames and closes connections to other peers
+func (n *NetworkNode) syncPeerConnections(hostnames []string) {
+	n.outboundMutex.Lock()
+	defer n.outboundMutex.Unlock()
+	if n.outbound == nil {
+		return
+	}
+
+	wanted := map[string]struct{}{}
+	for _, hostname := range hostnames {
+		wanted[hostname] = struct{}{}
+		if _, exists := n.outbound[hostname]; !exists {
+			n.outbound[hostname] = newPeerConnection(n, hostname)
+		}
+	}
+	for hostname, pc := range n.outbound {
+		if _, exists := wanted[hostname]; !exists {
+			pc.close()
+			delete(n.outbound, hostname)
+		}
+	}
+}
+
+func (n *NetworkNode) closePeerConnections() {
+	n.outboundMutex.Lock()
+	defer n.outboundMutex.Unlock()
+	for _, pc := range n.outbound {
+		pc.close()
+	}
+	n.outbound = nil
+}
+
+// notifyAll queues message for all hostnames without waiting for peers
+func (n *NetworkNode) notifyAll(hostnames []string, kind string, message interface{}) {
+	payload, err := json.Marshal(message)
+	if err != nil {
+		logging.Errorln("Error marshalling message:", err)
+		return
+	}
+
+	for _, hostname := range hostnames {
+		pc, err := n.peerConnection(hostname)
+		if err == nil {
+			err = pc.notify(kind, payload)
+		}
+		if err != nil {
+			logging.Errorf("Message to %s dropped: %v", hostname, err)
+		}
+	}
+}
@@ -0,0 +1,248 @@
+package network_node
+
+import (
+	"context"
+	"encoding/json"
+	blk "github.com/Digital-Voting-Team/Digital-Voting/pkg/blockchain/block"
+	tx "github.com/Digital-Voting-Team/Digital-Voting/pkg/blockchain/transaction"
+	ts "github.com/Digital-Voting-Team/Digital-Voting/pkg/blockchain/transaction/transaction_specific"
+	"github.com/Digital-Voting-Team/Digital-Voting/pkg/consensus"
+	"github.com/Digital-Voting-Team/Digital-Voting/pkg/models/account"
+	"github.com/Digital-Voting-Team/Digital-Voting/pkg/signature/keys"
+	ss "github.com/Digital-Voting-Team/Digital-Voting/pkg/signature/signatures/single_signature"
+	"net"
+	"net/http"
+	"sync"
+	"testing"
+	"time"
+)
+
+// slowValidator accepts transactions only once released
+type slowValidator struct {
+	*keyValidator
+	release chan struct{}
+}
+
+func (sv *slowValidator) AddToMemPool(transaction tx.ITransaction) error {
+	<-sv.release
+	return sv.keyValidator.AddToMemPool(transaction)
+}
+
+// testEngine counts consensus messages it receives
+type testEngine struct {
+	mutex    sync.Mutex
+	messages int
+}
+
+func (te *testEngine) Start()            {}
+func (te *testEngine) Stop()             {}
+func (te *testEngine) Submit(*blk.Block) {}
+func (te *testEngine) Proposer() (keys.PublicKeyBytes, error) {
+	return keys.PublicKeyBytes{}, consensus.ErrNotRunning
+}
+func (te *testEngine) HandleMessage(*consensus.Message) {
+	te.mutex.Lock()
+	defer te.mutex.Unlock()
+	te.messages++
+}
+
+// trust makes peers the node list of node and validators it takes consensus messages from
+func trust(t *testing.T, node *NetworkNode, peers ...*NetworkNode) {
+	infos := []PeerInfo{}
+	for _, peer := range peers {
+		infos = append(infos, PeerInfo{Hostname: peer.hostname, PublicKey: peer.MyPublicKey})
+		node.Validator.(interface{ trust(...keys.PublicKeyBytes) }).trust(peer.MyPublicKey)
+	}
+	node.setNodeList(infos)
+	t.Cleanup(node.closePeerConnections)
+}
+
+// forwardRequest forwards a transaction testValidator accepts
+func forwardRequest(t *testing.T) json.RawMessage {
+	transaction := tx.NewTransaction(tx.AccountCreation, ts.NewTxAccCreation(account.User, keys.PublicKeyBytes{1}))
+	transaction.Sign(keys.PublicKeyBytes{1}, ss.SingleSignatureBytes{1})
+	payload, err := json.Marshal(struct {
+		Transactions []tx.ITransaction `json:"transactions"`
+	}{Transactions: []tx.ITransaction{transaction}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return payload
+}
+
+func TestPeerConnection_Request(t *testing.T) {
+	dialer, _ := startTestNode(t, newKeyValidator())
+	fastValidator := newKeyValidator()
+	fast, _ := startTestNode(t, fastValidator)
+	slowValidator := &slowValidator{keyValidator: newKeyValidator(), release: make(chan struct{})}
+	slow, _ := startTestNode(t, slowValidator)
+	trust(t, fast, dialer)
+	trust(t, slow, dialer)
+	t.Cleanup(func() {
+		close(slowValidator.release)
+		dialer.closePeerConnections()
+	})
+	payload := forwardRequest(t)
+
+	t.Run("Concurrent requests share one connection", func(t *testing.T) {
+		pc, _ := dialer.peerConnection(fast.hostname)
+		wg := sync.WaitGroup{}
+		for i := 0; i < 20; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				ctx, cancel := context.WithTimeout(context.Background(), PeerTimeout)
+				defer cancel()
+				reply, err := pc.request(ctx, forwardFrame, payload)
+				if err != nil {
+					t.Errorf("request() error = %v", err)
+					return
+				}
+				response := forwardResponse{}
+				if err := json.Unmarshal(reply.Payload, &response); err != nil || reply.PublicKey != fastValidator.PublicKey() {
+					t.Errorf("request() = %s from %x, want answer of the peer", reply.Payload, reply.PublicKey)
+				}
+			}()
+		}
+		wg.Wait()
+
+		fast.connectionsMutex.Lock()
+		defer fast.connectionsMutex.Unlock()
+		if len(fast.connections) != 1 {
+			t.Errorf("peer has %d connections, want 1", len(fast.connections))
+		}
+	})
+
+	t.Run("Slow peer does not hold back others", func(t *testing.T) {
+		replies := make(chan string, 2)
+		for _, hostname := range []string{slow.hostname, fast.hostname} {
+			go func(hostname string) {
+				pc, _ := dialer.peerConnection(hostname)
+				ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+				defer cancel()
+				if _, err := pc.request(ctx, forwardFrame, payload); err == nil {
+					replies <- hostname
+				} else {
+					replies <- ""
+				}
+			}(hostname)
+		}
+
+		if first := <-replies; first != fast.hostname {
+			t.Errorf("first reply from %q, want %q", first, fast.hostname)
+		}
+		if second := <-replies; second != "" {
+			t.Errorf("reply from %q, want slow peer to time out", second)
+		}
+	})
+}
+
+func TestPeerConnection_Reconnect(t *testing.T) {
+	dialer, _ := startTestNode(t, newKeyValidator())
+	t.Cleanup(dialer.closePeerConnections)
+	peerValidator := newKeyValidator()
+	peer, stop := startTestNode(t, peerValidator)
+	trust(t, peer, dialer)
+	payload := forwardRequest(t)
+	pc, _ := dialer.peerConnection(peer.hostname)
+
+	request := func() error {
+		ctx, cancel := context.WithTimeout(context.Background(), PeerTimeout)
+		defer cancel()
+		_, err := pc.request(ctx, forwardFrame, payload)
+		return err
+	}
+	if err := request(); err != nil {
+		t.Fatalf("request() error = %v", err)
+	}
+
+	// closing the server leaves websockets open, Stop closes them as well
+	stop()
+	_ = peer.Stop(context.Background())
+	deadline := time.Now().Add(PeerTimeout)
+	for request() == nil && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+	}
+	started := time.Now()
+	if err := request(); err == nil || time.Since(started) > time.Second {
+		t.Errorf("request() to stopped peer error = %v after %v, want a failure at once", err, time.Since(started))
+	}
+
+	// the peer comes back on the same address
+	listener, err := net.Listen("tcp", peer.hostname)
+	if err != nil {
+		t.Skip("address of the peer is taken:", err)
+	}
+	server := &http.Server{Handler: peer.server.Handler}
+	go func() { _ = server.Serve(listener) }()
+	t.Cleanup(func() { _ = server.Close() })
+
+	deadline = time.Now().Add(2 * PeerRetryMax)
+	for request() != nil {
+		if time.Now().After(deadline) {
+			t.Fatal("peer connection was not restored")
+		}
+		time.Sleep(PeerRetryMin)
+	}
+}
+
+func TestPeerConnection_QueueFull(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	pc := &peerConnection{queue: make(chan envelope, 1), ctx: ctx, cancel: cancel, pending: map[uint64]chan peerReply{}}
+
+	tests := []struct {
+		name    string
+		close   bool
+		wantErr error
+	}{
+		{name: "Frame is queued", wantErr: nil},
+		{name: "Full queue drops frame", wantErr: ErrPeerQueueFull},
+		{name: "Closed connection drops frame", close: true, wantErr: ErrPeerDisconnected},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.close {
+				pc.close()
+			}
+			if err := pc.notify(gossipFrame, json.RawMessage(`{}`)); err != tt.wantErr {
+				t.Errorf("notify() error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestHandleEnvelope_NotValidator(t *testing.T) {
+	node, _ := startTestNode(t, newKeyValidator())
+	engine := &testEngine{}
+	node.Consensus = engine
+	validator, listed := newKeyValidator(), newKeyValidator()
+	trust(t, node, &NetworkNode{hostname: "127.0.0.1:1", MyPublicKey: validator.PublicKey()})
+	// peers of the node list are not validators unless recorded on chain
+	node.setNodeList([]PeerInfo{{Hostname: "127.0.0.1:1", PublicKey: validator.PublicKey()}, {Hostname: "127.0.0.1:2", PublicKey: listed.PublicKey()}})
+
+	tests := []struct {
+		name         string
+		publicKey    keys.PublicKeyBytes
+		kind         string
+		payload      json.RawMessage
+		wantReply    bool
+		wantMessages int
+	}{
+		{name: "Consensus from validator", publicKey: validator.PublicKey(), kind: consensusFrame, payload: json.RawMessage(`{}`), wantMessages: 1},
+		{name: "Consensus from other peer", publicKey: newKeyValidator().PublicKey(), kind: consensusFrame, payload: json.RawMessage(`{}`), wantMessages: 1},
+		{name: "Consensus from listed peer that is not a validator", publicKey: listed.PublicKey(), kind: consensusFrame, payload: json.RawMessage(`{}`), wantMessages: 1},
+		{name: "Forwarded transactions from other peer", publicKey: newKeyValidator().PublicKey(), kind: forwardFrame, payload: json.RawMessage(`{"transactions":[]}`), wantReply: true, wantMessages: 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := node.handleEnvelope(tt.publicKey, envelope{ID: 1, Kind: tt.kind, Payload: tt.payload}); (got != nil) != tt.wantReply {
+				t.Errorf("handleEnvelope() = %v, want reply %v", got, tt.wantReply)
+			}
+			engine.mutex.Lock()
+			defer engine.mutex.Unlock()
+			if engine.messages != tt.wantMessages {
+				t.Errorf("engine got %d messages, want %d", engine.messages, tt.wantMessages)
+			}
+		})
+	}
+}
@@ -0,0 +1,141 @@
+package network_node
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"github.com/gorilla/websocket"
+	"golang.org/x/crypto/curve25519"
+	"golang.org/x/crypto/hkdf"
+	"io"
+	"time"
+)
+
+// secureChannelInfo separates keys of the peer channel from other uses of the shared secret
+const secureChannelInfo = "Digital-Voting peer channel"
+
+var ErrSecureChannel = errors.New("message of peer failed authentication")
+
+// ephemeralKey is an X25519 key pair used for one handshake, it gives the channel forward secrecy
+type ephemeralKey struct {
+	private [32]byte
+	public  [32]byte
+}
+
+func newEphemeralKey() (ephemeralKey, error) {
+	key := ephemeralKey{}
+	_, err := rand.Read(key.private[:])
+	if err != nil {
+		return ephemeralKey{}, err
+	}
+	public, err := curve25519.X25519(key.private[:], curve25519.Basepoint)
+	if err != nil {
+		return ephemeralKey{}, err
+	}
+	copy(key.public[:], public)
+	return key, nil
+}
+
+// secureConn encrypts and authenticates messages over a websocket with keys agreed in a handshake.
+// Each message uses the next nonce of its direction, so replayed, reordered or altered messages fail to open.
+// It allows one reader and one writer at a time
+type secureConn struct {
+	conn         *websocket.Conn
+	sealer       cipher.AEAD
+	opener       cipher.AEAD
+	sendNonce    uint64
+	receiveNonce uint64
+
+	// version and capabilities of the peer are negotiated in the handshake
+	version      uint16
+	capabilities []string
+}
+
+// newSecureConn derives keys of both directions from ephemeral keys and challenges of a handshake,
+// ephemeral keys are signed in the handshake so only the authenticated peer can derive them
+func newSecureConn(conn *websocket.Conn, own ephemeralKey, theirs [32]byte, dialerChallenge [32]byte, listenerChallenge [32]byte, dialer bool) (*secureConn, error) {
+	shared, err := curve25519.X25519(own.private[:], theirs[:])
+	if err != nil {
+		return nil, err
+	}
+	salt := append(dialerChallenge[:], listenerChallenge[:]...)
+	reader := hkdf.New(sha256.New, shared, salt, []byte(secureChannelInfo))
+
+	aeads := make([]cipher.AEAD, 2)
+	for i := range aeads {
+		key := make([]byte, 32)
+		_, err = io.ReadFull(reader, key)
+		if err != nil {
+			return nil, err
+		}
+		block, err := aes.NewCipher(key)
+		if err != nil {
+			return nil, err
+		}
+		aeads[i], err = cipher.NewGCM(block)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	// the first key encrypts messages of the dialer, the second ones of the listener
+	if dialer {
+		return &secureConn{conn: conn, sealer: aeads[0], opener: aeads[1]}, nil
+	}
+	return &secureConn{conn: conn, sealer: aeads[1], opener: aeads[0]}, nil
+}
+
+func nonceBytes(size int, counter uint64) []byte {
+	nonce := make([]byte, size)
+	binary.BigEndian.PutUint64(nonce[size-8:], counter)
+	return nonce
+}
+
+func (sc *secureConn) seal(v interface{}) ([]byte, error) {
+	plaintext, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	sealed := sc.sealer.Seal(nil, nonceBytes(sc.sealer.NonceSize(), sc.sendNonce), plaintext, nil)
+	sc.sendNonce++
+	return sealed, nil
+}
+
+func (sc *secureConn) open(sealed []byte, v interface{}) error {
+	plaintext, err := sc.opener.Open(nil, nonceBytes(sc.opener.NonceSize(), sc.receiveNonce), sealed, nil)
+	if err != nil {
+		return ErrSecureChannel
+	}
+	sc.receiveNonce++
+	return json.Unmarshal(plaintext, v)
+}
+
+// WriteJSON encrypts v as the next message
+func (sc *secureConn) WriteJSON(v interface{}) error {
+	sealed, err := sc.seal(v)
+	if err != nil {
+		return err
+	}
+	return sc.conn.WriteMessage(websocket.BinaryMessage, sealed)
+}
+
+// ReadJSON decrypts the next message into v
+func (sc *secureConn) ReadJSON(v interface{}) error {
+	_, sealed, err := sc.conn.ReadMessage()
+	if err != nil {
+		return err
+	}
+	return sc.open(sealed, v)
+}
+
+func (sc *secureConn) SetWriteDeadline(t time.Time) error {
+	return sc.conn.SetWriteDeadline(t)
+}
+
+func (sc *secureConn) Close() error {
+	return sc.conn.Close()
+}
@@ -0,0 +1,58 @@
+package network_node
+
+import (
+	"testing"
+)
+
+func newTestChannel(t *testing.T) (dialer *secureConn, listener *secureConn) {
+	dialerKey, _ := newEphemeralKey()
+	listenerKey, _ := newEphemeralKey()
+	dialerChallenge, listenerChallenge := [32]byte{1}, [32]byte{2}
+	dialer, err := newSecureConn(nil, dialerKey, listenerKey.public, dialerChallenge, listenerChallenge, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	listener, err = newSecureConn(nil, listenerKey, dialerKey.public, dialerChallenge, listenerChallenge, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return dialer, listener
+}
+
+func TestSecureConn(t *testing.T) {
+	dialer, listener := newTestChannel(t)
+	first, _ := dialer.seal(envelope{ID: 1, Kind: forwardFrame})
+	second, _ := dialer.seal(envelope{ID: 2, Kind: forwardFrame})
+	tampered := append([]byte{}, second...)
+	tampered[0] ^= 1
+	answer, _ := listener.seal(envelope{ReplyTo: 1})
+	_, stranger := newTestChannel(t)
+
+	tests := []struct {
+		name     string
+		receiver *secureConn
+		sealed   []byte
+		wantID   uint64
+		wantErr  error
+	}{
+		{name: "Message of the dialer", receiver: listener, sealed: first, wantID: 1},
+		{name: "Replayed message", receiver: listener, sealed: first, wantErr: ErrSecureChannel},
+		{name: "Altered message", receiver: listener, sealed: tampered, wantErr: ErrSecureChannel},
+		{name: "Next message", receiver: listener, sealed: second, wantID: 2},
+		{name: "Message of another channel", receiver: stranger, sealed: first, wantErr: ErrSecureChannel},
+		{name: "Own message echoed back", receiver: dialer, sealed: first, wantErr: ErrSecureChannel},
+		{name: "Answer of the listener", receiver: dialer, sealed: answer},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			message := envelope{}
+			err := tt.receiver.open(tt.sealed, &message)
+			if err != tt.wantErr {
+				t.Fatalf("open() error = %v, want %v", err, tt.wantErr)
+			}
+			if message.ID != tt.wantID {
+				t.Errorf("open() ID = %d, want %d", message.ID, tt.wantID)
+			}
+		})
+	}
+}
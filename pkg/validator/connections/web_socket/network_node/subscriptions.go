@@ -0,0 +1,362 @@
+package network_node
+
+import (
+	"errors"
+	blk "github.com/Digital-Voting-Team/Digital-Voting/pkg/blockchain/block"
+	tx "github.com/Digital-Voting-Team/Digital-Voting/pkg/blockchain/transaction"
+	ts "github.com/Digital-Voting-Team/Digital-Voting/pkg/blockchain/transaction/transaction_specific"
+	"github.com/Digital-Voting-Team/Digital-Voting/pkg/logging"
+	"github.com/Digital-Voting-Team/Digital-Voting/pkg/signature/keys"
+	"github.com/Digital-Voting-Team/Digital-Voting/pkg/validator"
+	"github.com/Digital-Voting-Team/Digital-Voting/pkg/validator/query"
+	"github.com/gorilla/websocket"
+	"log"
+	"net"
+	"net/http"
+	"time"
+)
+
+// Topics clients subscribe to on /subscribe
+const (
+	// BlocksTopic sends every approved block
+	BlocksTopic = "blocks"
+	// TransactionsTopic sends transactions affecting a public key: sent by it, creating its account,
+	// adding it to a group or to a whitelist of a voting, or reporting or slashing it
+	TransactionsTopic = "transactions"
+	// VotesTopic sends votes on a voting
+	VotesTopic = "votes"
+	// ResultsTopic sends current results of a voting on subscribing and after every block with votes on it
+	ResultsTopic = "results"
+)
+
+// SubscriptionWriteTimeout bounds writing one event, a client not reading events is disconnected
+const SubscriptionWriteTimeout = 10 * time.Second
+
+// MaxSubscribers bounds subscriptions served at once, MaxSubscribersPerHost bounds ones of one remote address
+const (
+	MaxSubscribers        = 1024
+	MaxSubscribersPerHost = 16
+)
+
+var (
+	ErrSubscriptionTopic    = errors.New("unknown subscription topic")
+	ErrSubscriptionCursor   = errors.New("from_height is above the height of the chain")
+	ErrSubscriptionDisabled = errors.New("node does not serve subscriptions")
+	ErrTooManySubscribers   = errors.New("too many subscriptions")
+
+	errSubscriberGone = errors.New("subscriber disconnected")
+)
+
+// Chain is the part of the blockchain subscriptions replay blocks from, it is safe for concurrent use
+type Chain interface {
+	// GetBlocks returns a copy of the list of blocks starting with genesis
+	GetBlocks() []*blk.Block
+}
+
+// SubscriptionRequest is the first message of a client on /subscribe. PublicKey is set for TransactionsTopic,
+// Voting for VotesTopic and ResultsTopic. Events of blocks from FromHeight on are replayed before new ones,
+// without it only blocks approved after subscribing are sent. ResultsTopic ignores FromHeight since every
+// event of it has complete results
+type SubscriptionRequest struct {
+	Topic      string  `json:"topic"`
+	PublicKey  string  `json:"public_key,omitempty"`
+	Voting     string  `json:"voting,omitempty"`
+	FromHeight *uint64 `json:"from_height,omitempty"`
+}
+
+// Event is sent for every block with something of the topic, blocks with nothing of it are skipped.
+// A client reconnecting with FromHeight one above Height of its last event does not miss events
+type Event struct {
+	Topic        string                 `json:"topic"`
+	Height       uint64                 `json:"height"`
+	Block        *query.BlockDTO        `json:"block,omitempty"`
+	Transactions []query.TransactionDTO `json:"transactions,omitempty"`
+	Results      []uint64               `json:"results,omitempty"`
+}
+
+// subscription matches blocks against the topic, blocks have to be applied one by one from the start height
+type subscription struct {
+	topic     string
+	publicKey keys.PublicKeyBytes
+	voting    [32]byte
+	// results counts votes of a voting in blocks committed so far
+	results func(voting [32]byte) []uint64
+
+	// next is the height of the block applied next
+	next uint64
+}
+
+// newSubscription makes a subscription to the topic of request, chainHeight is the height the next block will be added at
+func newSubscription(request SubscriptionRequest, chainHeight uint64, results func(voting [32]byte) []uint64) (*subscription, uint64, error) {
+	s := &subscription{topic: request.Topic, results: results}
+	var err error
+	switch request.Topic {
+	case BlocksTopic:
+	case TransactionsTopic:
+		s.publicKey, err = query.DecodeKey(request.PublicKey)
+	case VotesTopic, ResultsTopic:
+		s.voting, err = query.DecodeHash(request.Voting)
+	default:
+		return nil, 0, ErrSubscriptionTopic
+	}
+	if err != nil {
+		return nil, 0, err
+	}
+
+	from := chainHeight
+	if request.FromHeight != nil && s.topic != ResultsTopic {
+		from = *request.FromHeight
+	}
+	if from > chainHeight {
+		return nil, 0, ErrSubscriptionCursor
+	}
+	s.next = from
+	return s, from, nil
+}
+
+// apply matches the block at height s.next and returns its event, false if the block has nothing of the topic
+func (s *subscription) apply(block *blk.Block) (Event, bool) {
+	height := s.next
+	s.next++
+	event := Event{Topic: s.topic, Height: height}
+
+	switch s.topic {
+	case BlocksTopic:
+		dto := query.NewBlockDTO(height, block)
+		event.Block = &dto
+		return event, true
+	case TransactionsTopic:
+		for _, transaction := range block.Body.Transactions {
+			if affects(transaction, s.publicKey) {
+				event.Transactions = append(event.Transactions, query.NewTransactionDTO(height, transaction))
+			}
+		}
+		return event, len(event.Transactions) != 0
+	case VotesTopic:
+		for _, transaction := range block.Body.Transactions {
+			if ballot, ok := ballotOf(transaction); ok && ballot.VotingLink == s.voting {
+				event.Transactions = append(event.Transactions, query.NewTransactionDTO(height, transaction))
+			}
+		}
+		return event, len(event.Transactions) != 0
+	case ResultsTopic:
+		// blocks are applied after they are committed, so results already include the block
+		for _, transaction := range block.Body.Transactions {
+			if ballot, ok := ballotOf(transaction); ok && ballot.VotingLink == s.voting {
+				event.Results = s.results(s.voting)
+				return event, true
+			}
+		}
+		return event, false
+	default:
+		return event, false
+	}
+}
+
+// current is the event of ResultsTopic sent on subscribing, it is of the last block committed before
+func (s *subscription) current() Event {
+	return Event{Topic: s.topic, Height: s.next - 1, Results: s.results(s.voting)}
+}
+
+func ballotOf(transaction tx.ITransaction) (tx.Ballot, bool) {
+	caster, ok := transaction.(tx.BallotCaster)
+	if !ok {
+		return tx.Ballot{}, false
+	}
+	return caster.GetBallot()
+}
+
+// affects reports whether transaction is sent by publicKey or names it, anonymous votes name nobody
+func affects(transaction tx.ITransaction, publicKey keys.PublicKeyBytes) bool {
+	signed, ok := transaction.(*tx.Transaction)
+	if !ok {
+		return false
+	}
+	if signed.PublicKey == publicKey {
+		return true
+	}
+
+	switch body := signed.TxBody.(type) {
+	case *ts.TxAccountCreation:
+		return body.NewPublicKey == publicKey
+	case *ts.TxGroupCreation:
+		for _, member := range body.MembersPublicKeys {
+			if member == publicKey {
+				return true
+			}
+		}
+	case *ts.TxVotingCreation:
+		for _, member := range body.Whitelist {
+			if member == publicKey {
+				return true
+			}
+		}
+	case *ts.TxEvidence:
+		return body.Reporter == publicKey || body.Evidence.Offender() == publicKey
+	}
+	return false
+}
+
+// HandleWebSocketSubscribe sends events of the topic the client subscribes to with SubscriptionRequest
+// until the client disconnects or the node stops. Subscriptions count against the connection rate limit
+// of the remote address and against MaxSubscribers and MaxSubscribersPerHost
+func (n *NetworkNode) HandleWebSocketSubscribe(w http.ResponseWriter, r *http.Request) {
+	err := n.Admission.AdmitConnection(r.RemoteAddr)
+	if err != nil {
+		logging.Errorf("Subscription from %s not admitted: %v", r.RemoteAddr, err)
+		http.Error(w, err.Error(), http.StatusTooManyRequests)
+		return
+	}
+	host, err := n.acquireSubscriber(r.RemoteAddr)
+	if err != nil {
+		logging.Errorf("Subscription from %s not admitted: %v", r.RemoteAddr, err)
+		http.Error(w, err.Error(), http.StatusServiceUnavailable)
+		return
+	}
+	defer n.releaseSubscriber(host)
+
+	conn, err := n.upgrade(w, r)
+	if err != nil {
+		logging.Errorln("WebSocket upgrade failed:", err)
+		return
+	}
+	defer n.release(conn)
+
+	request := SubscriptionRequest{}
+	err = conn.ReadJSON(&request)
+	if err != nil {
+		logging.Errorln("read in HandleWebSocketSubscribe:", err)
+		return
+	}
+
+	if n.Chain == nil || n.BlockFeed == nil {
+		writeSubscriptionError(conn, ErrSubscriptionDisabled)
+		return
+	}
+	s, from, err := newSubscription(request, uint64(len(n.Chain.GetBlocks())), n.Validator.GetResults)
+	if err != nil {
+		writeSubscriptionError(conn, err)
+		return
+	}
+
+	// the client sends nothing more, reading notices it disconnecting
+	closed := make(chan struct{})
+	go func() {
+		defer close(closed)
+		for {
+			if _, _, err := conn.NextReader(); err != nil {
+				return
+			}
+		}
+	}()
+
+	err = n.serveSubscription(conn, s, from, closed)
+	if err != nil && err != errSubscriberGone {
+		logging.Errorf("Subscription to %s ended: %v", request.Topic, err)
+	}
+}
+
+// serveSubscription sends events of blocks from height from on. Blocks of the chain are replayed first,
+// blocks approved meanwhile come from BlockFeed, subscribed to before the chain is read so none is missed.
+// A client falling behind BlockFeed catches up from the chain again
+func (n *NetworkNode) serveSubscription(conn *websocket.Conn, s *subscription, from uint64, closed <-chan struct{}) error {
+	send := func(block *blk.Block) error {
+		event, ok := s.apply(block)
+		if !ok || event.Height < from {
+			return nil
+		}
+		_ = conn.SetWriteDeadline(time.Now().Add(SubscriptionWriteTimeout))
+		return conn.WriteJSON(event)
+	}
+
+	// blocks committed after the current results were read are applied below, so no vote is missed
+	if s.topic == ResultsTopic {
+		_ = conn.SetWriteDeadline(time.Now().Add(SubscriptionWriteTimeout))
+		err := conn.WriteJSON(s.current())
+		if err != nil {
+			return err
+		}
+	}
+
+	for {
+		blocks, cancel := n.BlockFeed.Subscribe()
+		history := n.Chain.GetBlocks()
+		for s.next < uint64(len(history)) {
+			err := send(history[s.next])
+			if err != nil {
+				cancel()
+				return err
+			}
+		}
+
+		err := n.followFeed(blocks, s, send, closed)
+		cancel()
+		if err != nil {
+			return err
+		}
+		log.Printf("Subscription to %s fell behind at height %d, catching up from the chain", s.topic, s.next)
+	}
+}
+
+// followFeed sends blocks of the feed until the client disconnects or the feed drops the subscription,
+// the latter returns nil so the caller catches up from the chain
+func (n *NetworkNode) followFeed(blocks <-chan validator.CommittedBlock, s *subscription, send func(block *blk.Block) error, closed <-chan struct{}) error {
+	for {
+		select {
+		case <-closed:
+			return errSubscriberGone
+		case committed, ok := <-blocks:
+			if !ok {
+				return nil
+			}
+			// blocks replayed from the chain are in the feed as well
+			if committed.Height < s.next {
+				continue
+			}
+			if committed.Height > s.next {
+				return nil
+			}
+			err := send(committed.Block)
+			if err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// acquireSubscriber counts a subscription of the remote address unless a limit is reached,
+// the returned host is passed to releaseSubscriber once the subscription ends
+func (n *NetworkNode) acquireSubscriber(remoteAddress string) (string, error) {
+	host, _, err := net.SplitHostPort(remoteAddress)
+	if err != nil {
+		host = remoteAddress
+	}
+
+	n.subscribersMutex.Lock()
+	defer n.subscribersMutex.Unlock()
+	if n.subscriberCount >= MaxSubscribers || n.subscribers[host] >= MaxSubscribersPerHost {
+		return "", ErrTooManySubscribers
+	}
+	n.subscribers[host]++
+	n.subscriberCount++
+	return host, nil
+}
+
+func (n *NetworkNode) releaseSubscriber(host string) {
+	n.subscribersMutex.Lock()
+	defer n.subscribersMutex.Unlock()
+	n.subscriberCount--
+	n.subscribers[host]--
+	if n.subscribers[host] == 0 {
+		delete(n.subscribers, host)
+	}
+}
+
+func writeSubscriptionError(conn *websocket.Conn, err error) {
+	err = conn.WriteJSON(struct {
+		Error string `json:"error"`
+	}{Error: err.Error()})
+	if err != nil {
+		logging.Errorln("Error writing response")
+	}
+}
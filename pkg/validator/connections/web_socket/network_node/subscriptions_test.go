@@ -0,0 +1,243 @@
+package network_node
+
+import (
+	"encoding/json"
+	blk "github.com/Digital-Voting-Team/Digital-Voting/pkg/blockchain/block"
+	tx "github.com/Digital-Voting-Team/Digital-Voting/pkg/blockchain/transaction"
+	ts "github.com/Digital-Voting-Team/Digital-Voting/pkg/blockchain/transaction/transaction_specific"
+	"github.com/Digital-Voting-Team/Digital-Voting/pkg/signature/keys"
+	ss "github.com/Digital-Voting-Team/Digital-Voting/pkg/signature/signatures/single_signature"
+	"github.com/Digital-Voting-Team/Digital-Voting/pkg/validator"
+	"github.com/Digital-Voting-Team/Digital-Voting/pkg/validator/query"
+	"github.com/gorilla/websocket"
+	"net/url"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+)
+
+// testChain is a chain committing blocks to its feed
+type testChain struct {
+	mutex  sync.Mutex
+	blocks []*blk.Block
+	feed   *validator.BlockFeed
+}
+
+func newTestChain() *testChain {
+	return &testChain{blocks: []*blk.Block{blk.NewBlock(nil, [32]byte{})}, feed: validator.NewBlockFeed()}
+}
+
+func (tc *testChain) GetBlocks() []*blk.Block {
+	tc.mutex.Lock()
+	defer tc.mutex.Unlock()
+	return append([]*blk.Block{}, tc.blocks...)
+}
+
+func (tc *testChain) commit(transactions ...tx.ITransaction) {
+	tc.mutex.Lock()
+	block := blk.NewBlock(transactions, tc.blocks[len(tc.blocks)-1].GetHash())
+	tc.blocks = append(tc.blocks, block)
+	height := uint64(len(tc.blocks) - 1)
+	tc.mutex.Unlock()
+	tc.feed.Publish(validator.CommittedBlock{Height: height, Block: block})
+}
+
+func signedTransaction(txType tx.TxType, body tx.TxBody, sender keys.PublicKeyBytes) *tx.Transaction {
+	transaction := tx.NewTransaction(txType, body)
+	transaction.Sign(sender, ss.SingleSignatureBytes{})
+	return transaction
+}
+
+func TestSubscription_Apply(t *testing.T) {
+	voter, other := newKeyValidator().PublicKey(), newKeyValidator().PublicKey()
+	creation := ts.NewTxVotingCreation(time.Now().Add(time.Hour), "Voting", []string{"Yes", "No"}, [][33]byte{voter})
+	voting := creation.GetHash()
+	createVoting := signedTransaction(tx.VotingCreation, creation, other)
+	vote := signedTransaction(tx.Vote, ts.NewTxVote(voting, 1), voter)
+	otherVote := signedTransaction(tx.Vote, ts.NewTxVote([32]byte{1}, 0), other)
+	blocks := []*blk.Block{
+		blk.NewBlock([]tx.ITransaction{createVoting}, [32]byte{}),
+		blk.NewBlock([]tx.ITransaction{otherVote}, [32]byte{}),
+		blk.NewBlock([]tx.ITransaction{vote, otherVote}, [32]byte{}),
+	}
+
+	tests := []struct {
+		name       string
+		request    SubscriptionRequest
+		wantHeight []uint64
+		wantCount  []int
+	}{
+		{name: "Blocks", request: SubscriptionRequest{Topic: BlocksTopic}, wantHeight: []uint64{0, 1, 2}, wantCount: []int{0, 0, 0}},
+		{name: "Transactions of whitelisted voter", request: SubscriptionRequest{Topic: TransactionsTopic, PublicKey: query.EncodeHex(voter[:])}, wantHeight: []uint64{0, 2}, wantCount: []int{1, 1}},
+		{name: "Transactions of sender", request: SubscriptionRequest{Topic: TransactionsTopic, PublicKey: query.EncodeHex(other[:])}, wantHeight: []uint64{0, 1, 2}, wantCount: []int{1, 1, 1}},
+		{name: "Votes on voting", request: SubscriptionRequest{Topic: VotesTopic, Voting: query.EncodeHex(voting[:])}, wantHeight: []uint64{2}, wantCount: []int{1}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			from := uint64(0)
+			tt.request.FromHeight = &from
+			s, _, err := newSubscription(tt.request, uint64(len(blocks)), nil)
+			if err != nil {
+				t.Fatalf("newSubscription() error = %v", err)
+			}
+
+			heights, counts := []uint64{}, []int{}
+			for _, block := range blocks {
+				if event, ok := s.apply(block); ok {
+					heights = append(heights, event.Height)
+					counts = append(counts, len(event.Transactions))
+				}
+			}
+			if !reflect.DeepEqual(heights, tt.wantHeight) || !reflect.DeepEqual(counts, tt.wantCount) {
+				t.Errorf("events at heights %v with %v transactions, want %v with %v", heights, counts, tt.wantHeight, tt.wantCount)
+			}
+		})
+	}
+}
+
+func TestSubscription_Results(t *testing.T) {
+	voter := newKeyValidator().PublicKey()
+	voting := [32]byte{7}
+	results := []uint64{0, 1}
+	from := uint64(0)
+	s, start, err := newSubscription(SubscriptionRequest{Topic: ResultsTopic, Voting: query.EncodeHex(voting[:]), FromHeight: &from}, 3,
+		func([32]byte) []uint64 { return results })
+	if err != nil {
+		t.Fatalf("newSubscription() error = %v", err)
+	}
+	if start != 3 {
+		t.Errorf("results start at height %d, want 3 ignoring FromHeight", start)
+	}
+	if event := s.current(); event.Height != 2 || !reflect.DeepEqual(event.Results, results) {
+		t.Errorf("current() = %+v, want results %v at height 2", event, results)
+	}
+
+	if _, ok := s.apply(blk.NewBlock(nil, [32]byte{})); ok {
+		t.Errorf("apply() of block without votes sent an event")
+	}
+	event, ok := s.apply(blk.NewBlock([]tx.ITransaction{signedTransaction(tx.Vote, ts.NewTxVote(voting, 1), voter)}, [32]byte{}))
+	if !ok || event.Height != 4 || !reflect.DeepEqual(event.Results, results) {
+		t.Errorf("apply() of block with vote = %+v, %v, want results %v at height 4", event, ok, results)
+	}
+}
+
+func TestNewSubscription_Invalid(t *testing.T) {
+	above := uint64(5)
+
+	tests := []struct {
+		name    string
+		request SubscriptionRequest
+		wantErr error
+	}{
+		{name: "Unknown topic", request: SubscriptionRequest{Topic: "mempool"}, wantErr: ErrSubscriptionTopic},
+		{name: "Cursor above the chain", request: SubscriptionRequest{Topic: BlocksTopic, FromHeight: &above}, wantErr: ErrSubscriptionCursor},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, _, err := newSubscription(tt.request, 3, nil); err != tt.wantErr {
+				t.Errorf("newSubscription() error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestHandleWebSocketSubscribe_Resume(t *testing.T) {
+	chain := newTestChain()
+	v := newKeyValidator()
+	v.results = []uint64{2, 1}
+	node, _ := startTestNode(t, v)
+	node.Chain, node.BlockFeed = chain, chain.feed
+
+	voter := newKeyValidator().PublicKey()
+	voting := [32]byte{7}
+	for answer := uint8(0); answer < 3; answer++ {
+		chain.commit(signedTransaction(tx.Vote, ts.NewTxVote(voting, answer), voter))
+	}
+
+	subscribe := func(request SubscriptionRequest) *websocket.Conn {
+		u := url.URL{Scheme: "ws", Host: node.hostname, Path: "subscribe"}
+		conn, _, err := websocket.DefaultDialer.Dial(u.String(), nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		t.Cleanup(func() { _ = conn.Close() })
+		if err := conn.WriteJSON(request); err != nil {
+			t.Fatal(err)
+		}
+		return conn
+	}
+	readEvent := func(conn *websocket.Conn) Event {
+		_ = conn.SetReadDeadline(time.Now().Add(PeerTimeout))
+		event := Event{}
+		if err := conn.ReadJSON(&event); err != nil {
+			t.Fatalf("ReadJSON() error = %v", err)
+		}
+		return event
+	}
+	readHeight := func(conn *websocket.Conn) uint64 {
+		return readEvent(conn).Height
+	}
+
+	// the client saw the vote at height 1 and resumes after it
+	from := uint64(2)
+	conn := subscribe(SubscriptionRequest{Topic: VotesTopic, Voting: query.EncodeHex(voting[:]), FromHeight: &from})
+	if height := readHeight(conn); height != 2 {
+		t.Errorf("replayed event at height %d, want 2", height)
+	}
+	chain.commit()
+	chain.commit(signedTransaction(tx.Vote, ts.NewTxVote(voting, 0), voter))
+	if height := readHeight(conn); height != 3 {
+		t.Errorf("replayed event at height %d, want 3", height)
+	}
+	if height := readHeight(conn); height != 5 {
+		t.Errorf("new event at height %d, want 5 after the block without votes", height)
+	}
+
+	// results start with the current ones whatever FromHeight is
+	conn = subscribe(SubscriptionRequest{Topic: ResultsTopic, Voting: query.EncodeHex(voting[:]), FromHeight: &from})
+	if event := readEvent(conn); event.Height != 5 || !reflect.DeepEqual(event.Results, v.results) {
+		t.Errorf("first results event = %+v, want %v at height 5", event, v.results)
+	}
+	chain.commit(signedTransaction(tx.Vote, ts.NewTxVote(voting, 1), voter))
+	if height := readHeight(conn); height != 6 {
+		t.Errorf("results event at height %d, want 6", height)
+	}
+
+	conn = subscribe(SubscriptionRequest{Topic: "mempool"})
+	response := struct {
+		Error string `json:"error"`
+	}{}
+	_ = conn.SetReadDeadline(time.Now().Add(PeerTimeout))
+	_, message, _ := conn.ReadMessage()
+	if err := json.Unmarshal(message, &response); err != nil || response.Error != ErrSubscriptionTopic.Error() {
+		t.Errorf("response = %s, want error %q", message, ErrSubscriptionTopic)
+	}
+}
+
+func TestNetworkNode_AcquireSubscriber(t *testing.T) {
+	node := &NetworkNode{subscribers: map[string]int{}}
+	for i := 0; i < MaxSubscribersPerHost; i++ {
+		if _, err := node.acquireSubscriber("10.0.0.1:1000"); err != nil {
+			t.Fatalf("acquireSubscriber() error = %v", err)
+		}
+	}
+
+	if _, err := node.acquireSubscriber("10.0.0.1:2000"); err != ErrTooManySubscribers {
+		t.Errorf("acquireSubscriber() over the host limit error = %v, want %v", err, ErrTooManySubscribers)
+	}
+	host, err := node.acquireSubscriber("10.0.0.2:1000")
+	if err != nil {
+		t.Errorf("acquireSubscriber() of another host error = %v", err)
+	}
+	node.releaseSubscriber(host)
+	node.releaseSubscriber("10.0.0.1")
+	if _, err := node.acquireSubscriber("10.0.0.1:3000"); err != nil {
+		t.Errorf("acquireSubscriber() after release error = %v", err)
+	}
+
+	node.subscriberCount = MaxSubscribers
+	if _, err := node.acquireSubscriber("10.0.0.3:1000"); err != ErrTooManySubscribers {
+		t.Errorf("acquireSubscriber() over the total limit error = %v, want %v", err, ErrTooManySubscribers)
+	}
+}
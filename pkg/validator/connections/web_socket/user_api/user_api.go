@@ -1,80 +1,101 @@
 package user_api
 
 import (
+	"context"
 	tx "github.com/Digital-Voting-Team/Digital-Voting/pkg/blockchain/transaction"
 	"github.com/Digital-Voting-Team/Digital-Voting/pkg/blockchain/transaction/transaction_json"
+	"github.com/Digital-Voting-Team/Digital-Voting/pkg/logging"
+	"github.com/Digital-Voting-Team/Digital-Voting/pkg/rejection"
+	"github.com/Digital-Voting-Team/Digital-Voting/pkg/validator/admission"
 	"github.com/gorilla/websocket"
-	"log"
 	"net/http"
 )
 
+// Validator accepts transactions of users, it is safe for concurrent use
+type Validator interface {
+	AddToMemPool(transaction tx.ITransaction) error
+}
+
 type UserApi struct {
 	upgrader websocket.Upgrader
 	hostname string
+	server   *http.Server
+
+	Admission *admission.Controller
 
-	TransactionChannel chan<- tx.ITransaction
-	ResponseChannel    <-chan bool
+	Validator Validator
 }
 
-func NewUserApi(
-	hostname string,
-	transactionChannel chan<- tx.ITransaction,
-	responseChannel <-chan bool,
-) *UserApi {
+func NewUserApi(hostname string, v Validator) *UserApi {
 	ua := &UserApi{
-		hostname:           hostname,
-		TransactionChannel: transactionChannel,
-		ResponseChannel:    responseChannel,
+		hostname:  hostname,
+		Admission: admission.NewController(admission.DefaultConfig()),
+		Validator: v,
 	}
 
-	http.HandleFunc("/transaction", ua.HandleWebSocketNewTransaction)
+	mux := http.NewServeMux()
+	mux.HandleFunc("/transaction", ua.HandleWebSocketNewTransaction)
+	ua.server = &http.Server{Addr: hostname, Handler: mux}
 
 	return ua
 }
 
-// Start start user api
+// Start start user api, it returns nil once Stop was called
 func (ua *UserApi) Start() error {
-	return http.ListenAndServe(ua.hostname, nil)
+	err := ua.server.ListenAndServe()
+	if err == http.ErrServerClosed {
+		return nil
+	}
+	return err
+}
+
+// Stop stops accepting connections, ctx bounds the time requests in progress have to finish
+func (ua *UserApi) Stop(ctx context.Context) error {
+	return ua.server.Shutdown(ctx)
 }
 
 func (ua *UserApi) HandleWebSocketNewTransaction(w http.ResponseWriter, r *http.Request) {
 	conn, err := ua.upgrader.Upgrade(w, r, nil)
 	if err != nil {
-		log.Println("WebSocket upgrade failed:", err)
+		logging.Errorln("WebSocket upgrade failed:", err)
 		return
 	}
 	defer func(conn *websocket.Conn) {
 		err = conn.Close()
 		if err != nil {
-			log.Println("Error closing connection:", err)
+			logging.Errorln("Error closing connection:", err)
 		}
 	}(conn)
 
-	ua.addNewTransaction(conn)
+	ua.addNewTransaction(conn, r.RemoteAddr)
 }
 
-func (ua *UserApi) addNewTransaction(conn *websocket.Conn) {
+func (ua *UserApi) addNewTransaction(conn *websocket.Conn, remoteAddress string) {
 	_, message, err := conn.ReadMessage()
 	if err != nil {
-		log.Println("read in ReadMessages:", err)
+		logging.Errorln("read in ReadMessages:", err)
 		return
 	}
 
-	newTxJson := &transaction_json.JSONTransaction{}
-	transaction, err := newTxJson.UnmarshallJSON(message)
-	if err != nil {
-		log.Println("Error reading transaction from UserAPI")
-		return
+	if err = ua.Admission.AdmitConnection(remoteAddress); err != nil {
+		logging.Errorf("Transaction from %s not admitted: %v", remoteAddress, err)
+	} else {
+		newTxJson := &transaction_json.JSONTransaction{}
+		var transaction tx.ITransaction
+		transaction, err = newTxJson.UnmarshallJSON(message)
+		if err != nil {
+			logging.Errorln("Error reading transaction from UserAPI:", err)
+		} else {
+			err = ua.Validator.AddToMemPool(transaction)
+		}
 	}
 
-	ua.TransactionChannel <- transaction
-	success := <-ua.ResponseChannel
-
 	err = conn.WriteJSON(struct {
-		Response bool `json:"response"`
-	}{Response: success})
+		Response bool             `json:"response"`
+		Error    *rejection.Error `json:"error,omitempty"`
+	}{Response: err == nil, Error: rejection.FromError(err)})
 	if err != nil {
-		log.Println("Error writing response")
+		logging.Errorln("Error writing response")
 		return
 	}
 }
@@ -1,53 +1,240 @@
 package validator
 
 import (
+	"container/list"
+	"encoding/json"
 	tx "github.com/Digital-Voting-Team/Digital-Voting/pkg/blockchain/transaction"
-	"math"
+	"github.com/Digital-Voting-Team/Digital-Voting/pkg/blockchain/transaction/transaction_json"
+	ts "github.com/Digital-Voting-Team/Digital-Voting/pkg/blockchain/transaction/transaction_specific"
+	"github.com/Digital-Voting-Team/Digital-Voting/pkg/signature/keys"
+	"os"
 	"sync"
+	"time"
 )
 
+// expiryInterval is how often expired transactions are looked for
+const expiryInterval = time.Second
+
+// MemPoolConfig limits the number of pending transactions and the time they wait for a block
+type MemPoolConfig struct {
+	MaxSize      int
+	MaxPerSender int
+	TTL          time.Duration
+}
+
+func DefaultMemPoolConfig() MemPoolConfig {
+	return MemPoolConfig{
+		MaxSize:      10000,
+		MaxPerSender: 64,
+		TTL:          time.Hour,
+	}
+}
+
+// sender identifies who a transaction comes from: public key of signed transactions
+// and key image of anonymous votes, which is the same for all votes of one ring member
+type sender [33]byte
+
+// ballot identifies a pending vote, a voter has at most one pending vote in a voting
+// since a block with two of them is rejected
+type ballot struct {
+	votingLink [32]byte
+	voter      [33]byte
+}
+
+type memPoolEntry struct {
+	transaction tx.ITransaction
+	hash        [32]byte
+	sender      sender
+	ballot      *ballot
+	offender    *keys.PublicKeyBytes
+	added       time.Time
+}
+
+// MemPool keeps pending transactions in arrival order indexed by hash
 type MemPool struct {
-	mutex        sync.Mutex
-	Transactions []tx.ITransaction
+	mutex   sync.Mutex
+	config  MemPoolConfig
+	order   *list.List
+	index   map[[32]byte]*list.Element
+	senders map[sender]int
+	ballots map[ballot]struct{}
+	// offenders are validators with pending evidence, a block reporting one twice is rejected
+	offenders map[keys.PublicKeyBytes]struct{}
+	// admitted keeps admission times of transactions taken for a block or loaded from file
+	// until they are restored, so restoring them does not extend their TTL
+	admitted map[[32]byte]time.Time
+	added    chan struct{}
+
+	lastExpiry time.Time
 }
 
 func NewMemPool() *MemPool {
+	return NewMemPoolWithConfig(DefaultMemPoolConfig())
+}
+
+func NewMemPoolWithConfig(config MemPoolConfig) *MemPool {
 	return &MemPool{
-		Transactions: []tx.ITransaction{},
+		config:  config,
+		order:   list.New(),
+		index:   map[[32]byte]*list.Element{},
+		senders: map[sender]int{},
+		ballots: map[ballot]struct{}{},
+
+		offenders: map[keys.PublicKeyBytes]struct{}{},
+		admitted:  map[[32]byte]time.Time{},
+		added:     make(chan struct{}, 1),
+	}
+}
+
+func (mp *MemPool) Config() MemPoolConfig {
+	return mp.config
+}
+
+// Added is signalled after transactions are added, signals that were not received yet are merged into one
+func (mp *MemPool) Added() <-chan struct{} {
+	return mp.added
+}
+
+func getSender(transaction tx.ITransaction) sender {
+	switch exact := transaction.(type) {
+	case *tx.Transaction:
+		return sender(exact.PublicKey)
+	case *ts.TxVoteAnonymous:
+		return sender(exact.KeyImage)
+	default:
+		return sender{}
+	}
+}
+
+func getBallot(transaction tx.ITransaction) *ballot {
+	caster, ok := transaction.(tx.BallotCaster)
+	if !ok {
+		return nil
+	}
+	castBallot, ok := caster.GetBallot()
+	if !ok {
+		return nil
 	}
+	return &ballot{votingLink: castBallot.VotingLink, voter: castBallot.Voter}
+}
+
+// hasBallot reports whether another vote of the same voter in the same voting is pending
+func (mp *MemPool) hasBallot(transactionBallot *ballot) bool {
+	if transactionBallot == nil {
+		return false
+	}
+	_, exists := mp.ballots[*transactionBallot]
+	return exists
+}
+
+func getOffender(transaction tx.ITransaction) *keys.PublicKeyBytes {
+	body, ok := transaction.GetTxBody().(tx.EvidenceBody)
+	if !ok {
+		return nil
+	}
+	offender := body.GetOffender()
+	return &offender
+}
+
+// hasOffender reports whether other evidence against the same validator is pending
+func (mp *MemPool) hasOffender(offender *keys.PublicKeyBytes) bool {
+	if offender == nil {
+		return false
+	}
+	_, exists := mp.offenders[*offender]
+	return exists
 }
 
 func (mp *MemPool) GetTransactionsCount() int {
 	mp.mutex.Lock()
 	defer mp.mutex.Unlock()
-	return len(mp.Transactions)
+	mp.removeExpired()
+	return mp.order.Len()
 }
 
-func (mp *MemPool) IsInMemPool(transaction tx.ITransaction) bool {
-	for _, v := range mp.Transactions {
-		if v == transaction {
-			return true
-		}
+// GetTransactions returns pending transactions in arrival order without removing them
+func (mp *MemPool) GetTransactions() []tx.ITransaction {
+	mp.mutex.Lock()
+	defer mp.mutex.Unlock()
+	mp.removeExpired()
+	transactions := make([]tx.ITransaction, 0, mp.order.Len())
+	for element := mp.order.Front(); element != nil; element = element.Next() {
+		transactions = append(transactions, element.Value.(*memPoolEntry).transaction)
 	}
-	return false
+	return transactions
 }
 
+// IsInMemPool compares transactions by hash since the same transaction may arrive from several peers
+func (mp *MemPool) IsInMemPool(transaction tx.ITransaction) bool {
+	mp.mutex.Lock()
+	defer mp.mutex.Unlock()
+	_, exists := mp.index[transaction.GetHash()]
+	return exists
+}
+
+// AddToMemPool rejects duplicates, second votes of a voter, second evidence against a validator
+// and transactions over the size or the per-sender limit
 func (mp *MemPool) AddToMemPool(newTransaction tx.ITransaction) bool {
 	mp.mutex.Lock()
 	defer mp.mutex.Unlock()
-	if !mp.IsInMemPool(newTransaction) {
-		mp.Transactions = append(mp.Transactions, newTransaction)
-		return true
+	mp.removeExpired()
+
+	hash := newTransaction.GetHash()
+	if _, exists := mp.index[hash]; exists {
+		return false
+	}
+	if mp.order.Len() >= mp.config.MaxSize {
+		return false
 	}
-	return false
+	transactionSender := getSender(newTransaction)
+	if mp.senders[transactionSender] >= mp.config.MaxPerSender {
+		return false
+	}
+	transactionBallot := getBallot(newTransaction)
+	if mp.hasBallot(transactionBallot) {
+		return false
+	}
+	offender := getOffender(newTransaction)
+	if mp.hasOffender(offender) {
+		return false
+	}
+
+	mp.insert(newTransaction, hash, transactionSender, transactionBallot, offender, time.Now(), false)
+	return true
 }
 
+// RestoreMemPool puts transactions of a block that was not committed back in front,
+// limits are not checked since the transactions were admitted before, and they keep their admission time
 func (mp *MemPool) RestoreMemPool(transactions []tx.ITransaction) {
+	mp.mutex.Lock()
+	defer mp.mutex.Unlock()
+	for i := len(transactions) - 1; i >= 0; i-- {
+		hash := transactions[i].GetHash()
+		added, wasAdmitted := mp.admitted[hash]
+		if !wasAdmitted {
+			added = time.Now()
+		}
+		delete(mp.admitted, hash)
+		if time.Since(added) > mp.config.TTL {
+			continue
+		}
+
+		transactionBallot, offender := getBallot(transactions[i]), getOffender(transactions[i])
+		if _, exists := mp.index[hash]; !exists && !mp.hasBallot(transactionBallot) && !mp.hasOffender(offender) {
+			mp.insert(transactions[i], hash, getSender(transactions[i]), transactionBallot, offender, added, true)
+		}
+	}
+}
+
+// RemoveTransactions drops transactions included in a block, they reach every MemPool through gossip
+func (mp *MemPool) RemoveTransactions(transactions []tx.ITransaction) {
 	mp.mutex.Lock()
 	defer mp.mutex.Unlock()
 	for _, transaction := range transactions {
-		if !mp.IsInMemPool(transaction) {
-			mp.Transactions = append([]tx.ITransaction{transaction}, mp.Transactions...)
+		hash := transaction.GetHash()
+		delete(mp.admitted, hash)
+		if element, exists := mp.index[hash]; exists {
+			mp.remove(element)
 		}
 	}
 }
@@ -55,8 +242,147 @@ func (mp *MemPool) RestoreMemPool(transactions []tx.ITransaction) {
 func (mp *MemPool) GetWithUpperBound(upperBound int) []tx.ITransaction {
 	mp.mutex.Lock()
 	defer mp.mutex.Unlock()
-	numberInBlock := int(math.Min(float64(len(mp.Transactions)), float64(upperBound)))
-	transactionsToReturn := mp.Transactions[:numberInBlock]
-	mp.Transactions = mp.Transactions[numberInBlock:]
+	mp.removeExpired()
+	transactionsToReturn := []tx.ITransaction{}
+	for len(transactionsToReturn) < upperBound && mp.order.Len() > 0 {
+		element := mp.order.Front()
+		entry := element.Value.(*memPoolEntry)
+		transactionsToReturn = append(transactionsToReturn, entry.transaction)
+		mp.admitted[entry.hash] = entry.added
+		mp.remove(element)
+	}
 	return transactionsToReturn
 }
+
+// savedTransaction is a pending transaction as it is written by SaveToFile
+type savedTransaction struct {
+	Transaction json.RawMessage `json:"transaction"`
+	Added       time.Time       `json:"added"`
+}
+
+// SaveToFile writes pending transactions with their admission time as a JSON list in arrival order
+func (mp *MemPool) SaveToFile(path string) error {
+	mp.mutex.Lock()
+	saved := make([]savedTransaction, 0, mp.order.Len())
+	var err error
+	for element := mp.order.Front(); element != nil && err == nil; element = element.Next() {
+		entry := element.Value.(*memPoolEntry)
+		var marshalledTransaction []byte
+		marshalledTransaction, err = json.Marshal(entry.transaction)
+		saved = append(saved, savedTransaction{Transaction: marshalledTransaction, Added: entry.added})
+	}
+	mp.mutex.Unlock()
+	if err != nil {
+		return err
+	}
+
+	marshalled, err := json.Marshal(saved)
+	if err != nil {
+		return err
+	}
+
+	err = os.WriteFile(path+".tmp", marshalled, 0o600)
+	if err != nil {
+		return err
+	}
+	return os.Rename(path+".tmp", path)
+}
+
+// LoadFromFile reads transactions saved by SaveToFile, they are not added but their admission time
+// is kept, so they do not outlive TTL once they are verified and restored with RestoreMemPool
+func (mp *MemPool) LoadFromFile(path string) ([]tx.ITransaction, error) {
+	marshalled, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	saved := []savedTransaction{}
+	err = json.Unmarshal(marshalled, &saved)
+	if err != nil {
+		return nil, err
+	}
+
+	transactions := []tx.ITransaction{}
+	admitted := map[[32]byte]time.Time{}
+	for _, savedTx := range saved {
+		transaction, err := (&transaction_json.JSONTransaction{}).UnmarshallJSON(savedTx.Transaction)
+		if err != nil {
+			return nil, err
+		}
+		transactions = append(transactions, transaction)
+		admitted[transaction.GetHash()] = savedTx.Added
+	}
+
+	mp.mutex.Lock()
+	defer mp.mutex.Unlock()
+	for hash, added := range admitted {
+		mp.admitted[hash] = added
+	}
+	return transactions, nil
+}
+
+func (mp *MemPool) insert(transaction tx.ITransaction, hash [32]byte, transactionSender sender, transactionBallot *ballot, offender *keys.PublicKeyBytes, added time.Time, front bool) {
+	entry := &memPoolEntry{
+		transaction: transaction,
+		hash:        hash,
+		sender:      transactionSender,
+		ballot:      transactionBallot,
+		offender:    offender,
+		added:       added,
+	}
+	if front {
+		mp.index[hash] = mp.order.PushFront(entry)
+	} else {
+		mp.index[hash] = mp.order.PushBack(entry)
+	}
+	mp.senders[transactionSender]++
+	if transactionBallot != nil {
+		mp.ballots[*transactionBallot] = struct{}{}
+	}
+	if offender != nil {
+		mp.offenders[*offender] = struct{}{}
+	}
+
+	select {
+	case mp.added <- struct{}{}:
+	default:
+	}
+}
+
+func (mp *MemPool) remove(element *list.Element) {
+	entry := mp.order.Remove(element).(*memPoolEntry)
+	delete(mp.index, entry.hash)
+	mp.senders[entry.sender]--
+	if mp.senders[entry.sender] == 0 {
+		delete(mp.senders, entry.sender)
+	}
+	if entry.ballot != nil {
+		delete(mp.ballots, *entry.ballot)
+	}
+	if entry.offender != nil {
+		delete(mp.offenders, *entry.offender)
+	}
+}
+
+// removeExpired drops transactions older than TTL and admission times of ones that will not
+// be restored any more, at most once per expiryInterval
+func (mp *MemPool) removeExpired() {
+	now := time.Now()
+	if now.Sub(mp.lastExpiry) < expiryInterval {
+		return
+	}
+	mp.lastExpiry = now
+
+	for element := mp.order.Front(); element != nil; {
+		next := element.Next()
+		if now.Sub(element.Value.(*memPoolEntry).added) > mp.config.TTL {
+			mp.remove(element)
+		}
+		element = next
+	}
+	for hash, added := range mp.admitted {
+		if now.Sub(added) > mp.config.TTL {
+			delete(mp.admitted, hash)
+		}
+	}
+}
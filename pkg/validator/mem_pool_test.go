@@ -0,0 +1,181 @@
+package validator
+
+import (
+	tx "github.com/Digital-Voting-Team/Digital-Voting/pkg/blockchain/transaction"
+	ts "github.com/Digital-Voting-Team/Digital-Voting/pkg/blockchain/transaction/transaction_specific"
+	"github.com/Digital-Voting-Team/Digital-Voting/pkg/consensus/evidence"
+	"github.com/Digital-Voting-Team/Digital-Voting/pkg/models/account"
+	"github.com/Digital-Voting-Team/Digital-Voting/pkg/signature/keys"
+	ss "github.com/Digital-Voting-Team/Digital-Voting/pkg/signature/signatures/single_signature"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func newTestTransaction(sender keys.PublicKeyBytes, newKey byte) *tx.Transaction {
+	transaction := tx.NewTransaction(tx.AccountCreation, ts.NewTxAccCreation(account.User, keys.PublicKeyBytes{newKey}))
+	transaction.PublicKey = sender
+	return transaction
+}
+
+func TestMemPool_AddToMemPool(t *testing.T) {
+	memPool := NewMemPoolWithConfig(MemPoolConfig{MaxSize: 3, MaxPerSender: 2, TTL: time.Hour})
+	first := newTestTransaction(keys.PublicKeyBytes{1}, 1)
+	resubmitted := *first
+
+	tests := []struct {
+		name        string
+		transaction tx.ITransaction
+		want        bool
+	}{
+		{name: "New transaction", transaction: first, want: true},
+		{name: "Same transaction resubmitted", transaction: &resubmitted, want: false},
+		{name: "Second transaction of sender", transaction: newTestTransaction(keys.PublicKeyBytes{1}, 2), want: true},
+		{name: "Sender over the limit", transaction: newTestTransaction(keys.PublicKeyBytes{1}, 3), want: false},
+		{name: "Another sender", transaction: newTestTransaction(keys.PublicKeyBytes{2}, 4), want: true},
+		{name: "MemPool is full", transaction: newTestTransaction(keys.PublicKeyBytes{3}, 5), want: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := memPool.AddToMemPool(tt.transaction); got != tt.want {
+				t.Errorf("AddToMemPool() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMemPool_AddToMemPool_Ballot(t *testing.T) {
+	memPool := NewMemPool()
+	newVote := func(voter keys.PublicKeyBytes, votingLink [32]byte, answer uint8) *tx.Transaction {
+		vote := tx.NewTransaction(tx.Vote, ts.NewTxVote(votingLink, answer))
+		vote.PublicKey = voter
+		return vote
+	}
+	first := newVote(keys.PublicKeyBytes{1}, [32]byte{1}, 0)
+
+	tests := []struct {
+		name        string
+		transaction tx.ITransaction
+		want        bool
+	}{
+		{name: "Vote", transaction: first, want: true},
+		{name: "Another answer of the voter", transaction: newVote(keys.PublicKeyBytes{1}, [32]byte{1}, 1), want: false},
+		{name: "Vote of the voter in another voting", transaction: newVote(keys.PublicKeyBytes{1}, [32]byte{2}, 1), want: true},
+		{name: "Vote of another voter", transaction: newVote(keys.PublicKeyBytes{2}, [32]byte{1}, 1), want: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := memPool.AddToMemPool(tt.transaction); got != tt.want {
+				t.Errorf("AddToMemPool() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	memPool.RemoveTransactions([]tx.ITransaction{first})
+	if !memPool.AddToMemPool(newVote(keys.PublicKeyBytes{1}, [32]byte{1}, 1)) {
+		t.Errorf("AddToMemPool() = false after the pending vote was removed")
+	}
+}
+
+func TestMemPool_AddToMemPool_Evidence(t *testing.T) {
+	memPool := NewMemPool()
+	newEvidence := func(offender keys.PublicKeyBytes, reporter keys.PublicKeyBytes) *tx.Transaction {
+		vote := func(blockHash [32]byte) evidence.Vote {
+			return evidence.Vote{VoteType: evidence.Prevote, Height: 1, BlockHash: blockHash, Validator: offender}
+		}
+		report := tx.NewTransaction(tx.Evidence, ts.NewTxEvidence(*evidence.NewVoteEvidence(vote([32]byte{1}), vote([32]byte{2})), reporter))
+		report.PublicKey = reporter
+		return report
+	}
+
+	tests := []struct {
+		name        string
+		transaction tx.ITransaction
+		want        bool
+	}{
+		{name: "Evidence", transaction: newEvidence(keys.PublicKeyBytes{1}, keys.PublicKeyBytes{2}), want: true},
+		{name: "Evidence against the offender by another reporter", transaction: newEvidence(keys.PublicKeyBytes{1}, keys.PublicKeyBytes{3}), want: false},
+		{name: "Evidence against another offender", transaction: newEvidence(keys.PublicKeyBytes{4}, keys.PublicKeyBytes{2}), want: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := memPool.AddToMemPool(tt.transaction); got != tt.want {
+				t.Errorf("AddToMemPool() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMemPool_RemoveTransactions(t *testing.T) {
+	memPool := NewMemPool()
+	included := newTestTransaction(keys.PublicKeyBytes{1}, 1)
+	pending := newTestTransaction(keys.PublicKeyBytes{1}, 2)
+	memPool.AddToMemPool(included)
+	memPool.AddToMemPool(pending)
+
+	copied := *included
+	memPool.RemoveTransactions([]tx.ITransaction{&copied})
+
+	if memPool.IsInMemPool(included) {
+		t.Errorf("transaction included in block is still in MemPool")
+	}
+	if !memPool.IsInMemPool(pending) {
+		t.Errorf("pending transaction was removed")
+	}
+}
+
+func TestMemPool_Expiry(t *testing.T) {
+	memPool := NewMemPoolWithConfig(MemPoolConfig{MaxSize: 10, MaxPerSender: 10, TTL: time.Millisecond})
+	memPool.AddToMemPool(newTestTransaction(keys.PublicKeyBytes{1}, 1))
+	memPool.lastExpiry = time.Time{}
+	time.Sleep(5 * time.Millisecond)
+
+	if got := memPool.GetTransactionsCount(); got != 0 {
+		t.Errorf("GetTransactionsCount() = %d, want 0", got)
+	}
+}
+
+func TestMemPool_RestoreMemPool_AdmissionTime(t *testing.T) {
+	memPool := NewMemPool()
+	memPool.AddToMemPool(newTestTransaction(keys.PublicKeyBytes{1}, 1))
+	admitted := time.Now().Add(-time.Minute)
+	memPool.order.Front().Value.(*memPoolEntry).added = admitted
+
+	memPool.RestoreMemPool(memPool.GetWithUpperBound(1))
+
+	if got := memPool.order.Front().Value.(*memPoolEntry).added; !got.Equal(admitted) {
+		t.Errorf("restored transaction added at %v, want %v", got, admitted)
+	}
+}
+
+func TestMemPool_SaveToFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), MemPoolFile)
+	memPool := NewMemPool()
+	pending := newTestTransaction(keys.PublicKeyBytes{1}, 1)
+	expired := newTestTransaction(keys.PublicKeyBytes{1}, 2)
+	// Transactions are read back only if they are signed
+	pending.Sign(keys.PublicKeyBytes{1}, ss.SingleSignatureBytes{1})
+	expired.Sign(keys.PublicKeyBytes{1}, ss.SingleSignatureBytes{1})
+	memPool.AddToMemPool(pending)
+	memPool.AddToMemPool(expired)
+	admitted := time.Now().Add(-time.Minute)
+	memPool.order.Front().Value.(*memPoolEntry).added = admitted
+	memPool.order.Back().Value.(*memPoolEntry).added = time.Now().Add(-2 * time.Hour)
+	if err := memPool.SaveToFile(path); err != nil {
+		t.Fatal(err)
+	}
+
+	loaded := NewMemPool()
+	transactions, err := loaded.LoadFromFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	loaded.RestoreMemPool(transactions)
+
+	if got := loaded.GetTransactionsCount(); got != 1 || !loaded.IsInMemPool(pending) {
+		t.Fatalf("GetTransactionsCount() = %d after restart, want only the transaction within TTL", got)
+	}
+	if got := loaded.order.Front().Value.(*memPoolEntry).added; !got.Equal(admitted) {
+		t.Errorf("transaction loaded from file added at %v, want %v", got, admitted)
+	}
+}
@@ -0,0 +1,335 @@
+package query
+
+import (
+	"bytes"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	blk "github.com/Digital-Voting-Team/Digital-Voting/pkg/blockchain/block"
+	tx "github.com/Digital-Voting-Team/Digital-Voting/pkg/blockchain/transaction"
+	ts "github.com/Digital-Voting-Team/Digital-Voting/pkg/blockchain/transaction/transaction_specific"
+	"github.com/Digital-Voting-Team/Digital-Voting/pkg/validator/repository/indexed_groups"
+	"github.com/Digital-Voting-Team/Digital-Voting/pkg/validator/repository/indexed_slashings"
+	"github.com/Digital-Voting-Team/Digital-Voting/pkg/validator/repository/indexed_votings"
+	"time"
+)
+
+// Keys, hashes and key images are encoded as lowercase hex everywhere in the APIs,
+// both in responses and in requests
+
+func EncodeHex(data []byte) string {
+	return hex.EncodeToString(data)
+}
+
+func encodeKeys(keys [][33]byte) []string {
+	encoded := make([]string, len(keys))
+	for i, key := range keys {
+		encoded[i] = EncodeHex(key[:])
+	}
+	return encoded
+}
+
+// DecodeHash decodes hex hash of a block, a transaction or a voting
+func DecodeHash(encoded string) ([32]byte, error) {
+	hash := [32]byte{}
+	return hash, decodeFixed(encoded, hash[:])
+}
+
+// DecodeKey decodes hex public key or group identifier
+func DecodeKey(encoded string) ([33]byte, error) {
+	key := [33]byte{}
+	return key, decodeFixed(encoded, key[:])
+}
+
+func decodeFixed(encoded string, destination []byte) error {
+	decoded, err := hex.DecodeString(encoded)
+	if err != nil {
+		return err
+	}
+	if len(decoded) != len(destination) {
+		return fmt.Errorf("expected %d bytes, got %d", len(destination), len(decoded))
+	}
+	copy(destination, decoded)
+	return nil
+}
+
+// decodeText returns text of a fixed size field without the zero padding
+func decodeText(data []byte) string {
+	return string(bytes.TrimRight(data, "\x00"))
+}
+
+// BlockDTO carries every field the hash of a block other than genesis is computed from,
+// so clients recompute the hash instead of trusting it
+type BlockDTO struct {
+	Height       uint64     `json:"height"`
+	Hash         string     `json:"hash"`
+	Version      uint32     `json:"version"`
+	Previous     string     `json:"previous"`
+	TimeStamp    uint64     `json:"time_stamp"`
+	MerkleRoot   string     `json:"merkle_root"`
+	Witness      WitnessDTO `json:"witness"`
+	Transactions []string   `json:"transactions"`
+}
+
+// WitnessDTO is the aggregate signature of validators over the block hash and the bitmap of who signed it
+type WitnessDTO struct {
+	Signers   string `json:"signers"`
+	Signature string `json:"signature"`
+}
+
+// NewBlockDTO describes the block at height, transactions are listed by hash
+func NewBlockDTO(height uint64, block *blk.Block) BlockDTO {
+	hash := block.GetHash()
+	transactions := make([]string, len(block.Body.Transactions))
+	for i, transaction := range block.Body.Transactions {
+		transactionHash := transaction.GetHash()
+		transactions[i] = EncodeHex(transactionHash[:])
+	}
+
+	return BlockDTO{
+		Height:     height,
+		Hash:       EncodeHex(hash[:]),
+		Version:    block.Header.Version,
+		Previous:   EncodeHex(block.Header.Previous[:]),
+		TimeStamp:  block.Header.TimeStamp,
+		MerkleRoot: EncodeHex(block.Header.MerkleRoot[:]),
+		Witness: WitnessDTO{
+			Signers:   EncodeHex(block.Witness.Signers),
+			Signature: EncodeHex(block.Witness.Signature[:]),
+		},
+		Transactions: transactions,
+	}
+}
+
+// DecodeBlock rebuilds the header and the witness of a block described by dto, its body is left empty
+// since the dto lists transactions by hash only
+func DecodeBlock(dto BlockDTO) (*blk.Block, error) {
+	block := &blk.Block{Header: blk.Header{Version: dto.Version, TimeStamp: dto.TimeStamp}}
+	var err error
+	block.Header.Previous, err = DecodeHash(dto.Previous)
+	if err != nil {
+		return nil, fmt.Errorf("previous block: %w", err)
+	}
+	block.Header.MerkleRoot, err = DecodeHash(dto.MerkleRoot)
+	if err != nil {
+		return nil, fmt.Errorf("merkle root: %w", err)
+	}
+	block.Witness.Signers, err = hex.DecodeString(dto.Witness.Signers)
+	if err != nil {
+		return nil, fmt.Errorf("witness signers: %w", err)
+	}
+	err = decodeFixed(dto.Witness.Signature, block.Witness.Signature[:])
+	if err != nil {
+		return nil, fmt.Errorf("witness signature: %w", err)
+	}
+	return block, nil
+}
+
+// TransactionDTO describes a transaction included in a block, Body depends on Type
+type TransactionDTO struct {
+	Hash        string      `json:"hash"`
+	Type        string      `json:"type"`
+	BlockHeight uint64      `json:"block_height"`
+	Sender      string      `json:"sender,omitempty"`
+	Body        interface{} `json:"body"`
+}
+
+var transactionTypeNames = map[tx.TxType]string{
+	tx.AccountCreation: "account_creation",
+	tx.GroupCreation:   "group_creation",
+	tx.VotingCreation:  "voting_creation",
+	tx.Vote:            "vote",
+	tx.VoteAnonymous:   "vote_anonymous",
+	tx.Evidence:        "evidence",
+}
+
+// NewTransactionDTO describes the transaction included in the block at height
+func NewTransactionDTO(height uint64, transaction tx.ITransaction) TransactionDTO {
+	hash := transaction.GetHash()
+	dto := TransactionDTO{
+		Hash:        EncodeHex(hash[:]),
+		Type:        transactionTypeNames[transaction.GetTxType()],
+		BlockHeight: height,
+	}
+
+	switch exact := transaction.(type) {
+	case *tx.Transaction:
+		dto.Sender = EncodeHex(exact.PublicKey[:])
+		dto.Body = newBodyDTO(exact.TxBody)
+	case *ts.TxVoteAnonymous:
+		ring := make([][33]byte, len(exact.PublicKeys))
+		for i, publicKey := range exact.PublicKeys {
+			ring[i] = publicKey
+		}
+		dto.Body = struct {
+			Voting   string   `json:"voting"`
+			Answer   uint8    `json:"answer"`
+			KeyImage string   `json:"key_image"`
+			Ring     []string `json:"ring"`
+		}{EncodeHex(exact.VotingLink[:]), exact.Answer, EncodeHex(exact.KeyImage[:]), encodeKeys(ring)}
+	}
+
+	return dto
+}
+
+func newBodyDTO(body tx.TxBody) interface{} {
+	switch exact := body.(type) {
+	case *ts.TxAccountCreation:
+		return struct {
+			AccountType  uint8  `json:"account_type"`
+			NewPublicKey string `json:"new_public_key"`
+		}{uint8(exact.AccountType), EncodeHex(exact.NewPublicKey[:])}
+	case *ts.TxGroupCreation:
+		return newGroupDTO(indexed_groups.GroupDTO{
+			GroupIdentifier:   exact.GroupIdentifier,
+			GroupName:         exact.GroupName,
+			MembersPublicKeys: exact.MembersPublicKeys,
+		})
+	case *ts.TxVotingCreation:
+		answers := make([]string, len(exact.Answers))
+		for i, answer := range exact.Answers {
+			answers[i] = decodeText(answer[:])
+		}
+		return struct {
+			ExpirationDate uint32   `json:"expiration_date"`
+			Description    string   `json:"description"`
+			Answers        []string `json:"answers"`
+			Whitelist      []string `json:"whitelist"`
+		}{exact.ExpirationDate, decodeText(exact.VotingDescription[:]), answers, encodeKeys(exact.Whitelist)}
+	case *ts.TxVote:
+		return struct {
+			Voting string `json:"voting"`
+			Answer uint8  `json:"answer"`
+		}{EncodeHex(exact.VotingLink[:]), exact.Answer}
+	case *ts.TxEvidence:
+		return newSlashingDTO(exact.Slashing())
+	default:
+		return nil
+	}
+}
+
+// Statuses of a voting, votes are accepted until its expiration date
+const (
+	StatusActive   = "active"
+	StatusFinished = "finished"
+)
+
+func votingStatus(voting indexed_votings.VotingDTO, now time.Time) string {
+	if uint32(now.Unix()) > voting.ExpirationDate {
+		return StatusFinished
+	}
+	return StatusActive
+}
+
+type VotingDTO struct {
+	Hash           string   `json:"hash"`
+	Description    string   `json:"description"`
+	ExpirationDate uint32   `json:"expiration_date"`
+	Status         string   `json:"status"`
+	Answers        []string `json:"answers"`
+	Whitelist      []string `json:"whitelist,omitempty"`
+	Results        []uint64 `json:"results,omitempty"`
+}
+
+func newVotingDTO(voting indexed_votings.VotingDTO, now time.Time) VotingDTO {
+	answers := make([]string, len(voting.Answers))
+	for i, answer := range voting.Answers {
+		answers[i] = decodeText(answer[:])
+	}
+
+	return VotingDTO{
+		Hash:           EncodeHex(voting.Hash[:]),
+		Description:    decodeText(voting.VotingDescription[:]),
+		ExpirationDate: voting.ExpirationDate,
+		Status:         votingStatus(voting, now),
+		Answers:        answers,
+	}
+}
+
+type GroupDTO struct {
+	Identifier string   `json:"identifier"`
+	Name       string   `json:"name"`
+	Members    []string `json:"members"`
+}
+
+func newGroupDTO(group indexed_groups.GroupDTO) GroupDTO {
+	members := make([][33]byte, len(group.MembersPublicKeys))
+	for i, member := range group.MembersPublicKeys {
+		members[i] = member
+	}
+
+	return GroupDTO{
+		Identifier: EncodeHex(group.GroupIdentifier[:]),
+		Name:       decodeText(group.GroupName[:]),
+		Members:    encodeKeys(members),
+	}
+}
+
+type ResultsDTO struct {
+	Hash    string   `json:"hash"`
+	Results []uint64 `json:"results"`
+}
+
+// ProofDTO proves a transaction is included in the block with MerkleRoot, see merkle_tree.VerifyProof
+type ProofDTO struct {
+	Transaction string         `json:"transaction"`
+	BlockHeight uint64         `json:"block_height"`
+	BlockHash   string         `json:"block_hash"`
+	MerkleRoot  string         `json:"merkle_root"`
+	Path        []ProofStepDTO `json:"path"`
+}
+
+type ProofStepDTO struct {
+	Hash  string `json:"hash"`
+	Right bool   `json:"right"`
+}
+
+// PreparedTransactionDTO is a transaction ready to be signed by the client: it signs SignatureMessage
+// and submits Transaction with the signature, anonymous votes are signed with a ring taken from Ring
+type PreparedTransactionDTO struct {
+	Transaction      json.RawMessage `json:"transaction"`
+	SignatureMessage string          `json:"signature_message"`
+	Ring             []string        `json:"ring,omitempty"`
+}
+
+type AccountDTO struct {
+	PublicKey string       `json:"public_key"`
+	Roles     []string     `json:"roles"`
+	Slashing  *SlashingDTO `json:"slashing,omitempty"`
+}
+
+// SlashingDTO tells which blocks the offender signed in the same round, Evidence is the hash of the evidence
+type SlashingDTO struct {
+	Offender    string   `json:"offender"`
+	Reporter    string   `json:"reporter"`
+	Kind        string   `json:"kind"`
+	Height      uint64   `json:"height"`
+	Round       uint32   `json:"round"`
+	BlockHashes []string `json:"block_hashes"`
+	Evidence    string   `json:"evidence"`
+}
+
+func newSlashingDTO(slashing indexed_slashings.SlashingDTO) SlashingDTO {
+	return SlashingDTO{
+		Offender:    EncodeHex(slashing.Offender[:]),
+		Reporter:    EncodeHex(slashing.Reporter[:]),
+		Kind:        slashing.Kind,
+		Height:      slashing.Height,
+		Round:       slashing.Round,
+		BlockHashes: []string{EncodeHex(slashing.BlockHashes[0][:]), EncodeHex(slashing.BlockHashes[1][:])},
+		Evidence:    EncodeHex(slashing.EvidenceHash[:]),
+	}
+}
+
+type MemPoolDTO struct {
+	Pending  int `json:"pending"`
+	Capacity int `json:"capacity"`
+}
+
+// Page is a part of a list starting at Offset, Total is the length of the whole list
+type Page struct {
+	Items  interface{} `json:"items"`
+	Total  int         `json:"total"`
+	Offset int         `json:"offset"`
+	Limit  int         `json:"limit"`
+}
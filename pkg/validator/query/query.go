@@ -0,0 +1,328 @@
+package query
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/Digital-Voting-Team/Digital-Voting/pkg/blockchain"
+	"github.com/Digital-Voting-Team/Digital-Voting/pkg/blockchain/block/merkle_tree"
+	"github.com/Digital-Voting-Team/Digital-Voting/pkg/blockchain/transaction/transaction_json"
+	ts "github.com/Digital-Voting-Team/Digital-Voting/pkg/blockchain/transaction/transaction_specific"
+	"github.com/Digital-Voting-Team/Digital-Voting/pkg/validator"
+	"github.com/Digital-Voting-Team/Digital-Voting/pkg/validator/repository"
+	"github.com/Digital-Voting-Team/Digital-Voting/pkg/validator/repository/indexed_votings"
+	"sort"
+	"time"
+)
+
+// DefaultLimit and MaxLimit bound the number of items in a page of a list
+const (
+	DefaultLimit = 20
+	MaxLimit     = 100
+)
+
+var (
+	ErrNotFound        = errors.New("not found")
+	ErrInvalidArgument = errors.New("invalid argument")
+)
+
+// Service answers read only queries over the chain, indexed data and MemPool,
+// APIs of the validator translate their requests into its methods
+type Service struct {
+	Blockchain  *blockchain.Blockchain
+	IndexedData *repository.IndexedData
+	MemPool     *validator.MemPool
+}
+
+func NewService(bc *blockchain.Blockchain, indexedData *repository.IndexedData, memPool *validator.MemPool) *Service {
+	return &Service{
+		Blockchain:  bc,
+		IndexedData: indexedData,
+		MemPool:     memPool,
+	}
+}
+
+// CheckPage validates offset and limit of a page, zero limit selects DefaultLimit
+func CheckPage(offset, limit int) (int, error) {
+	if offset < 0 {
+		return 0, fmt.Errorf("offset must be non-negative: %w", ErrInvalidArgument)
+	}
+	if limit == 0 {
+		return DefaultLimit, nil
+	}
+	if limit < 0 || limit > MaxLimit {
+		return 0, fmt.Errorf("limit must be from 1 to %d: %w", MaxLimit, ErrInvalidArgument)
+	}
+	return limit, nil
+}
+
+// pageBounds returns bounds of the page within a list of total items
+func pageBounds(total, offset, limit int) (int, int) {
+	if offset > total {
+		offset = total
+	}
+	end := offset + limit
+	if end > total {
+		end = total
+	}
+	return offset, end
+}
+
+// Blocks lists blocks from genesis on
+func (s *Service) Blocks(offset, limit int) (Page, error) {
+	limit, err := CheckPage(offset, limit)
+	if err != nil {
+		return Page{}, err
+	}
+
+	blocks := s.Blockchain.GetBlocks()
+	start, end := pageBounds(len(blocks), offset, limit)
+	items := make([]BlockDTO, 0, end-start)
+	for height := start; height < end; height++ {
+		items = append(items, NewBlockDTO(uint64(height), blocks[height]))
+	}
+
+	return Page{Items: items, Total: len(blocks), Offset: offset, Limit: limit}, nil
+}
+
+func (s *Service) BlockByHeight(height uint64) (BlockDTO, error) {
+	block, err := s.Blockchain.GetBlockByHeight(height)
+	if err != nil {
+		return BlockDTO{}, fmt.Errorf("block at height %d: %w", height, ErrNotFound)
+	}
+	return NewBlockDTO(height, block), nil
+}
+
+func (s *Service) BlockByHash(encodedHash string) (BlockDTO, error) {
+	hash, err := DecodeHash(encodedHash)
+	if err != nil {
+		return BlockDTO{}, fmt.Errorf("block hash: %v: %w", err, ErrInvalidArgument)
+	}
+
+	for height, block := range s.Blockchain.GetBlocks() {
+		if block.GetHash() == hash {
+			return NewBlockDTO(uint64(height), block), nil
+		}
+	}
+	return BlockDTO{}, fmt.Errorf("block %s: %w", encodedHash, ErrNotFound)
+}
+
+// Transaction returns a transaction included in the chain
+func (s *Service) Transaction(encodedHash string) (TransactionDTO, error) {
+	hash, err := DecodeHash(encodedHash)
+	if err != nil {
+		return TransactionDTO{}, fmt.Errorf("transaction hash: %v: %w", err, ErrInvalidArgument)
+	}
+
+	transaction, height, err := s.Blockchain.FindTransaction(hash)
+	if err != nil {
+		return TransactionDTO{}, fmt.Errorf("transaction %s: %w", encodedHash, ErrNotFound)
+	}
+	return NewTransactionDTO(height, transaction), nil
+}
+
+// Proof returns merkle path of a transaction included in the chain
+func (s *Service) Proof(encodedHash string) (ProofDTO, error) {
+	hash, err := DecodeHash(encodedHash)
+	if err != nil {
+		return ProofDTO{}, fmt.Errorf("transaction hash: %v: %w", err, ErrInvalidArgument)
+	}
+
+	_, height, err := s.Blockchain.FindTransaction(hash)
+	if err != nil {
+		return ProofDTO{}, fmt.Errorf("transaction %s: %w", encodedHash, ErrNotFound)
+	}
+	block, err := s.Blockchain.GetBlockByHeight(height)
+	if err != nil {
+		return ProofDTO{}, fmt.Errorf("block at height %d: %w", height, ErrNotFound)
+	}
+	steps, _ := merkle_tree.GetProof(hash, block.Body.Transactions)
+
+	blockHash := block.GetHash()
+	proof := ProofDTO{
+		Transaction: EncodeHex(hash[:]),
+		BlockHeight: height,
+		BlockHash:   EncodeHex(blockHash[:]),
+		MerkleRoot:  EncodeHex(block.Header.MerkleRoot[:]),
+		Path:        make([]ProofStepDTO, len(steps)),
+	}
+	for i, step := range steps {
+		proof.Path[i] = ProofStepDTO{Hash: EncodeHex(step.Hash[:]), Right: step.Right}
+	}
+	return proof, nil
+}
+
+// signatureMessenger is implemented by all transactions, the message is what the client signs
+type signatureMessenger interface {
+	GetSignatureMessage() string
+}
+
+// Prepare returns the signing payload of an unsigned transaction, zero nonce is replaced with a random one,
+// malformed transactions and ones carrying a private key are rejected
+func (s *Service) Prepare(marshalledTransaction []byte) (PreparedTransactionDTO, error) {
+	transaction, err := (&transaction_json.JSONTransaction{}).UnmarshallUnsignedJSON(marshalledTransaction)
+	if err != nil {
+		return PreparedTransactionDTO{}, err
+	}
+
+	prepared := PreparedTransactionDTO{}
+	if vote, ok := transaction.(*ts.TxVoteAnonymous); ok {
+		s.IndexedData.Mutex.Lock()
+		exists := s.IndexedData.VotingManager.GetVoting(vote.VotingLink).Hash != [32]byte{}
+		ring := ts.RingMembers(s.IndexedData, vote.VotingLink)
+		s.IndexedData.Mutex.Unlock()
+		if !exists {
+			return PreparedTransactionDTO{}, fmt.Errorf("voting %x: %w", vote.VotingLink, ErrNotFound)
+		}
+
+		prepared.Ring = make([]string, len(ring))
+		for i, member := range ring {
+			prepared.Ring[i] = EncodeHex(member[:])
+		}
+	}
+
+	prepared.Transaction, err = json.Marshal(transaction)
+	if err != nil {
+		return PreparedTransactionDTO{}, err
+	}
+	prepared.SignatureMessage = transaction.(signatureMessenger).GetSignatureMessage()
+	return prepared, nil
+}
+
+// Votings lists votings ordered by expiration date, empty status selects votings of all statuses
+func (s *Service) Votings(status string, offset, limit int) (Page, error) {
+	limit, err := CheckPage(offset, limit)
+	if err != nil {
+		return Page{}, err
+	}
+	if status != "" && status != StatusActive && status != StatusFinished {
+		return Page{}, fmt.Errorf("status must be %s or %s: %w", StatusActive, StatusFinished, ErrInvalidArgument)
+	}
+
+	now := time.Now()
+	votings := []indexed_votings.VotingDTO{}
+	s.IndexedData.Mutex.Lock()
+	for _, voting := range s.IndexedData.VotingManager.IndexedVotings {
+		if status == "" || votingStatus(voting, now) == status {
+			votings = append(votings, voting)
+		}
+	}
+	s.IndexedData.Mutex.Unlock()
+
+	sort.Slice(votings, func(i, j int) bool {
+		if votings[i].ExpirationDate != votings[j].ExpirationDate {
+			return votings[i].ExpirationDate < votings[j].ExpirationDate
+		}
+		return bytes.Compare(votings[i].Hash[:], votings[j].Hash[:]) < 0
+	})
+
+	start, end := pageBounds(len(votings), offset, limit)
+	items := make([]VotingDTO, 0, end-start)
+	for _, voting := range votings[start:end] {
+		items = append(items, newVotingDTO(voting, now))
+	}
+
+	return Page{Items: items, Total: len(votings), Offset: offset, Limit: limit}, nil
+}
+
+// Voting returns a voting with its whitelist and results
+func (s *Service) Voting(encodedHash string) (VotingDTO, error) {
+	hash, err := DecodeHash(encodedHash)
+	if err != nil {
+		return VotingDTO{}, fmt.Errorf("voting hash: %v: %w", err, ErrInvalidArgument)
+	}
+
+	s.IndexedData.Mutex.Lock()
+	voting, exists := s.IndexedData.VotingManager.IndexedVotings[hash]
+	results := s.IndexedData.VotingManager.GetResults(hash)
+	s.IndexedData.Mutex.Unlock()
+	if !exists {
+		return VotingDTO{}, fmt.Errorf("voting %s: %w", encodedHash, ErrNotFound)
+	}
+
+	dto := newVotingDTO(voting, time.Now())
+	dto.Whitelist = encodeKeys(voting.Whitelist)
+	dto.Results = results
+	return dto, nil
+}
+
+// Results counts votes for every answer of a voting
+func (s *Service) Results(encodedHash string) (ResultsDTO, error) {
+	voting, err := s.Voting(encodedHash)
+	if err != nil {
+		return ResultsDTO{}, err
+	}
+	return ResultsDTO{Hash: voting.Hash, Results: voting.Results}, nil
+}
+
+// Groups lists groups ordered by identifier
+func (s *Service) Groups(offset, limit int) (Page, error) {
+	limit, err := CheckPage(offset, limit)
+	if err != nil {
+		return Page{}, err
+	}
+
+	s.IndexedData.Mutex.Lock()
+	groups := make([]GroupDTO, 0, len(s.IndexedData.GroupManager.IndexedGroups))
+	for _, group := range s.IndexedData.GroupManager.IndexedGroups {
+		groups = append(groups, newGroupDTO(group))
+	}
+	s.IndexedData.Mutex.Unlock()
+
+	sort.Slice(groups, func(i, j int) bool {
+		return groups[i].Identifier < groups[j].Identifier
+	})
+
+	start, end := pageBounds(len(groups), offset, limit)
+	return Page{Items: groups[start:end], Total: len(groups), Offset: offset, Limit: limit}, nil
+}
+
+// Group returns a group with its members
+func (s *Service) Group(encodedIdentifier string) (GroupDTO, error) {
+	identifier, err := DecodeKey(encodedIdentifier)
+	if err != nil {
+		return GroupDTO{}, fmt.Errorf("group identifier: %v: %w", err, ErrInvalidArgument)
+	}
+
+	s.IndexedData.Mutex.Lock()
+	group, exists := s.IndexedData.GroupManager.IndexedGroups[identifier]
+	s.IndexedData.Mutex.Unlock()
+	if !exists {
+		return GroupDTO{}, fmt.Errorf("group %s: %w", encodedIdentifier, ErrNotFound)
+	}
+	return newGroupDTO(group), nil
+}
+
+// Account returns roles of a public key, a key without roles has an empty list.
+// Validators slashed for equivocation have the record of the evidence
+func (s *Service) Account(encodedPublicKey string) (AccountDTO, error) {
+	publicKey, err := DecodeKey(encodedPublicKey)
+	if err != nil {
+		return AccountDTO{}, fmt.Errorf("public key: %v: %w", err, ErrInvalidArgument)
+	}
+
+	s.IndexedData.Mutex.Lock()
+	roles := s.IndexedData.AccountManager.GetRoles(publicKey)
+	slashing, slashed := s.IndexedData.SlashingManager.GetSlashing(publicKey)
+	s.IndexedData.Mutex.Unlock()
+
+	names := make([]string, len(roles))
+	for i, role := range roles {
+		names[i] = role.String()
+	}
+	account := AccountDTO{PublicKey: EncodeHex(publicKey[:]), Roles: names}
+	if slashed {
+		dto := newSlashingDTO(slashing)
+		account.Slashing = &dto
+	}
+	return account, nil
+}
+
+// MemPoolStatus returns the number of pending transactions
+func (s *Service) MemPoolStatus() MemPoolDTO {
+	return MemPoolDTO{
+		Pending:  s.MemPool.GetTransactionsCount(),
+		Capacity: s.MemPool.Config().MaxSize,
+	}
+}
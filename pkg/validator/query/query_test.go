@@ -0,0 +1,133 @@
+package query
+
+import (
+	"errors"
+	"github.com/Digital-Voting-Team/Digital-Voting/pkg/validator/repository"
+	"github.com/Digital-Voting-Team/Digital-Voting/pkg/validator/repository/indexed_votings"
+	"testing"
+	"time"
+)
+
+func TestCheckPage(t *testing.T) {
+	tests := []struct {
+		name    string
+		offset  int
+		limit   int
+		want    int
+		wantErr error
+	}{
+		{name: "Zero limit selects default", offset: 0, limit: 0, want: DefaultLimit},
+		{name: "Limit within bounds", offset: 10, limit: 5, want: 5},
+		{name: "Largest limit", offset: 0, limit: MaxLimit, want: MaxLimit},
+		{name: "Limit above maximum", offset: 0, limit: MaxLimit + 1, wantErr: ErrInvalidArgument},
+		{name: "Negative limit", offset: 0, limit: -1, wantErr: ErrInvalidArgument},
+		{name: "Negative offset", offset: -1, limit: 5, wantErr: ErrInvalidArgument},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := CheckPage(tt.offset, tt.limit)
+			if !errors.Is(err, tt.wantErr) || got != tt.want {
+				t.Errorf("CheckPage() = %v, %v, want %v, %v", got, err, tt.want, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestPageBounds(t *testing.T) {
+	tests := []struct {
+		name      string
+		total     int
+		offset    int
+		limit     int
+		wantStart int
+		wantEnd   int
+	}{
+		{name: "Whole page", total: 10, offset: 2, limit: 5, wantStart: 2, wantEnd: 7},
+		{name: "Last page is shorter", total: 10, offset: 8, limit: 5, wantStart: 8, wantEnd: 10},
+		{name: "Offset past the end", total: 10, offset: 15, limit: 5, wantStart: 10, wantEnd: 10},
+		{name: "Empty list", total: 0, offset: 0, limit: 5, wantStart: 0, wantEnd: 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			start, end := pageBounds(tt.total, tt.offset, tt.limit)
+			if start != tt.wantStart || end != tt.wantEnd {
+				t.Errorf("pageBounds() = %v, %v, want %v, %v", start, end, tt.wantStart, tt.wantEnd)
+			}
+		})
+	}
+}
+
+func TestService_Votings(t *testing.T) {
+	indexedData := repository.NewIndexedData()
+	now := uint32(time.Now().Unix())
+	for i, expirationDate := range []uint32{now - 100, now + 100, now + 200} {
+		indexedData.VotingManager.AddNewVoting(indexed_votings.VotingDTO{Hash: [32]byte{byte(i + 1)}, ExpirationDate: expirationDate})
+	}
+	service := NewService(nil, indexedData, nil)
+	hash := func(first byte) string {
+		hash := [32]byte{first}
+		return EncodeHex(hash[:])
+	}
+
+	tests := []struct {
+		name       string
+		status     string
+		offset     int
+		limit      int
+		wantHashes []string
+		wantTotal  int
+		wantErr    error
+	}{
+		{
+			name:       "All votings ordered by expiration date",
+			status:     "",
+			wantHashes: []string{hash(1), hash(2), hash(3)},
+			wantTotal:  3,
+		},
+		{
+			name:       "Active votings",
+			status:     StatusActive,
+			wantHashes: []string{hash(2), hash(3)},
+			wantTotal:  2,
+		},
+		{
+			name:       "Finished votings",
+			status:     StatusFinished,
+			wantHashes: []string{hash(1)},
+			wantTotal:  1,
+		},
+		{
+			name:       "Page of active votings",
+			status:     StatusActive,
+			offset:     1,
+			limit:      1,
+			wantHashes: []string{hash(3)},
+			wantTotal:  2,
+		},
+		{
+			name:    "Unknown status",
+			status:  "pending",
+			wantErr: ErrInvalidArgument,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			page, err := service.Votings(tt.status, tt.offset, tt.limit)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Votings() error = %v, want %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			items := page.Items.([]VotingDTO)
+			if page.Total != tt.wantTotal || len(items) != len(tt.wantHashes) {
+				t.Fatalf("Votings() = %d of %d votings, want %d of %d", len(items), page.Total, len(tt.wantHashes), tt.wantTotal)
+			}
+			for i, item := range items {
+				if item.Hash != tt.wantHashes[i] {
+					t.Errorf("Votings() item %d has hash %s, want %s", i, item.Hash, tt.wantHashes[i])
+				}
+			}
+		})
+	}
+}
@@ -32,6 +32,32 @@ const (
 	Validator
 )
 
+var identifierNames = map[Identifier]string{
+	User:                "user",
+	RegistrationAdmin:   "registration_admin",
+	VotingCreationAdmin: "voting_creation_admin",
+	GroupIdentifier:     "group_identifier",
+	Validator:           "validator",
+}
+
+func (i Identifier) String() string {
+	name, exists := identifierNames[i]
+	if !exists {
+		return "unknown"
+	}
+	return name
+}
+
+// ParseIdentifier returns the identifier with name, the one String returns
+func ParseIdentifier(name string) (Identifier, bool) {
+	for identifier, identifierName := range identifierNames {
+		if identifierName == name {
+			return identifier, true
+		}
+	}
+	return 0, false
+}
+
 func (ip *AccountManager) AddPubKey(publicKey keys.PublicKeyBytes, keyType Identifier) {
 	switch keyType {
 	case User:
@@ -98,3 +124,14 @@ func (ip *AccountManager) RemovePubKey(publicKey keys.PublicKeyBytes, keyType Id
 		delete(ip.ValidatorPubKeys, publicKey)
 	}
 }
+
+// GetRoles returns all roles the public key has
+func (ip *AccountManager) GetRoles(publicKey keys.PublicKeyBytes) []Identifier {
+	roles := []Identifier{}
+	for _, role := range []Identifier{User, RegistrationAdmin, VotingCreationAdmin, GroupIdentifier, Validator} {
+		if ip.CheckPubKeyPresence(publicKey, role) {
+			roles = append(roles, role)
+		}
+	}
+	return roles
+}
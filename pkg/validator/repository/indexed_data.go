@@ -3,22 +3,32 @@ package repository
 import (
 	"github.com/Digital-Voting-Team/Digital-Voting/pkg/validator/repository/account_manager"
 	"github.com/Digital-Voting-Team/Digital-Voting/pkg/validator/repository/indexed_groups"
+	"github.com/Digital-Voting-Team/Digital-Voting/pkg/validator/repository/indexed_slashings"
+	"github.com/Digital-Voting-Team/Digital-Voting/pkg/validator/repository/indexed_validators"
 	"github.com/Digital-Voting-Team/Digital-Voting/pkg/validator/repository/indexed_votings"
 	"sync"
 )
 
 // IndexedData TODO: move functions with IndexedData here instead of elsewhere
 type IndexedData struct {
-	AccountManager *account_manager.AccountManager
-	GroupManager   *indexed_groups.GroupManager
-	VotingManager  *indexed_votings.VotingManager
-	Mutex          sync.Mutex
+	AccountManager  *account_manager.AccountManager
+	GroupManager    *indexed_groups.GroupManager
+	VotingManager   *indexed_votings.VotingManager
+	SlashingManager *indexed_slashings.SlashingManager
+	// ValidatorHistory keeps validator sets of AccountManager by the height they came in force at
+	ValidatorHistory *indexed_validators.ValidatorHistory
+	// Height is the number of blocks indexed data was actualized with, the height of the next block
+	Height uint64
+	Mutex  sync.Mutex
 }
 
 func NewIndexedData() *IndexedData {
 	return &IndexedData{
-		AccountManager: account_manager.NewAccountManager(),
-		GroupManager:   indexed_groups.NewGroupManager(),
-		VotingManager:  indexed_votings.NewVotingManager(),
+		AccountManager:  account_manager.NewAccountManager(),
+		GroupManager:    indexed_groups.NewGroupManager(),
+		VotingManager:   indexed_votings.NewVotingManager(),
+		SlashingManager: indexed_slashings.NewSlashingManager(),
+
+		ValidatorHistory: indexed_validators.NewValidatorHistory(),
 	}
 }
@@ -0,0 +1,16 @@
+package indexed_slashings
+
+import (
+	"github.com/Digital-Voting-Team/Digital-Voting/pkg/signature/keys"
+)
+
+// SlashingDTO records why a validator was removed from the set, EvidenceHash is the hash of the evidence transaction body
+type SlashingDTO struct {
+	Offender     keys.PublicKeyBytes `json:"offender"`
+	Reporter     keys.PublicKeyBytes `json:"reporter"`
+	Kind         string              `json:"kind"`
+	Height       uint64              `json:"height"`
+	Round        uint32              `json:"round"`
+	BlockHashes  [2][32]byte         `json:"block_hashes"`
+	EvidenceHash [32]byte            `json:"evidence_hash"`
+}
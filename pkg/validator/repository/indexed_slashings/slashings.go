@@ -0,0 +1,34 @@
+package indexed_slashings
+
+import (
+	"github.com/Digital-Voting-Team/Digital-Voting/pkg/signature/keys"
+)
+
+// SlashingManager keeps validators slashed for equivocation, they are not taken back into the validator set
+type SlashingManager struct {
+	IndexedSlashings map[keys.PublicKeyBytes]SlashingDTO
+}
+
+func NewSlashingManager() *SlashingManager {
+	return &SlashingManager{
+		IndexedSlashings: map[keys.PublicKeyBytes]SlashingDTO{},
+	}
+}
+
+// AddSlashing keeps the first record of the offender, later evidence against it changes nothing
+func (sm *SlashingManager) AddSlashing(slashing SlashingDTO) {
+	_, exists := sm.IndexedSlashings[slashing.Offender]
+	if !exists {
+		sm.IndexedSlashings[slashing.Offender] = slashing
+	}
+}
+
+func (sm *SlashingManager) GetSlashing(offender keys.PublicKeyBytes) (SlashingDTO, bool) {
+	slashing, exists := sm.IndexedSlashings[offender]
+	return slashing, exists
+}
+
+func (sm *SlashingManager) IsSlashed(publicKey keys.PublicKeyBytes) bool {
+	_, exists := sm.IndexedSlashings[publicKey]
+	return exists
+}
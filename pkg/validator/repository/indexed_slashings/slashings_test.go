@@ -0,0 +1,39 @@
+package indexed_slashings
+
+import (
+	"github.com/Digital-Voting-Team/Digital-Voting/pkg/signature/keys"
+	"testing"
+)
+
+func TestSlashingManager_AddSlashing(t *testing.T) {
+	sm := NewSlashingManager()
+
+	tests := []struct {
+		name       string
+		slashing   SlashingDTO
+		wantHeight uint64
+	}{
+		{
+			name:       "First evidence is recorded",
+			slashing:   SlashingDTO{Offender: keys.PublicKeyBytes{1}, Height: 5},
+			wantHeight: 5,
+		},
+		{
+			name:       "Later evidence keeps the first record",
+			slashing:   SlashingDTO{Offender: keys.PublicKeyBytes{1}, Height: 7},
+			wantHeight: 5,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sm.AddSlashing(tt.slashing)
+			got, exists := sm.GetSlashing(tt.slashing.Offender)
+			if !exists || got.Height != tt.wantHeight {
+				t.Errorf("GetSlashing() = %v, %v, want height %v", got, exists, tt.wantHeight)
+			}
+			if sm.IsSlashed(keys.PublicKeyBytes{2}) {
+				t.Errorf("IsSlashed() = true for a key without evidence")
+			}
+		})
+	}
+}
@@ -0,0 +1,59 @@
+package indexed_validators
+
+import (
+	"github.com/Digital-Voting-Team/Digital-Voting/pkg/signature/keys"
+	"sort"
+)
+
+// ValidatorSet is the set of validators in force from height From on, keys are sorted with keys.SortPublicKeys
+type ValidatorSet struct {
+	From       uint64                `json:"from"`
+	PublicKeys []keys.PublicKeyBytes `json:"public_keys"`
+}
+
+// ValidatorHistory keeps every validator set the chain had, so witnesses and evidence of past heights
+// are checked against the validators of their height rather than the current ones
+type ValidatorHistory struct {
+	Sets []ValidatorSet
+}
+
+func NewValidatorHistory() *ValidatorHistory {
+	return &ValidatorHistory{
+		Sets: []ValidatorSet{},
+	}
+}
+
+// Record makes validators the set in force from height from on, an unchanged set is not recorded again
+func (vh *ValidatorHistory) Record(from uint64, validators map[keys.PublicKeyBytes]struct{}) {
+	publicKeys := make([]keys.PublicKeyBytes, 0, len(validators))
+	for publicKey := range validators {
+		publicKeys = append(publicKeys, publicKey)
+	}
+	publicKeys = keys.SortPublicKeys(publicKeys)
+
+	if len(vh.Sets) != 0 && equal(vh.Sets[len(vh.Sets)-1].PublicKeys, publicKeys) {
+		return
+	}
+	vh.Sets = append(vh.Sets, ValidatorSet{From: from, PublicKeys: publicKeys})
+}
+
+// At returns the sorted set of validators in force at height, nil before the first recorded set
+func (vh *ValidatorHistory) At(height uint64) []keys.PublicKeyBytes {
+	i := sort.Search(len(vh.Sets), func(i int) bool { return vh.Sets[i].From > height })
+	if i == 0 {
+		return nil
+	}
+	return append([]keys.PublicKeyBytes{}, vh.Sets[i-1].PublicKeys...)
+}
+
+func equal(a, b []keys.PublicKeyBytes) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
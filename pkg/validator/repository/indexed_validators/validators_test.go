@@ -0,0 +1,37 @@
+package indexed_validators
+
+import (
+	"github.com/Digital-Voting-Team/Digital-Voting/pkg/signature/keys"
+	"reflect"
+	"testing"
+)
+
+func TestValidatorHistory_At(t *testing.T) {
+	first, second := keys.PublicKeyBytes{1}, keys.PublicKeyBytes{2}
+	vh := NewValidatorHistory()
+	vh.Record(1, map[keys.PublicKeyBytes]struct{}{first: {}, second: {}})
+	vh.Record(2, map[keys.PublicKeyBytes]struct{}{first: {}, second: {}})
+	vh.Record(5, map[keys.PublicKeyBytes]struct{}{second: {}})
+
+	tests := []struct {
+		name   string
+		height uint64
+		want   []keys.PublicKeyBytes
+	}{
+		{name: "Before the first set", height: 0, want: nil},
+		{name: "First set", height: 1, want: []keys.PublicKeyBytes{first, second}},
+		{name: "Unchanged set", height: 4, want: []keys.PublicKeyBytes{first, second}},
+		{name: "Set after removal", height: 5, want: []keys.PublicKeyBytes{second}},
+		{name: "Current set", height: 100, want: []keys.PublicKeyBytes{second}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := vh.At(tt.height); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("At() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+	if len(vh.Sets) != 2 {
+		t.Errorf("history has %d sets, want 2 as unchanged sets are not recorded", len(vh.Sets))
+	}
+}
@@ -2,11 +2,15 @@ package indexed_votings
 
 type VotingManager struct {
 	IndexedVotings map[[32]byte]VotingDTO
+	// Votes maps voting hash to answers of its voters, voter is the public key of a vote
+	// or the key image of an anonymous vote
+	Votes map[[32]byte]map[[33]byte]uint8
 }
 
 func NewVotingManager() *VotingManager {
 	return &VotingManager{
 		IndexedVotings: map[[32]byte]VotingDTO{},
+		Votes:          map[[32]byte]map[[33]byte]uint8{},
 	}
 }
 
@@ -24,4 +28,30 @@ func (vp *VotingManager) GetVoting(hash [32]byte) VotingDTO {
 
 func (vp *VotingManager) RemoveVoting(hash [32]byte) {
 	delete(vp.IndexedVotings, hash)
+	delete(vp.Votes, hash)
+}
+
+func (vp *VotingManager) AddVote(hash [32]byte, voter [33]byte, answer uint8) {
+	votes, exists := vp.Votes[hash]
+	if !exists {
+		votes = map[[33]byte]uint8{}
+		vp.Votes[hash] = votes
+	}
+	votes[voter] = answer
+}
+
+func (vp *VotingManager) HasVoted(hash [32]byte, voter [33]byte) bool {
+	_, voted := vp.Votes[hash][voter]
+	return voted
+}
+
+// GetResults counts votes for every answer of the voting
+func (vp *VotingManager) GetResults(hash [32]byte) []uint64 {
+	results := make([]uint64, len(vp.IndexedVotings[hash].Answers))
+	for _, answer := range vp.Votes[hash] {
+		if int(answer) < len(results) {
+			results[answer]++
+		}
+	}
+	return results
 }
@@ -123,3 +123,51 @@ func TestVotingProvider_RemoveVoting(t *testing.T) {
 		})
 	}
 }
+
+func TestVotingProvider_HasVoted(t *testing.T) {
+	vp := NewVotingManager()
+	vp.AddNewVoting(VotingDTO{Hash: [32]byte{1}, VotingDescription: [1024]byte{1}})
+	vp.AddVote([32]byte{1}, [33]byte{1}, 0)
+
+	type args struct {
+		hash  [32]byte
+		voter [33]byte
+	}
+	tests := []struct {
+		name string
+		args args
+		want bool
+	}{
+		{
+			name: "Voter of the voting",
+			args: args{
+				hash:  [32]byte{1},
+				voter: [33]byte{1},
+			},
+			want: true,
+		},
+		{
+			name: "Voter that did not vote",
+			args: args{
+				hash:  [32]byte{1},
+				voter: [33]byte{2},
+			},
+			want: false,
+		},
+		{
+			name: "Voter of another voting",
+			args: args{
+				hash:  [32]byte{2},
+				voter: [33]byte{1},
+			},
+			want: false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := vp.HasVoted(tt.args.hash, tt.args.voter); got != tt.want {
+				t.Errorf("HasVoted() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,131 @@
+package validation
+
+import (
+	"github.com/Digital-Voting-Team/Digital-Voting/pkg/blockchain/transaction"
+	"sync"
+	"sync/atomic"
+)
+
+// SignatureCacheSize is the number of hashes of transactions with verified signatures kept,
+// it covers the MemPool so transactions verified on admission are not verified again in a block
+const SignatureCacheSize = 16384
+
+// signatureCache remembers hashes of transactions whose signatures were verified,
+// the hash covers the signature so a cached hash can't belong to a forged transaction
+type signatureCache struct {
+	mutex  sync.Mutex
+	hashes map[[32]byte]struct{}
+	order  [][32]byte
+	next   int
+}
+
+func newSignatureCache(size int) *signatureCache {
+	return &signatureCache{
+		hashes: map[[32]byte]struct{}{},
+		order:  make([][32]byte, 0, size),
+	}
+}
+
+func (sc *signatureCache) contains(hash [32]byte) bool {
+	sc.mutex.Lock()
+	defer sc.mutex.Unlock()
+	_, exists := sc.hashes[hash]
+	return exists
+}
+
+// add replaces the oldest hash once the cache is full
+func (sc *signatureCache) add(hash [32]byte) {
+	sc.mutex.Lock()
+	defer sc.mutex.Unlock()
+	if _, exists := sc.hashes[hash]; exists || cap(sc.order) == 0 {
+		return
+	}
+
+	if len(sc.order) < cap(sc.order) {
+		sc.order = append(sc.order, hash)
+	} else {
+		delete(sc.hashes, sc.order[sc.next])
+		sc.order[sc.next] = hash
+		sc.next = (sc.next + 1) % len(sc.order)
+	}
+	sc.hashes[hash] = struct{}{}
+}
+
+// SignatureVerifier verifies signatures of transactions on a pool of workers and caches the results,
+// a nil SignatureVerifier verifies sequentially without a cache
+type SignatureVerifier struct {
+	workers int
+	cache   *signatureCache
+}
+
+func NewSignatureVerifier(workers, cacheSize int) *SignatureVerifier {
+	if workers < 1 {
+		workers = 1
+	}
+	return &SignatureVerifier{
+		workers: workers,
+		cache:   newSignatureCache(cacheSize),
+	}
+}
+
+// VerifySignature verifies a single transaction unless its signature was verified before
+func (sv *SignatureVerifier) VerifySignature(tx transaction.ITransaction) bool {
+	if sv == nil {
+		return tx.VerifySignature()
+	}
+
+	hash := tx.GetHash()
+	if sv.cache.contains(hash) {
+		return true
+	}
+	if !tx.VerifySignature() {
+		return false
+	}
+	sv.cache.add(hash)
+	return true
+}
+
+// VerifySignatures reports whether signatures of all transactions are valid,
+// workers stop taking new transactions once one of them fails
+func (sv *SignatureVerifier) VerifySignatures(transactions []transaction.ITransaction) bool {
+	if sv == nil || sv.workers == 1 || len(transactions) < 2 {
+		for _, tx := range transactions {
+			if !sv.VerifySignature(tx) {
+				return false
+			}
+		}
+		return true
+	}
+
+	jobs := make(chan transaction.ITransaction, len(transactions))
+	for _, tx := range transactions {
+		jobs <- tx
+	}
+	close(jobs)
+
+	workers := sv.workers
+	if workers > len(transactions) {
+		workers = len(transactions)
+	}
+
+	var failed int32
+	var wg sync.WaitGroup
+	wg.Add(workers)
+	for i := 0; i < workers; i++ {
+		go func() {
+			defer wg.Done()
+			for tx := range jobs {
+				if atomic.LoadInt32(&failed) != 0 {
+					return
+				}
+				if !sv.VerifySignature(tx) {
+					atomic.StoreInt32(&failed, 1)
+					return
+				}
+			}
+		}()
+	}
+	wg.Wait()
+
+	return failed == 0
+}
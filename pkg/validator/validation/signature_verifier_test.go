@@ -0,0 +1,58 @@
+package validation
+
+import (
+	tx "github.com/Digital-Voting-Team/Digital-Voting/pkg/blockchain/transaction"
+	ts "github.com/Digital-Voting-Team/Digital-Voting/pkg/blockchain/transaction/transaction_specific"
+	"github.com/Digital-Voting-Team/Digital-Voting/pkg/models/account"
+	"github.com/Digital-Voting-Team/Digital-Voting/pkg/signature/curve"
+	"github.com/Digital-Voting-Team/Digital-Voting/pkg/signature/keys"
+	"github.com/Digital-Voting-Team/Digital-Voting/pkg/signer"
+	"testing"
+)
+
+func TestSignatureVerifier_VerifySignatures(t *testing.T) {
+	keyPair, _ := keys.Random(curve.NewCurve25519())
+	txSigner := signer.NewTransactionSigner()
+
+	signed := []tx.ITransaction{}
+	for i := byte(1); i <= 4; i++ {
+		transaction := tx.NewTransaction(tx.AccountCreation, ts.NewTxAccCreation(account.User, keys.PublicKeyBytes{i}))
+		txSigner.SignTransaction(keyPair, transaction)
+		signed = append(signed, transaction)
+	}
+	forged := tx.NewTransaction(tx.AccountCreation, ts.NewTxAccCreation(account.User, keys.PublicKeyBytes{5}))
+	forged.PublicKey = keyPair.PublicToBytes()
+
+	tests := []struct {
+		name         string
+		verifier     *SignatureVerifier
+		transactions []tx.ITransaction
+		want         bool
+	}{
+		{name: "Parallel valid signatures", verifier: NewSignatureVerifier(4, 16), transactions: signed, want: true},
+		{name: "Parallel forged signature", verifier: NewSignatureVerifier(4, 16), transactions: append(append([]tx.ITransaction{}, signed...), forged), want: false},
+		{name: "Sequential valid signatures", verifier: nil, transactions: signed, want: true},
+		{name: "Sequential forged signature", verifier: nil, transactions: []tx.ITransaction{signed[0], forged}, want: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.verifier.VerifySignatures(tt.transactions); got != tt.want {
+				t.Errorf("VerifySignatures() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSignatureCache(t *testing.T) {
+	cache := newSignatureCache(2)
+	cache.add([32]byte{1})
+	cache.add([32]byte{2})
+	cache.add([32]byte{3})
+
+	if cache.contains([32]byte{1}) {
+		t.Errorf("oldest hash was not evicted")
+	}
+	if !cache.contains([32]byte{2}) || !cache.contains([32]byte{3}) {
+		t.Errorf("recent hashes were evicted")
+	}
+}
@@ -5,7 +5,7 @@ import (
 	"github.com/Digital-Voting-Team/Digital-Voting/pkg/validator/repository"
 )
 
-func CheckOnCreateTransaction(tx transaction.ITransaction, indexedData *repository.IndexedData) bool {
+func CheckOnCreateTransaction(tx transaction.ITransaction, indexedData *repository.IndexedData) error {
 	// TODO: think of how to actually get data from Identity Provider
 	return tx.CheckOnCreate(indexedData)
 }
@@ -4,6 +4,7 @@ import (
 	tx "github.com/Digital-Voting-Team/Digital-Voting/pkg/blockchain/transaction"
 	ts "github.com/Digital-Voting-Team/Digital-Voting/pkg/blockchain/transaction/transaction_specific"
 	"github.com/Digital-Voting-Team/Digital-Voting/pkg/models/account"
+	"github.com/Digital-Voting-Team/Digital-Voting/pkg/rejection"
 	"github.com/Digital-Voting-Team/Digital-Voting/pkg/signature/curve"
 	"github.com/Digital-Voting-Team/Digital-Voting/pkg/signature/keys"
 	ss "github.com/Digital-Voting-Team/Digital-Voting/pkg/signature/signatures/single_signature"
@@ -91,7 +92,7 @@ func TestValidateTransaction(t *testing.T) {
 	tests := []struct {
 		name string
 		args args
-		want bool
+		want rejection.Code
 	}{
 		{
 			name: "Valid Account creation tx",
@@ -99,7 +100,7 @@ func TestValidateTransaction(t *testing.T) {
 				tx:          txAccountCreation,
 				indexedData: indexedData,
 			},
-			want: true,
+			want: "",
 		},
 		{
 			name: "Valid Group creation tx",
@@ -107,7 +108,7 @@ func TestValidateTransaction(t *testing.T) {
 				tx:          txGroupCreation,
 				indexedData: indexedData,
 			},
-			want: true,
+			want: "",
 		},
 		{
 			name: "Valid Voting creation tx",
@@ -115,7 +116,7 @@ func TestValidateTransaction(t *testing.T) {
 				tx:          txVotingCreation,
 				indexedData: indexedData,
 			},
-			want: true,
+			want: "",
 		},
 		{
 			name: "Valid Vote tx",
@@ -123,7 +124,7 @@ func TestValidateTransaction(t *testing.T) {
 				tx:          txVote,
 				indexedData: indexedData,
 			},
-			want: true,
+			want: "",
 		},
 		{
 			name: "Valid Vote anonymous tx",
@@ -131,7 +132,7 @@ func TestValidateTransaction(t *testing.T) {
 				tx:          txVoteAnonymous,
 				indexedData: indexedData,
 			},
-			want: true,
+			want: "",
 		},
 		{
 			name: "Invalid identity provider and/or administrator",
@@ -139,13 +140,13 @@ func TestValidateTransaction(t *testing.T) {
 				tx:          txVoteAnonymous,
 				indexedData: repository.NewIndexedData(),
 			},
-			want: false,
+			want: rejection.UnknownVoting,
 		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			if got := CheckOnCreateTransaction(tt.args.tx, tt.args.indexedData); got != tt.want {
-				t.Errorf("CheckOnCreateTransaction() = %v, want %v", got, tt.want)
+			if got := rejection.CodeOf(CheckOnCreateTransaction(tt.args.tx, tt.args.indexedData)); got != tt.want {
+				t.Errorf("CheckOnCreateTransaction() code = %v, want %v", got, tt.want)
 			}
 		})
 	}
@@ -1,30 +1,41 @@
 package validator
 
 import (
+	"context"
+	"fmt"
 	"github.com/Digital-Voting-Team/Digital-Voting/pkg/blockchain"
 	blk "github.com/Digital-Voting-Team/Digital-Voting/pkg/blockchain/block"
 	"github.com/Digital-Voting-Team/Digital-Voting/pkg/blockchain/block/merkle_tree"
 	tx "github.com/Digital-Voting-Team/Digital-Voting/pkg/blockchain/transaction"
-	"github.com/Digital-Voting-Team/Digital-Voting/pkg/signature/curve"
+	ts "github.com/Digital-Voting-Team/Digital-Voting/pkg/blockchain/transaction/transaction_specific"
+	"github.com/Digital-Voting-Team/Digital-Voting/pkg/consensus/evidence"
+	"github.com/Digital-Voting-Team/Digital-Voting/pkg/logging"
+	"github.com/Digital-Voting-Team/Digital-Voting/pkg/rejection"
 	"github.com/Digital-Voting-Team/Digital-Voting/pkg/signature/keys"
+	ms "github.com/Digital-Voting-Team/Digital-Voting/pkg/signature/signatures/multi_signature"
 	ss "github.com/Digital-Voting-Team/Digital-Voting/pkg/signature/signatures/single_signature"
 	"github.com/Digital-Voting-Team/Digital-Voting/pkg/signer"
+	"github.com/Digital-Voting-Team/Digital-Voting/pkg/validator/admission"
 	"github.com/Digital-Voting-Team/Digital-Voting/pkg/validator/repository"
 	"github.com/Digital-Voting-Team/Digital-Voting/pkg/validator/repository/account_manager"
 	"github.com/Digital-Voting-Team/Digital-Voting/pkg/validator/repository/indexed_votings"
-	"log"
+	"github.com/Digital-Voting-Team/Digital-Voting/pkg/validator/validation"
+	"os"
+	"path/filepath"
+	"runtime"
+	"sync"
 	"time"
 )
 
 //var RegAdminPrivateKey = keys.PrivateKeyBytes{1}
 
-const MaxTransactionsInBlock = 5
+// BlocksFile and MemPoolFile are the names of files in DataDir the chain and pending transactions are saved to
+const (
+	BlocksFile  = "blocks.json"
+	MemPoolFile = "mem_pool.json"
+)
 
-type ResponseMessage struct {
-	VerificationSuccess bool                    `json:"verification_success"`
-	PublicKey           keys.PublicKeyBytes     `json:"public_key"`
-	Signature           ss.SingleSignatureBytes `json:"signature"`
-}
+var ErrMemPoolRejected = rejection.New(rejection.MemPoolRejected, "transaction is already pending or MemPool is full")
 
 type Validator struct {
 	KeyPair     *keys.KeyPair
@@ -32,176 +43,351 @@ type Validator struct {
 	IndexedData *repository.IndexedData
 	BlockSigner *signer.BlockSigner
 	Blockchain  *blockchain.Blockchain
+	Admission   *admission.Controller
+	Verifier    *validation.SignatureVerifier
+	BlockFeed   *BlockFeed
+
+	// DataDir keeps the chain and pending transactions between restarts, empty keeps them in memory only
+	DataDir string
+
+	// Network receives blocks created by this validator, the producer does not run without it
+	Network Network
 
-	Channels Communication
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
 }
 
-func NewValidator(
-	bc *blockchain.Blockchain,
-	channels Communication,
-) *Validator {
-	validatorKeys, err := keys.Random(curve.NewCurve25519())
-	if err != nil {
-		log.Fatal(err)
-	}
+// NewValidator creates a validator signing blocks with keyPair, the same key has to be used across restarts
+func NewValidator(bc *blockchain.Blockchain, keyPair *keys.KeyPair) *Validator {
 	v := &Validator{
-		KeyPair:     validatorKeys,
+		KeyPair:     keyPair,
 		MemPool:     NewMemPool(),
 		IndexedData: repository.NewIndexedData(),
 		BlockSigner: signer.NewBlockSigner(),
 		Blockchain:  bc,
-
-		Channels: channels,
+		Admission:   admission.NewController(admission.DefaultConfig()),
+		Verifier:    validation.NewSignatureVerifier(runtime.NumCPU(), validation.SignatureCacheSize),
+		BlockFeed:   NewBlockFeed(),
 	}
 
-	v.StartRoutines()
-
 	return v
 }
 
-func (v *Validator) StartRoutines() {
-	go v.ValidateBlocks()
-	go v.CreateAndSendBlock()
-	go v.ApproveBlock()
-	go v.DenyBlock()
-	go v.UpdateValidatorKeys()
-	go v.AddNewTransaction()
-	go v.GetVotingsForPubKey()
-}
+// Start rebuilds indexed data from the chain, restores saved MemPool and produces blocks until Stop or ctx is done
+func (v *Validator) Start(ctx context.Context) {
+	for _, block := range v.Blockchain.GetBlocks() {
+		v.ActualizeNodeData(block)
+	}
+	v.loadMemPool()
 
-// ValidateBlocks wait for blocks from channel and validate them
-func (v *Validator) ValidateBlocks() {
-	var response ResponseMessage
-	for {
-		newBlock := <-v.Channels.NetworkToValidator
-		if v.VerifyBlock(newBlock) {
-			log.Printf("Successfully verified block with hash %s", newBlock.GetHashString())
-			publicKey, signature := v.SignBlock(newBlock)
-			response = ResponseMessage{
-				VerificationSuccess: true,
-				PublicKey:           publicKey,
-				Signature:           signature,
-			}
-		} else {
-			response = ResponseMessage{
-				VerificationSuccess: false,
-			}
-		}
-		v.Channels.BlockResponse <- response
+	ctx, v.cancel = context.WithCancel(ctx)
+	if v.Network == nil {
+		return
 	}
+	v.wg.Add(1)
+	go func() {
+		defer v.wg.Done()
+		v.CreateAndSendBlock(ctx)
+	}()
 }
 
-// ApproveBlock wait for transactions from channel, approve and add them to blockchain
-func (v *Validator) ApproveBlock() {
-	for {
-		approvedBlock := <-v.Channels.BlockApproval
-		log.Printf("Block with hash %s received to approve", approvedBlock.GetHashString())
-		if v.VerifyBlock(approvedBlock) {
-			err := v.AddBlockToChain(approvedBlock)
-			if err != nil {
-				log.Fatalln(err)
-			}
-			v.ActualizeNodeData(approvedBlock)
-			v.Channels.ApprovalResponse <- true
-			log.Printf("Block with hash %s added", approvedBlock.GetHashString())
-		} else {
-			v.Channels.ApprovalResponse <- false
-		}
+// Stop waits for the producer to return and saves the chain and MemPool to DataDir
+func (v *Validator) Stop() error {
+	if v.cancel != nil {
+		v.cancel()
+	}
+	v.wg.Wait()
+
+	if v.DataDir == "" {
+		return nil
 	}
+	err := v.Blockchain.SaveToFile(filepath.Join(v.DataDir, BlocksFile))
+	if err != nil {
+		return err
+	}
+	return v.MemPool.SaveToFile(filepath.Join(v.DataDir, MemPoolFile))
 }
 
-// DenyBlock wait for transactions from channel, restore transactions from it
-func (v *Validator) DenyBlock() {
-	for {
-		deniedBlock := <-v.Channels.BlockDenial
-		v.RestoreMemPool(deniedBlock.Body.Transactions)
+// loadMemPool restores transactions saved on Stop, they are verified again since the chain may have changed
+func (v *Validator) loadMemPool() {
+	if v.DataDir == "" {
+		return
+	}
+	transactions, err := v.MemPool.LoadFromFile(filepath.Join(v.DataDir, MemPoolFile))
+	if err != nil {
+		if !os.IsNotExist(err) {
+			logging.Errorln("Error loading MemPool:", err)
+		}
+		return
 	}
+	v.RestoreMemPool(transactions)
 }
 
 func (v *Validator) RestoreMemPool(transactions []tx.ITransaction) {
-	transactionsToRestore := transactions
-	v.IndexedData.Mutex.Lock()
+	signed := []tx.ITransaction{}
 	for _, transaction := range transactions {
-		if transaction.Verify(v.IndexedData) {
-			transactionsToRestore = append(transactionsToRestore, transaction)
+		if v.Verifier.VerifySignature(transaction) {
+			signed = append(signed, transaction)
+		}
+	}
+
+	transactionsToRestore := []tx.ITransaction{}
+	v.IndexedData.Mutex.Lock()
+	for _, transaction := range signed {
+		err := transaction.VerifyData(v.IndexedData)
+		if err != nil {
+			logging.Errorf("Transaction with hash %s is not restored: %v", transaction.GetHashString(), err)
+			continue
 		}
+		transactionsToRestore = append(transactionsToRestore, transaction)
 	}
 	v.IndexedData.Mutex.Unlock()
 	v.MemPool.RestoreMemPool(transactionsToRestore)
 }
 
-// TODO: get last block hash from blockchain
-func (v *Validator) CreateAndSendBlock() {
-	ticker := time.NewTicker(time.Second * 10)
+// CreateAndSendBlock creates blocks while validator is the proposer of current height,
+// otherwise it forwards pending transactions to the proposer.
+// A block is created every BlockInterval or as soon as MemPool holds enough transactions to fill one
+func (v *Validator) CreateAndSendBlock(ctx context.Context) {
+	params := v.Blockchain.Params()
+	ticker := time.NewTicker(params.BlockInterval)
+	defer ticker.Stop()
 	for {
 		select {
+		case <-ctx.Done():
+			return
 		case <-ticker.C:
-			hash := v.Blockchain.GetLastBlockHash()
-			if v.MemPool.GetTransactionsCount() > 0 {
-				v.Channels.ValidatorToNetwork <- v.CreateBlock(hash)
+			if v.MemPool.GetTransactionsCount() == 0 {
+				continue
 			}
-		default:
-			hash := v.Blockchain.GetLastBlockHash()
-			if v.MemPool.GetTransactionsCount() >= MaxTransactionsInBlock {
-				v.Channels.ValidatorToNetwork <- v.CreateBlock(hash)
+			proposer, err := v.Network.Proposer()
+			if err != nil {
+				logging.Errorln("No proposer to hand transactions to:", err)
+				continue
+			}
+			if proposer != v.PublicKey() {
+				v.ForwardTransactions(proposer)
+			} else if block := v.CreateBlock(v.Blockchain.GetLastBlockHash()); block != nil {
+				v.Network.SubmitBlock(block)
+			}
+		case <-v.MemPool.Added():
+			if v.MemPool.GetTransactionsCount() < params.MaxTransactions {
+				continue
+			}
+			if proposer, err := v.Network.Proposer(); err != nil || proposer != v.PublicKey() {
+				continue
+			}
+			if block := v.CreateBlock(v.Blockchain.GetLastBlockHash()); block != nil {
+				v.Network.SubmitBlock(block)
 			}
 		}
 	}
 }
 
-func (v *Validator) AddToMemPool(newTransaction tx.ITransaction) bool {
+// ForwardTransactions passes pending transactions that fit into a block to the proposer.
+// They stay in MemPool until they are committed, so they are not lost if the proposer drops them
+func (v *Validator) ForwardTransactions(proposer keys.PublicKeyBytes) {
+	transactions := v.MemPool.GetTransactions()
+	if maxTransactions := v.Blockchain.Params().MaxTransactions; len(transactions) > maxTransactions {
+		transactions = transactions[:maxTransactions]
+	}
+	err := v.Network.ForwardTransactions(proposer, transactions)
+	if err != nil {
+		logging.Errorf("Failed to forward %d transactions to the proposer: %v", len(transactions), err)
+	}
+}
+
+// AddToMemPool runs stateless admission first since it is much cheaper than verification of the signature,
+// the sender is charged only once the signature proves the transaction is theirs
+func (v *Validator) AddToMemPool(newTransaction tx.ITransaction) error {
+	err := v.Admission.AdmitTransaction(newTransaction)
+	if err != nil {
+		logging.Errorf("Transaction with hash %s not admitted: %v", newTransaction.GetHashString(), err)
+		return err
+	}
+
+	// Signature is verified without the lock, successful verification is cached for block verification
+	if !v.Verifier.VerifySignature(newTransaction) {
+		err = rejection.New(rejection.BadSignature, "transaction signature is invalid")
+		logging.Errorf("Transaction with hash %s rejected: %v", newTransaction.GetHashString(), err)
+		v.Admission.Reject(admission.InvalidTransaction)
+		return err
+	}
+
+	err = v.Admission.ChargeSender(newTransaction)
+	if err != nil {
+		logging.Errorf("Transaction with hash %s not admitted: %v", newTransaction.GetHashString(), err)
+		return err
+	}
+
 	v.IndexedData.Mutex.Lock()
-	response := newTransaction.CheckOnCreate(v.IndexedData)
+	err = newTransaction.CheckDataOnCreate(v.IndexedData)
 	v.IndexedData.Mutex.Unlock()
-	if response {
-		response = v.MemPool.AddToMemPool(newTransaction)
+	if err != nil {
+		logging.Errorf("Transaction with hash %s rejected: %v", newTransaction.GetHashString(), err)
+		v.Admission.Reject(admission.InvalidTransaction)
+		return err
+	}
+
+	if !v.MemPool.AddToMemPool(newTransaction) {
+		v.Admission.Reject(admission.MemPoolRejected)
+		return ErrMemPoolRejected
 	}
-	return response
+	v.Admission.Accept()
+	return nil
 }
 
+// CreateBlock returns nil if no transaction of MemPool fits into a block, so empty blocks are never proposed
 func (v *Validator) CreateBlock(previousBlockHash [32]byte) *blk.Block {
 	// Validator does not validate its block since it validated all transactions while adding them to MemPool
 
-	// Takes up to MAX_TRANSACTIONS_IN_BLOCK transactions from beginning of MemPool and create block body with them
-	maxNumber := MaxTransactionsInBlock
+	// Takes up to MaxTransactions transactions from beginning of MemPool and create block body with them
+	params := v.Blockchain.Params()
+	transactions := v.MemPool.GetWithUpperBound(params.MaxTransactions)
+	if len(transactions) == 0 {
+		return nil
+	}
+
+	// Transactions that do not fit into MaxBlockBytes go back to MemPool for the next block
+	count := len(transactions)
+	newBlock := v.newBlock(previousBlockHash, transactions)
+	for count > 0 && !newBlock.CheckLimits(params) {
+		count--
+		newBlock = v.newBlock(previousBlockHash, transactions[:count])
+	}
+	if count == 0 {
+		// Transaction that does not fit even into a block of its own would stay in front of MemPool forever
+		logging.Errorf("Transaction with hash %s exceeds block size limit and is evicted from MemPool", transactions[0].GetHashString())
+		v.MemPool.RestoreMemPool(transactions[1:])
+		return nil
+	}
+	v.MemPool.RestoreMemPool(transactions[count:])
+
+	return newBlock
+}
+
+// newBlock creates block without the witness, it is aggregated once validators approve the block
+func (v *Validator) newBlock(previousBlockHash [32]byte, transactions []tx.ITransaction) *blk.Block {
 	blockBody := blk.Body{
-		Transactions: v.MemPool.GetWithUpperBound(maxNumber),
+		Transactions: transactions,
 	}
-	// Create block header
 	blockHeader := blk.Header{
 		Previous:   previousBlockHash,
 		TimeStamp:  uint64(time.Now().Unix()),
 		MerkleRoot: merkle_tree.GetMerkleRoot(blockBody.Transactions),
 	}
 
-	// Create block itself
-	newBlock := &blk.Block{
+	return &blk.Block{
 		Header: blockHeader,
 		Body:   blockBody,
 	}
+}
 
-	// Sign block
-	v.SignAndUpdateBlock(newBlock)
+// CreateNonce starts signing session of the block witness
+func (v *Validator) CreateNonce(session string) (ms.NonceBytes, error) {
+	return v.BlockSigner.CreateNonce(session)
+}
 
-	return newBlock
+// SignPartial signs the block witness with the nonce created for the session
+func (v *Validator) SignPartial(
+	session string,
+	block *blk.Block,
+	signers []keys.PublicKeyBytes,
+	nonces []ms.NonceBytes,
+) (ms.PartialSignatureBytes, error) {
+	return v.BlockSigner.SignPartial(v.KeyPair, session, block, signers, nonces)
+}
+
+// Sign signs arbitrary message with validator keys, used for consensus messages and to prove control of the key to peers
+func (v *Validator) Sign(message string) ss.SingleSignatureBytes {
+	ecdsa := v.BlockSigner.BlkSigner
+	edwardsSignature := ecdsa.SignEdDSA(message, v.KeyPair.GetPrivateKey(), v.KeyPair.GetPublicKey())
+	return ecdsa.EdwardsToSingleSignature(edwardsSignature).EdwardsSignatureToBytes()
+}
+
+func (v *Validator) PublicKey() keys.PublicKeyBytes {
+	return v.KeyPair.PublicToBytes()
 }
 
-func (v *Validator) SignAndUpdateBlock(block *blk.Block) {
-	v.BlockSigner.SignAndUpdateBlock(v.KeyPair, block)
+func (v *Validator) Height() uint64 {
+	return v.Blockchain.Height()
 }
 
-func (v *Validator) SignBlock(block *blk.Block) (keys.PublicKeyBytes, ss.SingleSignatureBytes) {
-	return v.BlockSigner.SignBlock(v.KeyPair, block)
+func (v *Validator) LastBlockHash() [32]byte {
+	return v.Blockchain.GetLastBlockHash()
+}
+
+func (v *Validator) ValidatorSet() []keys.PublicKeyBytes {
+	v.IndexedData.Mutex.Lock()
+	defer v.IndexedData.Mutex.Unlock()
+	validators := []keys.PublicKeyBytes{}
+	for key := range v.IndexedData.AccountManager.ValidatorPubKeys {
+		validators = append(validators, key)
+	}
+	return validators
+}
+
+// IsValidator reports whether publicKey is a validator recorded on chain
+func (v *Validator) IsValidator(publicKey keys.PublicKeyBytes) bool {
+	v.IndexedData.Mutex.Lock()
+	defer v.IndexedData.Mutex.Unlock()
+	return v.IndexedData.AccountManager.CheckPubKeyPresence(publicKey, account_manager.Validator)
+}
+
+// CommitBlock verifies block decided by consensus, adds it to blockchain and actualizes node data
+func (v *Validator) CommitBlock(block *blk.Block) error {
+	err := v.VerifyBlock(block)
+	if err != nil {
+		return fmt.Errorf("block with hash %s failed verification: %w", block.GetHashString(), err)
+	}
+
+	err = v.AddBlockToChain(block)
+	if err != nil {
+		return err
+	}
+	v.ActualizeNodeData(block)
+	v.BlockFeed.Publish(CommittedBlock{Height: v.Height() - 1, Block: block})
+
+	return nil
+}
+
+func (v *Validator) RestoreBlock(block *blk.Block) {
+	v.RestoreMemPool(block.Body.Transactions)
+}
+
+func (v *Validator) VerifyBlock(block *blk.Block) error {
+	v.IndexedData.Mutex.Lock()
+	err := block.Witness.Verify(v.IndexedData.ValidatorHistory.At(v.IndexedData.Height), block.GetHashString())
+	v.IndexedData.Mutex.Unlock()
+	if err != nil {
+		return err
+	}
+
+	return v.VerifyProposal(block)
 }
 
-func (v *Validator) VerifyBlock(block *blk.Block) bool {
-	if block.Header.Previous != v.Blockchain.GetLastBlockHash() || len(block.Body.Transactions) > MaxTransactionsInBlock {
-		return false
+// VerifyProposal verifies block that has no witness yet, signatures are verified in parallel
+// before the lock of indexed data is taken for the checks that need it
+func (v *Validator) VerifyProposal(block *blk.Block) error {
+	if block.Header.Previous != v.Blockchain.GetLastBlockHash() {
+		return rejection.New(rejection.WrongPreviousBlock, "block does not follow the last block of the chain")
+	}
+	if !block.CheckLimits(v.Blockchain.Params()) {
+		return rejection.New(rejection.LimitsExceeded, "block exceeds limits of the chain")
+	}
+
+	err := block.CheckHeader()
+	if err != nil {
+		return err
+	}
+	err = block.VerifySignatures(v.Verifier)
+	if err != nil {
+		return err
 	}
 
 	v.IndexedData.Mutex.Lock()
 	defer v.IndexedData.Mutex.Unlock()
-	return block.Verify(v.IndexedData)
+	return block.VerifyData(v.IndexedData)
 }
 
 func (v *Validator) AddBlockToChain(block *blk.Block) error {
@@ -213,63 +399,69 @@ type IndexedDataActualizer interface {
 }
 
 func (v *Validator) ActualizeNodeData(block *blk.Block) {
+	v.MemPool.RemoveTransactions(block.Body.Transactions)
+
 	v.IndexedData.Mutex.Lock()
 	defer v.IndexedData.Mutex.Unlock()
+	// Initial accounts of the chain are recorded in genesis, later blocks with accounts are rejected
+	if v.IndexedData.Height == 0 {
+		for _, initialAccount := range block.Header.Accounts {
+			v.IndexedData.AccountManager.AddPubKey(initialAccount.PublicKey, initialAccount.Type)
+		}
+	}
 	for _, transaction := range block.Body.Transactions {
 		txExact, ok := transaction.GetTxBody().(IndexedDataActualizer)
 		if ok {
 			txExact.ActualizeIndexedData(v.IndexedData)
 		}
-	}
-}
 
-func (v *Validator) UpdateValidatorKeys() {
-	for {
-		newValidatorKeys := <-v.Channels.ValidatorKeys
-		v.IndexedData.Mutex.Lock()
-		v.IndexedData.AccountManager.ValidatorPubKeys = map[keys.PublicKeyBytes]struct{}{}
-		for _, key := range newValidatorKeys {
-			v.IndexedData.AccountManager.AddPubKey(key, account_manager.Validator)
+		// Votes are recorded so a voter cannot vote twice in the same voting
+		caster, ok := transaction.(tx.BallotCaster)
+		if !ok {
+			continue
+		}
+		if ballot, ok := caster.GetBallot(); ok {
+			v.IndexedData.VotingManager.AddVote(ballot.VotingLink, ballot.Voter, ballot.Answer)
 		}
-		v.IndexedData.Mutex.Unlock()
 	}
+
+	// Witnesses of the next blocks are checked against the validators left after this one
+	v.IndexedData.Height++
+	v.IndexedData.ValidatorHistory.Record(v.IndexedData.Height, v.IndexedData.AccountManager.ValidatorPubKeys)
 }
 
-func (v *Validator) AddNewTransaction() {
-	for {
-		newTransaction := <-v.Channels.Transaction
-		// TODO: uncomment in case of demo without administrators
-		//if newTransaction.GetTxType() == tx.AccountCreation &&
-		//	(newTransaction.(*tx.Transaction).PublicKey == keys.PublicKeyBytes{}) {
-		//	signer.NewTransactionSigner().SignTransactionWithPrivateKey(RegAdminPrivateKey, newTransaction.(*tx.Transaction))
-		//}
-		v.Channels.TxResponse <- v.AddToMemPool(newTransaction)
+// ReportEvidence adds evidence of an equivocating validator to MemPool as a transaction signed by this validator
+func (v *Validator) ReportEvidence(e *evidence.Evidence) {
+	transaction := tx.NewTransaction(tx.Evidence, ts.NewTxEvidence(*e, v.PublicKey()))
+	transaction.Sign(v.PublicKey(), v.Sign(transaction.GetSignatureMessage()))
+
+	err := v.AddToMemPool(transaction)
+	if err != nil {
+		logging.Errorf("Evidence against validator %x was not added to MemPool: %v", e.Offender(), err)
 	}
 }
 
-func (v *Validator) GetVotingsForPubKey() {
-	for {
-		pubKey := <-v.Channels.PublicKey
-
-		result := []indexed_votings.VotingDTO{}
+// GetResults counts votes for every answer of the voting
+func (v *Validator) GetResults(voting [32]byte) []uint64 {
+	v.IndexedData.Mutex.Lock()
+	defer v.IndexedData.Mutex.Unlock()
+	return v.IndexedData.VotingManager.GetResults(voting)
+}
 
-		votings := v.IndexedData.VotingManager.IndexedVotings
+// GetVotingsForPubKey returns votings the key is whitelisted for directly or through a group
+func (v *Validator) GetVotingsForPubKey(pubKey keys.PublicKeyBytes) []indexed_votings.VotingDTO {
+	v.IndexedData.Mutex.Lock()
+	defer v.IndexedData.Mutex.Unlock()
 
-		for _, voting := range votings {
-			flag := false
-			whiteList := voting.Whitelist
-			for _, identifier := range whiteList {
-				if v.IndexedData.GroupManager.IsGroupMember(identifier, pubKey) || identifier == pubKey {
-					result = append(result, voting)
-					flag = true
-					break
-				}
-			}
-			if flag {
-				continue
+	result := []indexed_votings.VotingDTO{}
+	for _, voting := range v.IndexedData.VotingManager.IndexedVotings {
+		for _, identifier := range voting.Whitelist {
+			if v.IndexedData.GroupManager.IsGroupMember(identifier, pubKey) || identifier == pubKey {
+				result = append(result, voting)
+				break
 			}
 		}
-
-		v.Channels.Votings <- result
 	}
+
+	return result
 }
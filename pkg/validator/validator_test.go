@@ -1,17 +1,24 @@
 package validator
 
 import (
+	"context"
+	"errors"
 	"github.com/Digital-Voting-Team/Digital-Voting/pkg/blockchain"
 	blk "github.com/Digital-Voting-Team/Digital-Voting/pkg/blockchain/block"
 	"github.com/Digital-Voting-Team/Digital-Voting/pkg/blockchain/block/merkle_tree"
 	tx "github.com/Digital-Voting-Team/Digital-Voting/pkg/blockchain/transaction"
 	ts "github.com/Digital-Voting-Team/Digital-Voting/pkg/blockchain/transaction/transaction_specific"
+	"github.com/Digital-Voting-Team/Digital-Voting/pkg/consensus/evidence"
 	"github.com/Digital-Voting-Team/Digital-Voting/pkg/models/account"
+	"github.com/Digital-Voting-Team/Digital-Voting/pkg/rejection"
 	"github.com/Digital-Voting-Team/Digital-Voting/pkg/signature/keys"
 	ss "github.com/Digital-Voting-Team/Digital-Voting/pkg/signature/signatures/single_signature"
 	"github.com/Digital-Voting-Team/Digital-Voting/pkg/signer"
+	"github.com/Digital-Voting-Team/Digital-Voting/pkg/validator/admission"
 	nd "github.com/Digital-Voting-Team/Digital-Voting/pkg/validator/repository"
 	ip "github.com/Digital-Voting-Team/Digital-Voting/pkg/validator/repository/account_manager"
+	"github.com/Digital-Voting-Team/Digital-Voting/pkg/validator/validation"
+	"reflect"
 	"testing"
 	"time"
 )
@@ -115,7 +122,7 @@ func TestCreateBlock(t *testing.T) {
 	validator.AddToMemPool(txVote)
 
 	blockBody := blk.Body{
-		Transactions: validator.MemPool.Transactions,
+		Transactions: validator.MemPool.GetTransactions(),
 	}
 	timeStamp := uint64(time.Unix(1494505756, 0).Unix())
 	blockHeader := blk.Header{
@@ -127,7 +134,7 @@ func TestCreateBlock(t *testing.T) {
 		Header: blockHeader,
 		Body:   blockBody,
 	}
-	validator.SignAndUpdateBlock(testBlock)
+	_ = validator.BlockSigner.SignAndUpdateBlock(keyPair1, testBlock, validator.ValidatorSet())
 
 	type args struct {
 		previousBlockHash [32]byte
@@ -148,8 +155,7 @@ func TestCreateBlock(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			got := validator.CreateBlock(tt.args.previousBlockHash)
-			got.Witness.ValidatorsPublicKeys = tt.want.Witness.ValidatorsPublicKeys
-			got.Witness.ValidatorsSignatures = tt.want.Witness.ValidatorsSignatures
+			got.Witness = tt.want.Witness
 			got.Header.TimeStamp = timeStamp
 			if got.GetHash() != tt.want.GetHash() {
 				t.Errorf("CreateBlock() = %v, want %v", got, tt.want)
@@ -158,6 +164,73 @@ func TestCreateBlock(t *testing.T) {
 	}
 }
 
+func TestValidator_CreateBlock_NothingFits(t *testing.T) {
+	params := blk.DefaultChainParams()
+	params.MaxBlockBytes = 1
+	validator := &Validator{
+		MemPool:     NewMemPool(),
+		IndexedData: nd.NewIndexedData(),
+		Blockchain:  &blockchain.Blockchain{Blocks: []*blk.Block{blk.NewGenesisBlock(params, nil)}},
+	}
+
+	if got := validator.CreateBlock([32]byte{}); got != nil {
+		t.Errorf("CreateBlock() of empty MemPool = %v, want nil", got)
+	}
+
+	oversized := newTestTransaction(keys.PublicKeyBytes{1}, 1)
+	pending := newTestTransaction(keys.PublicKeyBytes{2}, 1)
+	validator.MemPool.AddToMemPool(oversized)
+	validator.MemPool.AddToMemPool(pending)
+	if got := validator.CreateBlock([32]byte{}); got != nil {
+		t.Errorf("CreateBlock() with transactions over size limit = %v, want nil", got)
+	}
+	if validator.MemPool.IsInMemPool(oversized) || !validator.MemPool.IsInMemPool(pending) {
+		t.Errorf("oversized transaction in MemPool: %v, next one: %v, want only the next one", validator.MemPool.IsInMemPool(oversized), validator.MemPool.IsInMemPool(pending))
+	}
+}
+
+func TestValidator_VerifyProposal_Header(t *testing.T) {
+	genesis := blk.NewGenesisBlock(blk.DefaultChainParams(), nil)
+	validator := &Validator{
+		MemPool:     NewMemPool(),
+		IndexedData: nd.NewIndexedData(),
+		Blockchain:  &blockchain.Blockchain{Blocks: []*blk.Block{genesis}},
+		Verifier:    validation.NewSignatureVerifier(1, validation.SignatureCacheSize),
+	}
+	validator.ActualizeNodeData(genesis)
+
+	newProposal := func(params *blk.ChainParams, accounts []blk.Account) *blk.Block {
+		block := blk.NewBlock(nil, genesis.GetHash())
+		block.Header.Params = params
+		block.Header.Accounts = accounts
+		return block
+	}
+	accounts := []blk.Account{{PublicKey: keys.PublicKeyBytes{1}, Type: ip.Validator}}
+	params := blk.DefaultChainParams()
+
+	tests := []struct {
+		name     string
+		block    *blk.Block
+		wantCode rejection.Code
+	}{
+		{name: "Block without genesis fields", block: newProposal(nil, nil), wantCode: ""},
+		{name: "Block with accounts", block: newProposal(nil, accounts), wantCode: rejection.GenesisOnly},
+		{name: "Block with params", block: newProposal(&params, nil), wantCode: rejection.GenesisOnly},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := rejection.CodeOf(validator.VerifyProposal(tt.block)); got != tt.wantCode {
+				t.Errorf("VerifyProposal() code = %v, want %v", got, tt.wantCode)
+			}
+		})
+	}
+
+	validator.ActualizeNodeData(newProposal(nil, accounts))
+	if validator.IndexedData.AccountManager.CheckPubKeyPresence(keys.PublicKeyBytes{1}, ip.Validator) {
+		t.Errorf("accounts of a block after genesis were recorded")
+	}
+}
+
 func TestActualizeIdentityProvider(t *testing.T) {
 	sign := ss.NewECDSA()
 	indexedData := nd.NewIndexedData()
@@ -241,6 +314,10 @@ func TestVerifyBlock(t *testing.T) {
 
 	validatorKeyPair, _ := keys.Random(sign.Curve)
 	indexedData.AccountManager.AddPubKey(validatorKeyPair.PublicToBytes(), ip.Validator)
+	indexedData.ValidatorHistory.Record(0, indexedData.AccountManager.ValidatorPubKeys)
+	// another validator joins at height 1, a single signature is no quorum of two
+	otherKeyPair, _ := keys.Random(sign.Curve)
+	indexedData.ValidatorHistory.Record(1, map[keys.PublicKeyBytes]struct{}{validatorKeyPair.PublicToBytes(): {}, otherKeyPair.PublicToBytes(): {}})
 
 	validator := &Validator{
 		MemPool:     NewMemPool(),
@@ -258,7 +335,9 @@ func TestVerifyBlock(t *testing.T) {
 	genesisBlock := blk.NewBlock([]tx.ITransaction{genesisTransaction1}, [32]byte{89, 30, 32, 250, 95, 98, 97, 139, 139, 137, 172, 12, 26, 84, 187, 91, 65, 82, 16, 79, 79, 69, 158, 210, 187, 152, 72, 222, 90, 241, 38, 213})
 	fakeBlock := blk.NewBlock([]tx.ITransaction{genesisTransaction1}, [32]byte{})
 
-	validator.SignAndUpdateBlock(genesisBlock)
+	_ = validator.BlockSigner.SignAndUpdateBlock(validatorKeyPair, genesisBlock, validator.ValidatorSet())
+	noQuorumBlock := blk.NewBlock([]tx.ITransaction{genesisTransaction1}, genesisBlock.Header.Previous)
+	_ = validator.BlockSigner.SignAndUpdateBlock(validatorKeyPair, noQuorumBlock, indexedData.ValidatorHistory.At(1))
 
 	type args struct {
 		block *blk.Block
@@ -266,28 +345,213 @@ func TestVerifyBlock(t *testing.T) {
 	tests := []struct {
 		name     string
 		args     args
-		wantBool bool
+		height   uint64
+		wantCode rejection.Code
 	}{
 		{
 			name: "Verify valid blk",
 			args: args{
 				block: genesisBlock,
 			},
-			wantBool: true,
+			wantCode: "",
 		},
 		{
 			name: "Verify bot valid blk",
 			args: args{
 				block: fakeBlock,
 			},
-			wantBool: false,
+			wantCode: rejection.BadWitness,
+		},
+		{
+			name: "Witness without quorum",
+			args: args{
+				block: noQuorumBlock,
+			},
+			height:   1,
+			wantCode: rejection.BadWitness,
 		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			if got := validator.VerifyBlock(tt.args.block); got != tt.wantBool {
-				t.Errorf("VerifyBlock function returned: %v, expected value was: %v", got, tt.wantBool)
+			indexedData.Height = tt.height
+			if got := rejection.CodeOf(validator.VerifyBlock(tt.args.block)); got != tt.wantCode {
+				t.Errorf("VerifyBlock function returned code: %v, expected code was: %v", got, tt.wantCode)
 			}
 		})
 	}
 }
+
+// testNetwork records transactions forwarded to the proposer
+type testNetwork struct {
+	forwarded []tx.ITransaction
+	err       error
+}
+
+func (tn *testNetwork) SubmitBlock(*blk.Block) {}
+func (tn *testNetwork) Proposer() (keys.PublicKeyBytes, error) {
+	return keys.PublicKeyBytes{1}, nil
+}
+func (tn *testNetwork) ForwardTransactions(_ keys.PublicKeyBytes, transactions []tx.ITransaction) error {
+	tn.forwarded = append(tn.forwarded, transactions...)
+	return tn.err
+}
+
+func TestValidator_ForwardTransactions(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+	}{
+		{name: "Proposer got transactions", err: nil},
+		{name: "Proposer is unreachable", err: errors.New("unreachable")},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			transaction := newTestTransaction(keys.PublicKeyBytes{2}, 1)
+			network := &testNetwork{err: tt.err}
+			validator := &Validator{
+				MemPool:     NewMemPool(),
+				IndexedData: nd.NewIndexedData(),
+				Blockchain:  &blockchain.Blockchain{Blocks: []*blk.Block{blk.NewGenesisBlock(blk.DefaultChainParams(), nil)}},
+				Network:     network,
+			}
+			validator.MemPool.AddToMemPool(transaction)
+
+			validator.ForwardTransactions(keys.PublicKeyBytes{1})
+			if len(network.forwarded) != 1 || !validator.MemPool.IsInMemPool(transaction) {
+				t.Errorf("forwarded %d transactions, in MemPool: %v, want 1 kept in MemPool", len(network.forwarded), validator.MemPool.IsInMemPool(transaction))
+			}
+
+			validator.ActualizeNodeData(blk.NewBlock([]tx.ITransaction{transaction}, [32]byte{}))
+			if validator.MemPool.IsInMemPool(transaction) {
+				t.Errorf("transaction is in MemPool after it was committed")
+			}
+		})
+	}
+}
+
+func TestValidator_AddToMemPool_ForgedSignature(t *testing.T) {
+	sign := ss.NewECDSA()
+	indexedData := nd.NewIndexedData()
+	adminKeyPair, _ := keys.Random(sign.Curve)
+	indexedData.AccountManager.AddPubKey(adminKeyPair.PublicToBytes(), ip.RegistrationAdmin)
+
+	validator := &Validator{
+		MemPool:     NewMemPool(),
+		IndexedData: indexedData,
+		Verifier:    validation.NewSignatureVerifier(1, validation.SignatureCacheSize),
+		Admission:   admission.NewController(admission.Config{KeyRate: 0.001, KeyBurst: 1}),
+	}
+
+	// Forged transactions in the name of the admin must not use up its rate limit
+	for i := byte(0); i < 3; i++ {
+		forged := tx.NewTransaction(tx.AccountCreation, ts.NewTxAccCreation(account.User, keys.PublicKeyBytes{i}))
+		forged.Sign(adminKeyPair.PublicToBytes(), ss.SingleSignatureBytes{1})
+		if err := validator.AddToMemPool(forged); rejection.CodeOf(err) != rejection.BadSignature {
+			t.Errorf("AddToMemPool() of forged transaction error = %v, want %s", err, rejection.BadSignature)
+		}
+	}
+
+	userKeyPair, _ := keys.Random(sign.Curve)
+	transaction := tx.NewTransaction(tx.AccountCreation, ts.NewTxAccCreation(account.User, userKeyPair.PublicToBytes()))
+	signer.NewTransactionSigner().SignTransaction(adminKeyPair, transaction)
+	if err := validator.AddToMemPool(transaction); err != nil {
+		t.Errorf("AddToMemPool() of signed transaction error = %v, want nil", err)
+	}
+	if err := validator.AddToMemPool(transaction); err != admission.ErrKeyRateLimited {
+		t.Errorf("AddToMemPool() over rate limit error = %v, want %v", err, admission.ErrKeyRateLimited)
+	}
+}
+
+func TestValidator_StartStop(t *testing.T) {
+	dataDir := t.TempDir()
+	adminKeyPair, _ := keys.Random(ss.NewECDSA().Curve)
+	transaction := tx.NewTransaction(tx.AccountCreation, ts.NewTxAccCreation(account.User, keys.PublicKeyBytes{1}))
+	signer.NewTransactionSigner().SignTransaction(adminKeyPair, transaction)
+
+	newValidator := func() *Validator {
+		bc := &blockchain.Blockchain{}
+		// the admin is an account of genesis, Start applies it by replaying the chain
+		_ = bc.AddBlock(blk.NewGenesisBlock(blk.DefaultChainParams(), []blk.Account{{PublicKey: adminKeyPair.PublicToBytes(), Type: ip.RegistrationAdmin}}))
+		validatorKeyPair, _ := keys.Random(ss.NewECDSA().Curve)
+		v := NewValidator(bc, validatorKeyPair)
+		v.DataDir = dataDir
+		return v
+	}
+
+	stopped := newValidator()
+	stopped.Start(context.Background())
+	if err := stopped.AddToMemPool(transaction); err != nil {
+		t.Fatalf("AddToMemPool() error = %v", err)
+	}
+	if err := stopped.Stop(); err != nil {
+		t.Fatalf("Stop() error = %v", err)
+	}
+
+	restarted := newValidator()
+	restarted.Start(context.Background())
+	defer func() {
+		_ = restarted.Stop()
+	}()
+	if !restarted.MemPool.IsInMemPool(transaction) {
+		t.Errorf("MemPool was not restored after restart")
+	}
+}
+
+func TestReportEvidence(t *testing.T) {
+	sign := ss.NewECDSA()
+	indexedData := nd.NewIndexedData()
+
+	reporterKeyPair, _ := keys.Random(sign.Curve)
+	offenderKeyPair, _ := keys.Random(sign.Curve)
+	validatorKeys := []keys.PublicKeyBytes{reporterKeyPair.PublicToBytes(), offenderKeyPair.PublicToBytes()}
+
+	validator := &Validator{
+		MemPool:     NewMemPool(),
+		KeyPair:     reporterKeyPair,
+		IndexedData: indexedData,
+		BlockSigner: signer.NewBlockSigner(),
+	}
+	for _, validatorKey := range validatorKeys {
+		indexedData.AccountManager.AddPubKey(validatorKey, ip.Validator)
+	}
+	// the equivocation happened at height 4 of a chain of 5 blocks
+	indexedData.ValidatorHistory.Record(1, indexedData.AccountManager.ValidatorPubKeys)
+	indexedData.Height = 5
+
+	vote := func(blockHash [32]byte) evidence.Vote {
+		vote := evidence.Vote{VoteType: evidence.Precommit, Height: 4, Round: 1, BlockHash: blockHash, Validator: offenderKeyPair.PublicToBytes()}
+		signature := sign.SignEdDSA(vote.GetSignatureMessage(), offenderKeyPair.GetPrivateKey(), offenderKeyPair.GetPublicKey())
+		vote.Signature = sign.EdwardsToSingleSignature(signature).EdwardsSignatureToBytes()
+		return vote
+	}
+	equivocation := evidence.NewVoteEvidence(vote([32]byte{1}), vote([32]byte{2}))
+
+	validator.ReportEvidence(equivocation)
+	if got := validator.MemPool.GetTransactionsCount(); got != 1 {
+		t.Fatalf("MemPool has %d transactions after the report, want 1", got)
+	}
+	validator.ActualizeNodeData(validator.CreateBlock([32]byte{}))
+	validator.ReportEvidence(equivocation)
+
+	tests := []struct {
+		name string
+		got  interface{}
+		want interface{}
+	}{
+		{name: "Offender is removed from the set", got: validator.ValidatorSet(), want: []keys.PublicKeyBytes{reporterKeyPair.PublicToBytes()}},
+		{name: "Slashing is recorded", got: indexedData.SlashingManager.IsSlashed(offenderKeyPair.PublicToBytes()), want: true},
+		{name: "Evidence is not accepted twice", got: validator.MemPool.GetTransactionsCount(), want: 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if !reflect.DeepEqual(tt.got, tt.want) {
+				t.Errorf("got %v, want %v", tt.got, tt.want)
+			}
+		})
+	}
+
+	slashing, _ := indexedData.SlashingManager.GetSlashing(offenderKeyPair.PublicToBytes())
+	if slashing.Reporter != reporterKeyPair.PublicToBytes() || slashing.Kind != "precommit" || slashing.Height != 4 || slashing.Round != 1 {
+		t.Errorf("slashing record = %+v, want precommit of height 4 and round 1 reported by the validator", slashing)
+	}
+}